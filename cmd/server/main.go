@@ -11,6 +11,7 @@ import (
 	"github.com/kjanat/chatlogger-api-go/internal/jobs"
 	"github.com/kjanat/chatlogger-api-go/internal/repository"
 	"github.com/kjanat/chatlogger-api-go/internal/service"
+	"github.com/kjanat/chatlogger-api-go/internal/storage"
 	"github.com/kjanat/chatlogger-api-go/internal/version"
 )
 
@@ -75,9 +76,19 @@ func main() {
 	userRepo := repository.NewUserRepository(db)
 	chatRepo := repository.NewChatRepository(db)
 	messageRepo := repository.NewMessageRepository(db)
+	analyticsRepo := repository.NewAnalyticsRepository(db)
+	usageRepo := repository.NewUsageRepository(db)
 	exportRepo := repository.NewExportRepository(db.DB)
+	scheduleRepo := repository.NewExportScheduleRepository(db.DB)
+	destinationRepo := repository.NewExportDestinationRepository(db.DB)
+	deliveryRepo := repository.NewExportDeliveryRepository(db.DB)
+
+	// 4. Initialize Export Storage and Job Queue
+	exportStorage, err := storage.New(cfg.Storage)
+	if err != nil {
+		log.Fatalf("Failed to initialize export storage: %v", err)
+	}
 
-	// 4. Initialize Job Queue
 	queue := jobs.NewQueue(cfg.RedisAddr)
 	defer func() {
 		if err := queue.Close(); err != nil {
@@ -91,7 +102,11 @@ func main() {
 	userService := service.NewUserService(userRepo, cfg.JWTSecret)
 	chatService := service.NewChatService(chatRepo)
 	messageService := service.NewMessageService(messageRepo)
-	exportService := service.NewExportService(exportRepo, queue)
+	analyticsService := service.NewAnalyticsService(analyticsRepo, orgRepo)
+	usageService := service.NewUsageService(usageRepo, orgRepo, apiKeyRepo)
+	exportService := service.NewExportService(exportRepo, orgRepo, destinationRepo, deliveryRepo, queue)
+	scheduleService := service.NewExportScheduleService(scheduleRepo, exportService)
+	destinationService := service.NewExportDestinationService(destinationRepo)
 	swaggerService := service.NewSwaggerService()
 
 	// Configure Swagger documentation with API information
@@ -99,27 +114,36 @@ func main() {
 
 	// 6. Bundle services for dependency injection
 	services := &api.AppServices{
-		OrganizationService: orgService,
-		APIKeyService:       apiKeyService,
-		UserService:         userService,
-		ChatService:         chatService,
-		MessageService:      messageService,
-		ExportService:       exportService,
-		SwaggerService:      swaggerService,
+		OrganizationService:      orgService,
+		APIKeyService:            apiKeyService,
+		UserService:              userService,
+		ChatService:              chatService,
+		MessageService:           messageService,
+		AnalyticsService:         analyticsService,
+		UsageService:             usageService,
+		ExportService:            exportService,
+		ExportScheduleService:    scheduleService,
+		ExportDestinationService: destinationService,
+		SwaggerService:           swaggerService,
+		ExportStorage:            exportStorage,
 		Config: &api.AppConfig{
 			ExportDir: cfg.ExportDir,
 			APIServer: struct {
-				Host string
-				Port string
+				Host   string
+				Port   string
 				Scheme string
 			}{
-				Host: cfg.ApiServer.Host,
-				Port: cfg.ApiServer.Port,
+				Host:   cfg.ApiServer.Host,
+				Port:   cfg.ApiServer.Port,
 				Scheme: cfg.ApiServer.Scheme,
 			},
 		},
 	}
 
+	if cfg.ExportPresignedDownloads {
+		services.Config.ExportPresignTTL = cfg.ExportPresignTTL
+	}
+
 	// 7. Set up Gin Router with routes and inject services
 	router := api.NewRouter(services, cfg.JWTSecret)
 
@@ -0,0 +1,84 @@
+package main
+
+import (
+	"flag"
+	"log"
+	"os"
+	"time"
+
+	"github.com/kjanat/chatlogger-api-go/internal/repository"
+	"github.com/kjanat/chatlogger-api-go/internal/service"
+)
+
+// backfillCommand is the worker subcommand that computes the analytics rollups
+// of historical data.
+const backfillCommand = "backfill-rollups"
+
+// runBackfill recomputes the analytics rollups of a date range. The periodic
+// rollup job only covers recent hours, so this is run once after upgrading,
+// and again whenever historical chats or messages are changed or imported.
+func runBackfill(args []string) {
+	flags := flag.NewFlagSet(backfillCommand, flag.ExitOnError)
+	fromFlag := flags.String("from", "", "Start of the range (RFC3339 or YYYY-MM-DD, required)")
+	toFlag := flags.String("to", "", "End of the range (RFC3339 or YYYY-MM-DD), defaults to now")
+	if err := flags.Parse(args); err != nil {
+		log.Fatalf("Invalid arguments: %v", err)
+	}
+
+	if *fromFlag == "" {
+		log.Printf("Usage: worker %s -from=<date> [-to=<date>]", backfillCommand)
+		flags.PrintDefaults()
+		os.Exit(2)
+	}
+
+	from, err := parseBackfillTime(*fromFlag)
+	if err != nil {
+		log.Fatalf("Invalid -from: %v", err)
+	}
+
+	to := time.Now()
+	if *toFlag != "" {
+		if to, err = parseBackfillTime(*toFlag); err != nil {
+			log.Fatalf("Invalid -to: %v", err)
+		}
+	}
+
+	dbURL := os.Getenv("DATABASE_URL")
+	if dbURL == "" {
+		log.Fatalf("DATABASE_URL environment variable is required")
+	}
+
+	dbOptions := repository.DefaultDatabaseOptions()
+	dbOptions.RunMigrations = false // The server runs migrations
+
+	db, err := repository.NewDatabaseWithOptions(dbURL, dbOptions)
+	if err != nil {
+		log.Fatalf("Failed to connect to database: %v", err)
+	}
+	defer func() {
+		if err := db.Close(); err != nil {
+			log.Printf("Error closing database connection: %v", err)
+		}
+	}()
+
+	rollupService := service.NewRollupService(repository.NewRollupRepository(db))
+
+	log.Printf("Backfilling analytics rollups from %s to %s", from.Format(time.RFC3339), to.Format(time.RFC3339))
+	err = rollupService.Backfill(from, to, func(done time.Time) {
+		log.Printf("Rolled up until %s", done.Format(time.RFC3339))
+	})
+	if err != nil {
+		log.Fatalf("Backfill failed: %v", err)
+	}
+
+	log.Println("Backfill complete")
+}
+
+// parseBackfillTime parses an RFC3339 timestamp or a UTC date.
+func parseBackfillTime(value string) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+
+	return time.Parse(time.DateOnly, value)
+}
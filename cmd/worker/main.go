@@ -1,6 +1,8 @@
 // Package main implements the ChatLogger API worker process.
-// This worker handles asynchronous background jobs like chat exports,
-// connecting to Redis for job processing and PostgreSQL for data access.
+// This worker handles asynchronous background jobs like chat exports and
+// analytics rollups, connecting to Redis for job processing and PostgreSQL for
+// data access. Run as "worker backfill-rollups", it computes the analytics
+// rollups of historical data instead.
 // It uses the asynq library for job queue management with graceful shutdown
 // handling for production reliability.
 package main
@@ -15,9 +17,12 @@ import (
 	"github.com/hibiken/asynq"
 	"github.com/joho/godotenv"
 
+	"github.com/kjanat/chatlogger-api-go/internal/config"
+	"github.com/kjanat/chatlogger-api-go/internal/delivery"
 	"github.com/kjanat/chatlogger-api-go/internal/jobs"
 	"github.com/kjanat/chatlogger-api-go/internal/repository"
 	"github.com/kjanat/chatlogger-api-go/internal/service"
+	"github.com/kjanat/chatlogger-api-go/internal/storage"
 )
 
 // CustomLogger wraps the standard logger to implement asynq.Logger
@@ -56,6 +61,11 @@ func main() {
 		log.Println("No .env file found, using environment variables")
 	}
 
+	if len(os.Args) > 1 && os.Args[1] == backfillCommand {
+		runBackfill(os.Args[2:])
+		return
+	}
+
 	// Get Redis connection info
 	redisAddr := os.Getenv("REDIS_ADDR")
 	if redisAddr == "" {
@@ -68,9 +78,10 @@ func main() {
 		exportDir = "./exports"
 	}
 
-	// Create exports directory if it doesn't exist
-	if err := os.MkdirAll(exportDir, 0755); err != nil {
-		log.Fatalf("Failed to create export directory: %v", err)
+	// Set up export storage, shared with the API server
+	exportStorage, err := storage.New(config.LoadStorageConfig(exportDir))
+	if err != nil {
+		log.Fatalf("Failed to initialize export storage: %v", err)
 	}
 
 	// Get database connection info
@@ -104,20 +115,49 @@ func main() {
 
 	// Set up repositories
 	exportRepo := repository.NewExportRepository(db.DB)
+	orgRepo := repository.NewOrganizationRepository(db)
 	chatRepo := repository.NewChatRepository(db)
 	messageRepo := repository.NewMessageRepository(db)
 
+	scheduleRepo := repository.NewExportScheduleRepository(db.DB)
+	destinationRepo := repository.NewExportDestinationRepository(db.DB)
+	deliveryRepo := repository.NewExportDeliveryRepository(db.DB)
+	rollupRepo := repository.NewRollupRepository(db)
+
+	// Set up the job queue, used to enqueue exports created by schedules and
+	// the deliveries of completed exports
+	queue := jobs.NewQueue(redisAddr)
+	defer func() {
+		if err := queue.Close(); err != nil {
+			log.Printf("Error closing queue connection: %v", err)
+		}
+	}()
+
 	// Set up services
 	chatService := service.NewChatService(chatRepo)
 	messageService := service.NewMessageService(messageRepo)
+	exportService := service.NewExportService(exportRepo, orgRepo, destinationRepo, deliveryRepo, queue)
+	scheduleService := service.NewExportScheduleService(scheduleRepo, exportService)
+	rollupService := service.NewRollupService(rollupRepo)
 
 	// Create export processor
 	processor := jobs.NewExportProcessor(
 		exportRepo,
+		orgRepo,
 		chatService,
 		messageService,
-		exportDir,
+		exportStorage,
+		queue,
+	)
+	deliveryProcessor := jobs.NewDeliveryProcessor(
+		exportRepo,
+		deliveryRepo,
+		destinationRepo,
+		delivery.NewDeliverer(exportStorage),
 	)
+	cleanupProcessor := jobs.NewExportCleanupProcessor(exportRepo, exportStorage)
+	scheduleProcessor := jobs.NewScheduleProcessor(scheduleService)
+	rollupProcessor := jobs.NewRollupProcessor(rollupService)
 
 	// Create a custom logger that implements the asynq.Logger interface
 	customLogger := &CustomLogger{
@@ -141,6 +181,25 @@ func main() {
 	// Register task handlers
 	mux := asynq.NewServeMux()
 	mux.HandleFunc(jobs.TypeExportProcess, processor.ProcessExport)
+	mux.HandleFunc(jobs.TypeExportCleanup, cleanupProcessor.ProcessCleanup)
+	mux.HandleFunc(jobs.TypeExportScheduled, scheduleProcessor.ProcessScheduledExport)
+	mux.HandleFunc(jobs.TypeExportDeliver, deliveryProcessor.ProcessDelivery)
+	mux.HandleFunc(jobs.TypeAnalyticsRollup, rollupProcessor.ProcessRollup)
+
+	// Schedule periodic jobs: the export cleanup, the analytics rollup and every
+	// enabled export schedule
+	scheduler, err := asynq.NewPeriodicTaskManager(asynq.PeriodicTaskManagerOpts{
+		RedisConnOpt:               asynq.RedisClientOpt{Addr: redisAddr},
+		PeriodicTaskConfigProvider: jobs.NewPeriodicTaskProvider(scheduleService),
+		SyncInterval:               jobs.ScheduleSyncInterval,
+		SchedulerOpts:              &asynq.SchedulerOpts{Logger: customLogger},
+	})
+	if err != nil {
+		log.Fatalf("Failed to create periodic task manager: %v", err)
+	}
+	if err := scheduler.Start(); err != nil {
+		log.Fatalf("Could not start scheduler: %v", err)
+	}
 
 	// Set up graceful shutdown
 	c := make(chan os.Signal, 1)
@@ -156,6 +215,7 @@ func main() {
 		// Set a timeout for shutdown to ensure we don't hang
 		shutdownComplete := make(chan struct{})
 		go func() {
+			scheduler.Shutdown()
 			srv.Shutdown()
 			close(shutdownComplete)
 		}()
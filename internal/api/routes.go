@@ -102,13 +102,42 @@ func addRoutes(router *gin.Engine, services *AppServices, jwtSecret string) {
 			orgGroup.GET("/apikeys", apiKeyHandler.ListKeys)
 			orgGroup.POST("/apikeys", apiKeyHandler.GenerateKey)
 			orgGroup.DELETE("/apikeys/:id", apiKeyHandler.RevokeKey)
+
+			// Organization members
+			orgGroup.GET("/users", userHandler.ListOrgUsers)
+
+			// Organization settings
+			orgHandler := handler.NewOrganizationHandler(services.OrganizationService)
+			orgGroup.GET("/settings", orgHandler.GetSettings)
+			orgGroup.PATCH("/settings", orgHandler.UpdateSettings)
+
+			// Ingestion usage and quotas
+			usageHandler := handler.NewUsageHandler(services.UsageService)
+			orgGroup.GET("/usage", usageHandler.GetUsage)
+
+			// Export destinations
+			destinationHandler := handler.NewExportDestinationHandler(services.ExportDestinationService)
+			orgGroup.POST("/destinations", destinationHandler.CreateDestination)
+			orgGroup.GET("/destinations", destinationHandler.ListDestinations)
+			orgGroup.GET("/destinations/:id", destinationHandler.GetDestination)
+			orgGroup.PATCH("/destinations/:id", destinationHandler.UpdateDestination)
+			orgGroup.DELETE("/destinations/:id", destinationHandler.DeleteDestination)
 		}
 
+		// Usage quotas are set per plan - superadmin access only
+		quotaHandler := handler.NewOrganizationHandler(services.OrganizationService)
+		dashboardGroup.PUT(
+			"/orgs/:slug/quotas",
+			middleware.RoleRequired(domain.RoleSuperAdmin),
+			middleware.ValidateSlugAccess(services.OrganizationService),
+			quotaHandler.UpdateQuotas,
+		)
+
 		// Chat routes - any authenticated user
 		chatHandler := handler.NewChatHandler(services.ChatService, services.MessageService)
 		chatGroup := dashboardGroup.Group("/chats")
 		{
-			chatGroup.POST("", chatHandler.CreateChat)
+			chatGroup.POST("", middleware.UsageQuota(services.UsageService, domain.UsageChats), chatHandler.CreateChat)
 			chatGroup.GET("", chatHandler.ListChats)
 			chatGroup.GET("/:chatID", chatHandler.GetChat)
 			chatGroup.PATCH("/:chatID", chatHandler.UpdateChat)
@@ -119,15 +148,37 @@ func addRoutes(router *gin.Engine, services *AppServices, jwtSecret string) {
 		messageHandler := handler.NewMessageHandler(services.MessageService, services.ChatService)
 		dashboardGroup.GET("/chats/:chatID/messages", messageHandler.GetMessages)
 
+		// Full-text search across message content
+		dashboardGroup.GET("/search", messageHandler.SearchMessages)
+
 		// Analytics routes
 		dashboardGroup.GET("/analytics/messages", messageHandler.GetMessageStats)
+		dashboardGroup.GET("/analytics/chats", chatHandler.GetChatStats)
+
+		analyticsHandler := handler.NewAnalyticsHandler(services.AnalyticsService)
+		dashboardGroup.GET("/analytics/timeseries", analyticsHandler.GetTimeSeries)
+		dashboardGroup.GET("/analytics/quality", analyticsHandler.GetQualityReport)
+		dashboardGroup.GET("/analytics/latency", analyticsHandler.GetLatencyReport)
+		dashboardGroup.GET("/analytics/latency/slowest-chats", analyticsHandler.GetSlowestChats)
+		dashboardGroup.GET("/analytics/usage", analyticsHandler.GetUsageReport)
+
+		// Tag routes - renaming and merging affect every chat, so admin access only
+		tagHandler := handler.NewTagHandler(services.ChatService)
+		tagGroup := dashboardGroup.Group("/tags")
+		{
+			tagGroup.GET("", tagHandler.ListTags)
+			tagGroup.POST("/bulk", tagHandler.BulkUpdateTags)
+			tagGroup.POST("/rename", middleware.RoleRequired(domain.RoleAdmin), tagHandler.RenameTag)
+			tagGroup.POST("/merge", middleware.RoleRequired(domain.RoleAdmin), tagHandler.MergeTags)
+		}
 
 		// Export routes - available to all authenticated users
 		exportHandler := handler.NewExportHandler(
 			services.ExportService,
 			services.ChatService,
 			services.MessageService,
-			services.Config.ExportDir,
+			services.ExportStorage,
+			services.Config.ExportPresignTTL,
 		)
 
 		// Async export endpoints
@@ -135,9 +186,22 @@ func addRoutes(router *gin.Engine, services *AppServices, jwtSecret string) {
 		dashboardGroup.GET("/exports", exportHandler.ListExports)
 		dashboardGroup.GET("/exports/:id", exportHandler.GetExport)
 		dashboardGroup.GET("/exports/:id/download", exportHandler.DownloadExport)
+		dashboardGroup.POST("/exports/:id/cancel", exportHandler.CancelExport)
 
 		// Legacy sync export endpoint (for backward compatibility)
 		dashboardGroup.POST("/exports/sync", exportHandler.SyncExport)
+
+		// Export schedules - admin access only
+		scheduleHandler := handler.NewExportScheduleHandler(services.ExportScheduleService)
+		scheduleGroup := dashboardGroup.Group("/exports/schedules")
+		scheduleGroup.Use(middleware.RoleRequired(domain.RoleAdmin))
+		{
+			scheduleGroup.POST("", scheduleHandler.CreateSchedule)
+			scheduleGroup.GET("", scheduleHandler.ListSchedules)
+			scheduleGroup.GET("/:id", scheduleHandler.GetSchedule)
+			scheduleGroup.PATCH("/:id", scheduleHandler.UpdateSchedule)
+			scheduleGroup.DELETE("/:id", scheduleHandler.DeleteSchedule)
+		}
 	}
 
 	// Public API routes (API key auth required)
@@ -149,7 +213,12 @@ func addRoutes(router *gin.Engine, services *AppServices, jwtSecret string) {
 		chatHandler := handler.NewChatHandler(services.ChatService, services.MessageService)
 		messageHandler := handler.NewMessageHandler(services.MessageService, services.ChatService)
 
-		publicAPIGroup.POST("/chats", chatHandler.CreateChat)
-		publicAPIGroup.POST("/chats/:chatID/messages", messageHandler.CreateMessage)
+		// Chats and messages count towards the usage quotas of the organization
+		publicAPIGroup.POST("/chats", middleware.UsageQuota(services.UsageService, domain.UsageChats), chatHandler.CreateChat)
+		publicAPIGroup.POST(
+			"/chats/:chatID/messages",
+			middleware.UsageQuota(services.UsageService, domain.UsageMessages),
+			messageHandler.CreateMessage,
+		)
 	}
 }
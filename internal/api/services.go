@@ -5,27 +5,38 @@
 package api
 
 import (
+	"time"
+
 	"github.com/kjanat/chatlogger-api-go/internal/domain"
+	"github.com/kjanat/chatlogger-api-go/internal/storage"
 )
 
 // AppConfig contains application configuration values
 type AppConfig struct {
 	ExportDir string
-	APIServer struct {
-		Host string
-		Port string
+	// ExportPresignTTL is the lifetime of presigned export download URLs; zero
+	// streams downloads through the server
+	ExportPresignTTL time.Duration
+	APIServer        struct {
+		Host   string
+		Port   string
 		Scheme string
 	}
 }
 
 // AppServices contains all the services used by the application.
 type AppServices struct {
-	UserService         domain.UserService
-	OrganizationService domain.OrganizationService
-	APIKeyService       domain.APIKeyService
-	ChatService         domain.ChatService
-	MessageService      domain.MessageService
-	ExportService       domain.ExportService
-	SwaggerService      domain.SwaggerService
-	Config              *AppConfig
+	UserService              domain.UserService
+	OrganizationService      domain.OrganizationService
+	APIKeyService            domain.APIKeyService
+	ChatService              domain.ChatService
+	MessageService           domain.MessageService
+	AnalyticsService         domain.AnalyticsService
+	UsageService             domain.UsageService
+	ExportService            domain.ExportService
+	ExportScheduleService    domain.ExportScheduleService
+	ExportDestinationService domain.ExportDestinationService
+	SwaggerService           domain.SwaggerService
+	ExportStorage            storage.Storage
+	Config                   *AppConfig
 }
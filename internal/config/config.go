@@ -6,8 +6,12 @@ package config
 import (
 	"log"
 	"os"
+	"strconv"
+	"time"
 
 	"github.com/joho/godotenv" // Optional: helpful for local development
+
+	"github.com/kjanat/chatlogger-api-go/internal/storage"
 )
 
 // Config holds all application configuration settings loaded from environment variables.
@@ -24,6 +28,13 @@ type Config struct {
 	RedisAddr string
 	// ExportDir is the directory where export files will be stored
 	ExportDir string
+	// Storage selects and configures the backend that export files are stored in
+	Storage storage.Config
+	// ExportPresignedDownloads redirects export downloads to presigned URLs when
+	// the storage backend supports them
+	ExportPresignedDownloads bool
+	// ExportPresignTTL is how long a presigned export download URL stays valid
+	ExportPresignTTL time.Duration
 	// ApiServer contains the API server configuration
 	// Host is the hostname for the API server
 	// Port is the port for the API server
@@ -98,6 +109,17 @@ func LoadConfig() (*Config, error) {
 		log.Println("Warning: Using default export directory. Set EXPORT_DIR for production.")
 	}
 
+	cfg.Storage = LoadStorageConfig(cfg.ExportDir)
+	cfg.ExportPresignedDownloads = envBool("EXPORT_PRESIGNED_DOWNLOADS")
+	cfg.ExportPresignTTL = 15 * time.Minute // Default presigned URL lifetime
+	if ttl := os.Getenv("EXPORT_PRESIGN_TTL"); ttl != "" {
+		if d, err := time.ParseDuration(ttl); err == nil && d > 0 {
+			cfg.ExportPresignTTL = d
+		} else {
+			log.Printf("Warning: Invalid EXPORT_PRESIGN_TTL %q, using default %s", ttl, cfg.ExportPresignTTL)
+		}
+	}
+
 	// Check if API server host and port are set
 	// If not, use server host and port as defaults
 	// This is useful for local development where the API server might be on the same host
@@ -115,10 +137,36 @@ func LoadConfig() (*Config, error) {
 		log.Println("Warning: API_ENDPOINT_SCHEME not set, using http")
 	}
 
-	// Create export directory if it doesn't exist
-	if err := os.MkdirAll(cfg.ExportDir, 0755); err != nil {
-		log.Printf("Warning: Failed to create export directory %s: %v", cfg.ExportDir, err)
+	return cfg, nil
+}
+
+// LoadStorageConfig loads the export storage settings from environment variables.
+// The local backend stores files in exportDir. It is shared by the server and
+// the worker, which must agree on where exports live.
+func LoadStorageConfig(exportDir string) storage.Config {
+	cfg := storage.Config{
+		Driver:   os.Getenv("EXPORT_STORAGE"),
+		LocalDir: exportDir,
+		S3: storage.S3Config{
+			Endpoint:        os.Getenv("S3_ENDPOINT"),
+			Region:          os.Getenv("S3_REGION"),
+			Bucket:          os.Getenv("S3_BUCKET"),
+			Prefix:          os.Getenv("S3_PREFIX"),
+			AccessKeyID:     os.Getenv("S3_ACCESS_KEY_ID"),
+			SecretAccessKey: os.Getenv("S3_SECRET_ACCESS_KEY"),
+			UseSSL:          envBool("S3_USE_SSL"),
+		},
+	}
+
+	if cfg.Driver == "" {
+		cfg.Driver = storage.DriverLocal // Default storage driver
 	}
 
-	return cfg, nil
+	return cfg
+}
+
+// envBool reports whether the environment variable key is set to a true value.
+func envBool(key string) bool {
+	value, err := strconv.ParseBool(os.Getenv(key))
+	return err == nil && value
 }
@@ -0,0 +1,81 @@
+// Package delivery sends completed exports to the export destinations of an
+// organization, such as webhooks and SFTP servers.
+package delivery
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"path/filepath"
+	"time"
+
+	"github.com/kjanat/chatlogger-api-go/internal/domain"
+	"github.com/kjanat/chatlogger-api-go/internal/storage"
+	"github.com/kjanat/chatlogger-api-go/internal/strategy"
+)
+
+// LinkTTL is how long the signed download links posted to webhooks are valid.
+const LinkTTL = 24 * time.Hour
+
+// webhookTimeout bounds a whole webhook request, including the upload of the
+// export file, so an endpoint that stops responding cannot hold a worker.
+const webhookTimeout = 10 * time.Minute
+
+// ErrLinksNotSupported is returned when a webhook asks for a signed link, but
+// the storage backend cannot create one.
+var ErrLinksNotSupported = errors.New("storage backend does not support signed download links")
+
+// Deliverer sends export files from export storage to destinations.
+type Deliverer struct {
+	store  storage.Storage
+	client *http.Client
+}
+
+// NewDeliverer creates a new deliverer reading export files from store.
+func NewDeliverer(store storage.Storage) *Deliverer {
+	return &Deliverer{
+		store:  store,
+		client: &http.Client{Timeout: webhookTimeout},
+	}
+}
+
+// file describes the export file being delivered.
+type file struct {
+	key         string
+	name        string
+	contentType string
+}
+
+// Deliver sends the file of a completed export to the destination.
+func (d *Deliverer) Deliver(ctx context.Context, destination *domain.ExportDestination, export *domain.Export) error {
+	if export.FilePath == "" {
+		return errors.New("export has no file")
+	}
+
+	config, err := destination.GetConfig()
+	if err != nil {
+		return fmt.Errorf("failed to parse destination config: %w", err)
+	}
+
+	f := file{
+		key:         export.FilePath,
+		name:        filepath.Base(export.FilePath),
+		contentType: strategy.ContentType(export),
+	}
+
+	switch destination.Type {
+	case domain.ExportDestinationWebhook:
+		if config.Webhook == nil {
+			return errors.New("webhook destination has no webhook config")
+		}
+		return d.deliverWebhook(ctx, config.Webhook, export, f)
+	case domain.ExportDestinationSFTP:
+		if config.SFTP == nil {
+			return errors.New("sftp destination has no sftp config")
+		}
+		return d.deliverSFTP(ctx, config.SFTP, f)
+	default:
+		return fmt.Errorf("unsupported destination type: %s", destination.Type)
+	}
+}
@@ -0,0 +1,69 @@
+package delivery
+
+import (
+	"context"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/kjanat/chatlogger-api-go/internal/domain"
+	"github.com/kjanat/chatlogger-api-go/internal/storage"
+)
+
+const testExportContent = `[{"id":1,"title":"Support chat"}]`
+
+// newTestDeliverer returns a deliverer over local storage holding the file of
+// a completed export.
+func newTestDeliverer(t *testing.T) (*Deliverer, *domain.Export) {
+	t.Helper()
+
+	store, err := storage.NewLocal(t.TempDir())
+	if err != nil {
+		t.Fatalf("NewLocal: %v", err)
+	}
+
+	export := &domain.Export{
+		ID:             42,
+		OrganizationID: 7,
+		Format:         domain.ExportFormatJSON,
+		Type:           domain.ExportTypeChats,
+		Status:         domain.ExportStatusCompleted,
+		FilePath:       "7/export_42.json",
+	}
+
+	err = store.Put(context.Background(), export.FilePath, func(w io.Writer) error {
+		_, err := io.WriteString(w, testExportContent)
+		return err
+	})
+	if err != nil {
+		t.Fatalf("Put: %v", err)
+	}
+
+	return NewDeliverer(store), export
+}
+
+// newTestDestination returns a destination of the given type and config.
+func newTestDestination(t *testing.T, destinationType domain.ExportDestinationType, config *domain.ExportDestinationConfig) *domain.ExportDestination {
+	t.Helper()
+
+	destination := &domain.ExportDestination{Type: destinationType}
+	if err := destination.SetConfig(config); err != nil {
+		t.Fatalf("SetConfig: %v", err)
+	}
+
+	return destination
+}
+
+func TestDeliverRequiresExportFile(t *testing.T) {
+	deliverer, export := newTestDeliverer(t)
+	export.FilePath = ""
+
+	destination := newTestDestination(t, domain.ExportDestinationWebhook, &domain.ExportDestinationConfig{
+		Webhook: &domain.WebhookDestinationConfig{URL: "http://127.0.0.1:1"},
+	})
+
+	err := deliverer.Deliver(context.Background(), destination, export)
+	if err == nil || !strings.Contains(err.Error(), "no file") {
+		t.Fatalf("Deliver() error = %v, want export has no file", err)
+	}
+}
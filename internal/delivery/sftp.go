@@ -0,0 +1,120 @@
+package delivery
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"path"
+	"strconv"
+	"time"
+
+	"github.com/pkg/sftp"
+	"golang.org/x/crypto/ssh"
+
+	"github.com/kjanat/chatlogger-api-go/internal/domain"
+)
+
+// sftpDialTimeout bounds connecting and authenticating to SFTP servers.
+const sftpDialTimeout = 30 * time.Second
+
+// deliverSFTP uploads the export file to the SFTP server. The file is written
+// under a temporary name and renamed once complete, so readers on the server
+// never see a partial upload.
+func (d *Deliverer) deliverSFTP(ctx context.Context, config *domain.SFTPDestinationConfig, f file) error {
+	clientConfig, err := sshClientConfig(config)
+	if err != nil {
+		return err
+	}
+
+	port := config.Port
+	if port == 0 {
+		port = 22
+	}
+	addr := net.JoinHostPort(config.Host, strconv.Itoa(port))
+
+	dialer := &net.Dialer{Timeout: sftpDialTimeout}
+	conn, err := dialer.DialContext(ctx, "tcp", addr)
+	if err != nil {
+		return fmt.Errorf("failed to connect to %s: %w", addr, err)
+	}
+
+	// Abort the transfer when the task is cancelled or times out
+	stop := context.AfterFunc(ctx, func() { conn.Close() })
+	defer stop()
+
+	sshConn, chans, reqs, err := ssh.NewClientConn(conn, addr, clientConfig)
+	if err != nil {
+		conn.Close()
+		return fmt.Errorf("ssh handshake with %s failed: %w", addr, err)
+	}
+	sshClient := ssh.NewClient(sshConn, chans, reqs)
+	defer sshClient.Close()
+
+	client, err := sftp.NewClient(sshClient)
+	if err != nil {
+		return fmt.Errorf("failed to start sftp session: %w", err)
+	}
+	defer client.Close()
+
+	object, err := d.store.Open(ctx, f.key)
+	if err != nil {
+		return fmt.Errorf("failed to open export file: %w", err)
+	}
+	defer object.Close()
+
+	remotePath := path.Join(config.Directory, f.name)
+	tempPath := remotePath + ".part"
+
+	remote, err := client.Create(tempPath)
+	if err != nil {
+		return fmt.Errorf("failed to create %s: %w", tempPath, err)
+	}
+	if _, err := io.Copy(remote, object); err != nil {
+		remote.Close()
+		_ = client.Remove(tempPath)
+		return fmt.Errorf("failed to upload %s: %w", tempPath, err)
+	}
+	if err := remote.Close(); err != nil {
+		_ = client.Remove(tempPath)
+		return fmt.Errorf("failed to upload %s: %w", tempPath, err)
+	}
+
+	// Prefer the atomic POSIX rename, which also replaces an existing file
+	if err := client.PosixRename(tempPath, remotePath); err != nil {
+		if err := client.Rename(tempPath, remotePath); err != nil {
+			_ = client.Remove(tempPath)
+			return fmt.Errorf("failed to rename %s: %w", tempPath, err)
+		}
+	}
+
+	return nil
+}
+
+// sshClientConfig creates the SSH client config of an SFTP destination,
+// pinning the configured host key.
+func sshClientConfig(config *domain.SFTPDestinationConfig) (*ssh.ClientConfig, error) {
+	hostKey, _, _, _, err := ssh.ParseAuthorizedKey([]byte(config.HostKey))
+	if err != nil {
+		return nil, fmt.Errorf("invalid host key: %w", err)
+	}
+
+	var auth []ssh.AuthMethod
+	if config.PrivateKey != "" {
+		signer, err := ssh.ParsePrivateKey([]byte(config.PrivateKey))
+		if err != nil {
+			return nil, fmt.Errorf("invalid private key: %w", err)
+		}
+		auth = append(auth, ssh.PublicKeys(signer))
+	}
+	if config.Password != "" {
+		auth = append(auth, ssh.Password(config.Password))
+	}
+
+	return &ssh.ClientConfig{
+		User:            config.Username,
+		Auth:            auth,
+		HostKeyCallback: ssh.FixedHostKey(hostKey),
+		Timeout:         sftpDialTimeout,
+	}, nil
+}
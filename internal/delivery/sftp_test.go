@@ -0,0 +1,212 @@
+package delivery
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"errors"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/pkg/sftp"
+	"golang.org/x/crypto/ssh"
+
+	"github.com/kjanat/chatlogger-api-go/internal/domain"
+)
+
+const (
+	testSFTPUser     = "exports"
+	testSFTPPassword = "sftp-password"
+)
+
+// newHostKey generates an ed25519 SSH host key.
+func newHostKey(t *testing.T) ssh.Signer {
+	t.Helper()
+
+	_, key, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatalf("failed to generate host key: %v", err)
+	}
+	signer, err := ssh.NewSignerFromKey(key)
+	if err != nil {
+		t.Fatalf("failed to create host key signer: %v", err)
+	}
+
+	return signer
+}
+
+// authorizedKey formats a public key as a destination host key.
+func authorizedKey(key ssh.PublicKey) string {
+	return strings.TrimSpace(string(ssh.MarshalAuthorizedKey(key)))
+}
+
+// startSFTPServer starts a local SFTP server identified by hostKey that
+// serves dir to testSFTPUser, and returns its host and port.
+func startSFTPServer(t *testing.T, hostKey ssh.Signer, dir string) (string, int) {
+	t.Helper()
+
+	config := &ssh.ServerConfig{
+		PasswordCallback: func(conn ssh.ConnMetadata, password []byte) (*ssh.Permissions, error) {
+			if conn.User() == testSFTPUser && string(password) == testSFTPPassword {
+				return nil, nil
+			}
+			return nil, errors.New("invalid credentials")
+		},
+	}
+	config.AddHostKey(hostKey)
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to listen: %v", err)
+	}
+	t.Cleanup(func() { listener.Close() })
+
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go serveSFTP(conn, config, dir)
+		}
+	}()
+
+	addr := listener.Addr().(*net.TCPAddr)
+	return addr.IP.String(), addr.Port
+}
+
+// serveSFTP serves the sftp subsystem on an SSH connection.
+func serveSFTP(conn net.Conn, config *ssh.ServerConfig, dir string) {
+	defer conn.Close()
+
+	_, chans, reqs, err := ssh.NewServerConn(conn, config)
+	if err != nil {
+		return
+	}
+	go ssh.DiscardRequests(reqs)
+
+	for newChannel := range chans {
+		if newChannel.ChannelType() != "session" {
+			_ = newChannel.Reject(ssh.UnknownChannelType, "unknown channel type")
+			continue
+		}
+		channel, requests, err := newChannel.Accept()
+		if err != nil {
+			return
+		}
+
+		go func() {
+			defer channel.Close()
+			for req := range requests {
+				// The payload of a subsystem request is the length-prefixed subsystem name
+				ok := req.Type == "subsystem" && len(req.Payload) > 4 && string(req.Payload[4:]) == "sftp"
+				_ = req.Reply(ok, nil)
+				if !ok {
+					continue
+				}
+
+				server, err := sftp.NewServer(channel, sftp.WithServerWorkingDirectory(dir))
+				if err != nil {
+					return
+				}
+				_ = server.Serve()
+				return
+			}
+		}()
+	}
+}
+
+// sftpDestination returns an SFTP destination of the server at host and port
+// that pins hostKey.
+func sftpDestination(t *testing.T, host string, port int, hostKey ssh.PublicKey) *domain.ExportDestination {
+	t.Helper()
+
+	return newTestDestination(t, domain.ExportDestinationSFTP, &domain.ExportDestinationConfig{
+		SFTP: &domain.SFTPDestinationConfig{
+			Host:     host,
+			Port:     port,
+			Username: testSFTPUser,
+			Password: testSFTPPassword,
+			HostKey:  authorizedKey(hostKey),
+		},
+	})
+}
+
+func TestDeliverSFTPUploadsFile(t *testing.T) {
+	deliverer, export := newTestDeliverer(t)
+	hostKey := newHostKey(t)
+	dir := t.TempDir()
+	host, port := startSFTPServer(t, hostKey, dir)
+
+	destination := sftpDestination(t, host, port, hostKey.PublicKey())
+	if err := deliverer.Deliver(context.Background(), destination, export); err != nil {
+		t.Fatalf("Deliver() error = %v", err)
+	}
+
+	uploaded, err := os.ReadFile(filepath.Join(dir, "export_42.json"))
+	if err != nil {
+		t.Fatalf("export file not uploaded: %v", err)
+	}
+	if string(uploaded) != testExportContent {
+		t.Errorf("uploaded file = %q, want %q", uploaded, testExportContent)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "export_42.json.part")); !os.IsNotExist(err) {
+		t.Errorf("temporary upload file left behind: %v", err)
+	}
+}
+
+func TestDeliverSFTPRejectsUnpinnedHostKey(t *testing.T) {
+	deliverer, export := newTestDeliverer(t)
+	dir := t.TempDir()
+	host, port := startSFTPServer(t, newHostKey(t), dir)
+
+	// The destination pins the key of another server
+	destination := sftpDestination(t, host, port, newHostKey(t).PublicKey())
+	err := deliverer.Deliver(context.Background(), destination, export)
+	if err == nil || !strings.Contains(err.Error(), "handshake") {
+		t.Fatalf("Deliver() error = %v, want a failed handshake", err)
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatalf("ReadDir: %v", err)
+	}
+	if len(entries) != 0 {
+		t.Errorf("server received %d files from a client that did not trust it", len(entries))
+	}
+}
+
+func TestDeliverSFTPRejectsInvalidCredentials(t *testing.T) {
+	deliverer, export := newTestDeliverer(t)
+	hostKey := newHostKey(t)
+	host, port := startSFTPServer(t, hostKey, t.TempDir())
+
+	destination := newTestDestination(t, domain.ExportDestinationSFTP, &domain.ExportDestinationConfig{
+		SFTP: &domain.SFTPDestinationConfig{
+			Host:     host,
+			Port:     port,
+			Username: testSFTPUser,
+			Password: "wrong-password",
+			HostKey:  authorizedKey(hostKey.PublicKey()),
+		},
+	})
+
+	if err := deliverer.Deliver(context.Background(), destination, export); err == nil {
+		t.Fatal("Deliver() succeeded with invalid credentials")
+	}
+}
+
+func TestSSHClientConfigRejectsInvalidHostKey(t *testing.T) {
+	_, err := sshClientConfig(&domain.SFTPDestinationConfig{
+		Host:     "sftp.example.com",
+		Username: testSFTPUser,
+		Password: testSFTPPassword,
+		HostKey:  "not a key",
+	})
+	if err == nil || !strings.Contains(err.Error(), "host key") {
+		t.Fatalf("sshClientConfig() error = %v, want an invalid host key error", err)
+	}
+}
@@ -0,0 +1,155 @@
+package delivery
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/kjanat/chatlogger-api-go/internal/domain"
+	"github.com/kjanat/chatlogger-api-go/internal/storage"
+)
+
+// Webhook request headers. The signature is "sha256=" followed by the hex
+// HMAC-SHA256 of the timestamp, a dot and the request body, keyed with the
+// destination secret.
+const (
+	HeaderEvent     = "X-ChatLogger-Event"
+	HeaderExportID  = "X-ChatLogger-Export-ID"
+	HeaderTimestamp = "X-ChatLogger-Timestamp"
+	HeaderSignature = "X-ChatLogger-Signature"
+)
+
+// EventExportCompleted is the event name of export deliveries.
+const EventExportCompleted = "export.completed"
+
+// LinkPayload is the body posted to webhooks that receive a signed link
+// instead of the export file.
+type LinkPayload struct {
+	Event          string              `json:"event"`
+	ExportID       uint64              `json:"export_id"`
+	OrganizationID uint64              `json:"organization_id"`
+	Format         domain.ExportFormat `json:"format"`
+	Type           domain.ExportType   `json:"type"`
+	Filename       string              `json:"filename"`
+	ContentType    string              `json:"content_type"`
+	URL            string              `json:"url"`
+	ExpiresAt      time.Time           `json:"expires_at"`
+}
+
+// Sign returns the signature of a webhook body sent at timestamp.
+func Sign(secret, timestamp string, body io.Reader) (string, error) {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp + "."))
+	if _, err := io.Copy(mac, body); err != nil {
+		return "", err
+	}
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil)), nil
+}
+
+// deliverWebhook posts the export file, or a signed link to it, to the webhook.
+func (d *Deliverer) deliverWebhook(ctx context.Context, config *domain.WebhookDestinationConfig, export *domain.Export, f file) error {
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+
+	var req *http.Request
+	if config.SendLink {
+		body, err := d.linkBody(ctx, export, f)
+		if err != nil {
+			return err
+		}
+
+		signature, err := Sign(config.Secret, timestamp, bytes.NewReader(body))
+		if err != nil {
+			return err
+		}
+
+		req, err = http.NewRequestWithContext(ctx, http.MethodPost, config.URL, bytes.NewReader(body))
+		if err != nil {
+			return err
+		}
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set(HeaderSignature, signature)
+	} else {
+		// The file is read twice: once to sign it, once to send it
+		signature, err := d.signFile(ctx, config.Secret, timestamp, f)
+		if err != nil {
+			return err
+		}
+
+		object, err := d.store.Open(ctx, f.key)
+		if err != nil {
+			return fmt.Errorf("failed to open export file: %w", err)
+		}
+		defer object.Close()
+
+		req, err = http.NewRequestWithContext(ctx, http.MethodPost, config.URL, object)
+		if err != nil {
+			return err
+		}
+		req.ContentLength = object.Size
+		req.Header.Set("Content-Type", f.contentType)
+		req.Header.Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", f.name))
+		req.Header.Set(HeaderSignature, signature)
+	}
+
+	req.Header.Set(HeaderEvent, EventExportCompleted)
+	req.Header.Set(HeaderExportID, strconv.FormatUint(export.ID, 10))
+	req.Header.Set(HeaderTimestamp, timestamp)
+
+	resp, err := d.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("webhook request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	// Drain a bit of the body so the connection can be reused
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("webhook responded with status %d", resp.StatusCode)
+	}
+
+	return nil
+}
+
+// signFile computes the webhook signature of an export file.
+func (d *Deliverer) signFile(ctx context.Context, secret, timestamp string, f file) (string, error) {
+	object, err := d.store.Open(ctx, f.key)
+	if err != nil {
+		return "", fmt.Errorf("failed to open export file: %w", err)
+	}
+	defer object.Close()
+
+	return Sign(secret, timestamp, object)
+}
+
+// linkBody creates the JSON body announcing a signed download link of the export file.
+func (d *Deliverer) linkBody(ctx context.Context, export *domain.Export, f file) ([]byte, error) {
+	url, err := d.store.PresignGet(ctx, f.key, f.name, LinkTTL)
+	if errors.Is(err, storage.ErrPresignNotSupported) {
+		return nil, ErrLinksNotSupported
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to create download link: %w", err)
+	}
+
+	return json.Marshal(LinkPayload{
+		Event:          EventExportCompleted,
+		ExportID:       export.ID,
+		OrganizationID: export.OrganizationID,
+		Format:         export.Format,
+		Type:           export.Type,
+		Filename:       f.name,
+		ContentType:    f.contentType,
+		URL:            url,
+		ExpiresAt:      time.Now().Add(LinkTTL),
+	})
+}
@@ -0,0 +1,153 @@
+package delivery
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/kjanat/chatlogger-api-go/internal/domain"
+)
+
+// webhookRequest is a request received by a test webhook.
+type webhookRequest struct {
+	header http.Header
+	body   []byte
+}
+
+// startWebhook starts a local webhook responding with status and returns its
+// URL and the requests it received.
+func startWebhook(t *testing.T, status int) (string, <-chan webhookRequest) {
+	t.Helper()
+
+	requests := make(chan webhookRequest, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := io.ReadAll(r.Body)
+		if err != nil {
+			t.Errorf("failed to read webhook body: %v", err)
+		}
+		requests <- webhookRequest{header: r.Header.Clone(), body: body}
+		w.WriteHeader(status)
+	}))
+	t.Cleanup(server.Close)
+
+	return server.URL, requests
+}
+
+// expectedSignature computes the signature a receiver expects, independently of Sign.
+func expectedSignature(secret, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp + "." + string(body)))
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+func TestDeliverWebhookPostsSignedFile(t *testing.T) {
+	deliverer, export := newTestDeliverer(t)
+	url, requests := startWebhook(t, http.StatusNoContent)
+
+	const secret = "webhook-secret"
+	destination := newTestDestination(t, domain.ExportDestinationWebhook, &domain.ExportDestinationConfig{
+		Webhook: &domain.WebhookDestinationConfig{URL: url, Secret: secret},
+	})
+
+	if err := deliverer.Deliver(context.Background(), destination, export); err != nil {
+		t.Fatalf("Deliver() error = %v", err)
+	}
+
+	req := <-requests
+	if string(req.body) != testExportContent {
+		t.Errorf("body = %q, want %q", req.body, testExportContent)
+	}
+
+	timestamp := req.header.Get(HeaderTimestamp)
+	sentAt, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil {
+		t.Fatalf("invalid %s header %q: %v", HeaderTimestamp, timestamp, err)
+	}
+	if age := time.Since(time.Unix(sentAt, 0)); age < 0 || age > time.Minute {
+		t.Errorf("%s is %v old, want a current timestamp", HeaderTimestamp, age)
+	}
+
+	if got, want := req.header.Get(HeaderSignature), expectedSignature(secret, timestamp, req.body); got != want {
+		t.Errorf("%s = %q, want %q", HeaderSignature, got, want)
+	}
+	if got := req.header.Get(HeaderEvent); got != EventExportCompleted {
+		t.Errorf("%s = %q, want %q", HeaderEvent, got, EventExportCompleted)
+	}
+	if got := req.header.Get(HeaderExportID); got != "42" {
+		t.Errorf("%s = %q, want 42", HeaderExportID, got)
+	}
+	if got := req.header.Get("Content-Disposition"); !strings.Contains(got, `filename="export_42.json"`) {
+		t.Errorf("Content-Disposition = %q, want the export file name", got)
+	}
+}
+
+func TestDeliverWebhookSignatureDependsOnSecret(t *testing.T) {
+	deliverer, export := newTestDeliverer(t)
+	url, requests := startWebhook(t, http.StatusOK)
+
+	destination := newTestDestination(t, domain.ExportDestinationWebhook, &domain.ExportDestinationConfig{
+		Webhook: &domain.WebhookDestinationConfig{URL: url, Secret: "webhook-secret"},
+	})
+
+	if err := deliverer.Deliver(context.Background(), destination, export); err != nil {
+		t.Fatalf("Deliver() error = %v", err)
+	}
+
+	req := <-requests
+	forged := expectedSignature("another-secret", req.header.Get(HeaderTimestamp), req.body)
+	if req.header.Get(HeaderSignature) == forged {
+		t.Error("signature verifies with a different secret")
+	}
+}
+
+func TestDeliverWebhookFailsOnErrorStatus(t *testing.T) {
+	deliverer, export := newTestDeliverer(t)
+	url, _ := startWebhook(t, http.StatusInternalServerError)
+
+	destination := newTestDestination(t, domain.ExportDestinationWebhook, &domain.ExportDestinationConfig{
+		Webhook: &domain.WebhookDestinationConfig{URL: url, Secret: "webhook-secret"},
+	})
+
+	err := deliverer.Deliver(context.Background(), destination, export)
+	if err == nil || !strings.Contains(err.Error(), "500") {
+		t.Fatalf("Deliver() error = %v, want the status in the error", err)
+	}
+}
+
+func TestDeliverWebhookLinkNeedsPresigningStorage(t *testing.T) {
+	deliverer, export := newTestDeliverer(t)
+	url, requests := startWebhook(t, http.StatusOK)
+
+	destination := newTestDestination(t, domain.ExportDestinationWebhook, &domain.ExportDestinationConfig{
+		Webhook: &domain.WebhookDestinationConfig{URL: url, Secret: "webhook-secret", SendLink: true},
+	})
+
+	err := deliverer.Deliver(context.Background(), destination, export)
+	if !errors.Is(err, ErrLinksNotSupported) {
+		t.Fatalf("Deliver() error = %v, want %v", err, ErrLinksNotSupported)
+	}
+	if len(requests) != 0 {
+		t.Error("webhook was called although no link could be created")
+	}
+}
+
+func TestSign(t *testing.T) {
+	body := []byte(`{"event":"export.completed"}`)
+
+	got, err := Sign("secret", "1700000000", strings.NewReader(string(body)))
+	if err != nil {
+		t.Fatalf("Sign() error = %v", err)
+	}
+	if want := expectedSignature("secret", "1700000000", body); got != want {
+		t.Errorf("Sign() = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,560 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+// MaxTimeSeriesBuckets is the largest number of buckets a time series can span.
+const MaxTimeSeriesBuckets = 2000
+
+// ErrInvalidAnalyticsQuery is returned when the parameters of an analytics query are invalid.
+var ErrInvalidAnalyticsQuery = errors.New("invalid analytics query")
+
+// TimeBucket is the width of the buckets of a time series.
+type TimeBucket string
+
+// Time bucket constants define the supported bucket widths.
+const (
+	TimeBucketHour  TimeBucket = "hour"
+	TimeBucketDay   TimeBucket = "day"
+	TimeBucketWeek  TimeBucket = "week" // Weeks start on Monday
+	TimeBucketMonth TimeBucket = "month"
+)
+
+// IsValid checks if the time bucket is supported.
+func (b TimeBucket) IsValid() bool {
+	switch b {
+	case TimeBucketHour, TimeBucketDay, TimeBucketWeek, TimeBucketMonth:
+		return true
+	}
+
+	return false
+}
+
+// Interval returns the width of the bucket as a PostgreSQL interval.
+func (b TimeBucket) Interval() string {
+	return "1 " + string(b)
+}
+
+// minDuration returns the shortest duration a bucket can have.
+func (b TimeBucket) minDuration() time.Duration {
+	switch b {
+	case TimeBucketHour:
+		return time.Hour
+	case TimeBucketDay:
+		// Days are an hour shorter when daylight saving time starts
+		return 23 * time.Hour
+	case TimeBucketWeek:
+		return 7*24*time.Hour - time.Hour
+	default:
+		return 28*24*time.Hour - time.Hour
+	}
+}
+
+// TimeSeriesQuery selects a time series of an organization's activity.
+// Buckets are aligned to the calendar of the timezone, so daily buckets start
+// at local midnight.
+type TimeSeriesQuery struct {
+	Start    time.Time
+	End      time.Time
+	Bucket   TimeBucket
+	Timezone string // IANA name, e.g. Europe/Amsterdam
+}
+
+// Normalize applies the defaults: the last 30 days in daily UTC buckets.
+func (q *TimeSeriesQuery) Normalize() {
+	if q.End.IsZero() {
+		q.End = time.Now()
+	}
+	if q.Start.IsZero() {
+		q.Start = q.End.AddDate(0, 0, -30)
+	}
+	if q.Bucket == "" {
+		q.Bucket = TimeBucketDay
+	}
+	if q.Timezone == "" {
+		q.Timezone = "UTC"
+	}
+}
+
+// Location loads the timezone of the query.
+func (q *TimeSeriesQuery) Location() (*time.Location, error) {
+	return loadTimezone(q.Timezone)
+}
+
+// loadTimezone loads a timezone by its IANA name.
+func loadTimezone(name string) (*time.Location, error) {
+	// Local is the server's timezone, which the database knows nothing about
+	if name == "Local" {
+		return nil, fmt.Errorf("unknown timezone %q", name)
+	}
+
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		return nil, fmt.Errorf("unknown timezone %q", name)
+	}
+
+	return loc, nil
+}
+
+// Validate performs validation on the time series query.
+func (q *TimeSeriesQuery) Validate() error {
+	if !q.Bucket.IsValid() {
+		return errors.New("invalid bucket, must be 'hour', 'day', 'week' or 'month'")
+	}
+
+	if !q.Start.Before(q.End) {
+		return errors.New("start must be before end")
+	}
+
+	if q.End.Sub(q.Start)/q.Bucket.minDuration() >= MaxTimeSeriesBuckets {
+		return fmt.Errorf("date range spans more than %d buckets, use a wider bucket", MaxTimeSeriesBuckets)
+	}
+
+	_, err := q.Location()
+
+	return err
+}
+
+// ResponseTimeStats summarizes the response times of messages, in
+// milliseconds. The fields are nil when no message reported a response time.
+type ResponseTimeStats struct {
+	Count int64    `json:"count"` // Messages reporting a response time
+	Avg   *float64 `json:"avg"`
+	P50   *float64 `json:"p50"`
+	P90   *float64 `json:"p90"`
+	P95   *float64 `json:"p95"`
+	P99   *float64 `json:"p99"`
+}
+
+// TimeSeriesPoint is the activity of one bucket of a time series.
+type TimeSeriesPoint struct {
+	Start          time.Time             `json:"start"` // Start of the bucket in the query's timezone
+	Chats          int64                 `json:"chats"` // Chats created
+	Messages       int64                 `json:"messages"`
+	MessagesByRole map[MessageRole]int64 `json:"messages_by_role"`
+	Tokens         int64                 `json:"tokens"` // Sum of the messages' token counts
+	ResponseTime   ResponseTimeStats     `json:"response_time"`
+}
+
+// TimeSeries is the activity of an organization bucketed over a date range.
+// Every bucket of the range is present, including empty ones.
+type TimeSeries struct {
+	Bucket   TimeBucket        `json:"bucket"`
+	Timezone string            `json:"timezone"`
+	Start    time.Time         `json:"start"`
+	End      time.Time         `json:"end"`
+	Points   []TimeSeriesPoint `json:"points"`
+}
+
+// Quality report limits.
+const (
+	// SatisfiedRating is the lowest user rating counted as satisfied in the
+	// CSAT score, on the usual 1-5 scale.
+	SatisfiedRating = 4
+	// DefaultTopCategories is the number of question categories reported when none is requested.
+	DefaultTopCategories = 10
+	// MaxTopCategories is the most question categories reported.
+	MaxTopCategories = 50
+)
+
+// QualityDimension is a chat attribute quality reports can be broken down by.
+type QualityDimension string
+
+// Quality dimension constants define the supported breakdowns.
+const (
+	QualityByCountry  QualityDimension = "country"
+	QualityByLanguage QualityDimension = "language"
+	QualityByTag      QualityDimension = "tag" // A chat counts towards each of its tags
+)
+
+// IsValid checks if the quality dimension is supported.
+func (d QualityDimension) IsValid() bool {
+	switch d {
+	case QualityByCountry, QualityByLanguage, QualityByTag:
+		return true
+	}
+
+	return false
+}
+
+// QualityQuery selects a quality report over the chats of an organization
+// created in a date range. Zero-valued filters do not restrict the report.
+type QualityQuery struct {
+	Start         time.Time
+	End           time.Time
+	GroupBy       QualityDimension // Empty reports the totals only
+	CountryCode   string
+	LanguageCode  string
+	Tags          []string // Chat must carry at least one of these tags
+	TopCategories int
+}
+
+// Normalize applies the defaults: the last 30 days and the top 10 categories.
+func (q *QualityQuery) Normalize() {
+	if q.End.IsZero() {
+		q.End = time.Now()
+	}
+	if q.Start.IsZero() {
+		q.Start = q.End.AddDate(0, 0, -30)
+	}
+	if q.TopCategories == 0 {
+		q.TopCategories = DefaultTopCategories
+	}
+}
+
+// Validate performs validation on the quality query.
+func (q *QualityQuery) Validate() error {
+	if !q.Start.Before(q.End) {
+		return errors.New("start must be before end")
+	}
+
+	if q.GroupBy != "" && !q.GroupBy.IsValid() {
+		return errors.New("invalid group_by, must be 'country', 'language' or 'tag'")
+	}
+
+	if q.TopCategories < 1 || q.TopCategories > MaxTopCategories {
+		return fmt.Errorf("top_categories must be between 1 and %d", MaxTopCategories)
+	}
+
+	return nil
+}
+
+// CategoryCount is a question category with the number of chats asking it.
+type CategoryCount struct {
+	Category string `json:"category"`
+	Chats    int64  `json:"chats"`
+}
+
+// QualityStats summarizes the quality of a set of chats. Averages and rates
+// are nil when there is nothing to compute them over.
+type QualityStats struct {
+	Chats              int64            `json:"chats"`
+	RatedChats         int64            `json:"rated_chats"`
+	AvgRating          *float64         `json:"avg_rating"`
+	CSAT               *float64         `json:"csat"` // Share of rated chats rated SatisfiedRating or higher, 0-1
+	RatingDistribution map[int]int64    `json:"rating_distribution"`
+	Sentiment          map[string]int64 `json:"sentiment"` // Chats without a sentiment count as "unknown"
+	EscalatedChats     int64            `json:"escalated_chats"`
+	EscalationRate     *float64         `json:"escalation_rate"` // 0-1
+	ForwardedToHRChats int64            `json:"forwarded_to_hr_chats"`
+	HRForwardRate      *float64         `json:"hr_forward_rate"` // 0-1
+	TopCategories      []CategoryCount  `json:"top_categories"`
+}
+
+// QualityGroup is the quality of the chats sharing a value of the breakdown
+// dimension. Key is empty for the chats without a value.
+type QualityGroup struct {
+	Key string `json:"key"`
+	QualityStats
+}
+
+// QualityReport is the quality of an organization's chats over a date range.
+type QualityReport struct {
+	Start   time.Time        `json:"start"`
+	End     time.Time        `json:"end"`
+	GroupBy QualityDimension `json:"group_by,omitempty"`
+	Totals  QualityStats     `json:"totals"`
+	Groups  []QualityGroup   `json:"groups,omitempty"` // Most chats first
+}
+
+// Slow chat list limits.
+const (
+	// DefaultSlowChats is the number of chats listed when no limit is requested.
+	DefaultSlowChats = 10
+	// MaxSlowChats is the most chats listed.
+	MaxSlowChats = 100
+)
+
+// LatencyDimension is an attribute latency reports can be broken down by.
+type LatencyDimension string
+
+// Latency dimension constants define the supported breakdowns.
+const (
+	LatencyByTag    LatencyDimension = "tag" // A response counts towards each tag of its chat
+	LatencyByAPIKey LatencyDimension = "api_key"
+)
+
+// IsValid checks if the latency dimension is supported.
+func (d LatencyDimension) IsValid() bool {
+	switch d {
+	case LatencyByTag, LatencyByAPIKey:
+		return true
+	}
+
+	return false
+}
+
+// LatencyQuery selects a latency report over the assistant messages of an
+// organization sent in a date range, bucketed like a time series.
+type LatencyQuery struct {
+	TimeSeriesQuery
+	GroupBy LatencyDimension // Empty reports the totals and buckets only
+	Tags    []string         // Chat must carry at least one of these tags
+}
+
+// Validate performs validation on the latency query.
+func (q *LatencyQuery) Validate() error {
+	if err := q.TimeSeriesQuery.Validate(); err != nil {
+		return err
+	}
+
+	if q.GroupBy != "" && !q.GroupBy.IsValid() {
+		return errors.New("invalid group_by, must be 'tag' or 'api_key'")
+	}
+
+	return nil
+}
+
+// LatencyStats summarizes the response times of assistant messages, in
+// milliseconds. Percentiles are nil when no message reported a response time,
+// and the SLA fields when the organization has not configured an SLA.
+type LatencyStats struct {
+	Responses     int64    `json:"responses"` // Assistant messages reporting a response time
+	Avg           *float64 `json:"avg"`
+	P50           *float64 `json:"p50"`
+	P90           *float64 `json:"p90"`
+	P99           *float64 `json:"p99"`
+	SLABreaches   *int64   `json:"sla_breaches,omitempty"`    // Responses slower than the SLA
+	SLABreachRate *float64 `json:"sla_breach_rate,omitempty"` // 0-1
+}
+
+// LatencyPoint is the latency of one bucket of a latency report.
+type LatencyPoint struct {
+	Start time.Time `json:"start"` // Start of the bucket in the query's timezone
+	LatencyStats
+}
+
+// LatencyGroup is the latency of the responses sharing a value of the
+// breakdown dimension. Key is the tag or API key ID, empty for untagged chats
+// or chats created from the dashboard.
+type LatencyGroup struct {
+	Key   string `json:"key"`
+	Label string `json:"label,omitempty"` // Label of the API key
+	LatencyStats
+}
+
+// LatencyReport is the assistant response latency of an organization over a
+// date range. Every bucket of the range is present, including empty ones.
+type LatencyReport struct {
+	Bucket   TimeBucket       `json:"bucket"`
+	Timezone string           `json:"timezone"`
+	Start    time.Time        `json:"start"`
+	End      time.Time        `json:"end"`
+	GroupBy  LatencyDimension `json:"group_by,omitempty"`
+	SLA      *float64         `json:"sla_ms"` // Response time SLA of the organization, nil when not configured
+	Totals   LatencyStats     `json:"totals"`
+	Points   []LatencyPoint   `json:"points"`
+	Groups   []LatencyGroup   `json:"groups,omitempty"` // Most responses first
+}
+
+// SlowChatQuery selects the chats of an organization created in a date range
+// with the slowest average response time.
+type SlowChatQuery struct {
+	Start time.Time
+	End   time.Time
+	Tags  []string // Chat must carry at least one of these tags
+	Limit int
+}
+
+// Normalize applies the defaults: the 10 slowest chats of the last 30 days.
+func (q *SlowChatQuery) Normalize() {
+	if q.End.IsZero() {
+		q.End = time.Now()
+	}
+	if q.Start.IsZero() {
+		q.Start = q.End.AddDate(0, 0, -30)
+	}
+	if q.Limit == 0 {
+		q.Limit = DefaultSlowChats
+	}
+}
+
+// Validate performs validation on the slow chat query.
+func (q *SlowChatQuery) Validate() error {
+	if !q.Start.Before(q.End) {
+		return errors.New("start must be before end")
+	}
+
+	if q.Limit < 1 || q.Limit > MaxSlowChats {
+		return fmt.Errorf("limit must be between 1 and %d", MaxSlowChats)
+	}
+
+	return nil
+}
+
+// SlowChat is a chat with its assistant response times, in milliseconds.
+// Chats whose messages report no response time are ranked by the average
+// response time of their metadata.
+type SlowChat struct {
+	ChatID          uint64    `json:"chat_id"`
+	Title           string    `json:"title"`
+	APIKeyID        *uint64   `json:"api_key_id,omitempty"`
+	CreatedAt       time.Time `json:"created_at"`
+	Responses       int64     `json:"responses"` // Assistant messages reporting a response time
+	AvgResponseTime float64   `json:"avg_response_time"`
+	MaxResponseTime *float64  `json:"max_response_time"`
+	SLABreaches     *int64    `json:"sla_breaches,omitempty"`
+}
+
+// UsageDimension is an attribute usage reports are broken down by.
+type UsageDimension string
+
+// Usage dimension constants define the supported breakdowns.
+const (
+	UsageByDay    UsageDimension = "day"
+	UsageByModel  UsageDimension = "model"
+	UsageByUser   UsageDimension = "user"
+	UsageByAPIKey UsageDimension = "api_key"
+)
+
+// IsValid checks if the usage dimension is supported.
+func (d UsageDimension) IsValid() bool {
+	switch d {
+	case UsageByDay, UsageByModel, UsageByUser, UsageByAPIKey:
+		return true
+	}
+
+	return false
+}
+
+// UsageQuery selects a usage report over the messages of an organization
+// sent in a date range.
+type UsageQuery struct {
+	Start    time.Time
+	End      time.Time
+	GroupBy  UsageDimension
+	Timezone string // IANA name the days are aligned to
+}
+
+// Normalize applies the defaults: the last 30 days per UTC day.
+func (q *UsageQuery) Normalize() {
+	if q.End.IsZero() {
+		q.End = time.Now()
+	}
+	if q.Start.IsZero() {
+		q.Start = q.End.AddDate(0, 0, -30)
+	}
+	if q.GroupBy == "" {
+		q.GroupBy = UsageByDay
+	}
+	if q.Timezone == "" {
+		q.Timezone = "UTC"
+	}
+}
+
+// Location loads the timezone of the query.
+func (q *UsageQuery) Location() (*time.Location, error) {
+	return loadTimezone(q.Timezone)
+}
+
+// Validate performs validation on the usage query.
+func (q *UsageQuery) Validate() error {
+	if !q.Start.Before(q.End) {
+		return errors.New("start must be before end")
+	}
+
+	if !q.GroupBy.IsValid() {
+		return errors.New("invalid group_by, must be 'day', 'model', 'user' or 'api_key'")
+	}
+
+	_, err := q.Location()
+
+	return err
+}
+
+// UsageCount is the token usage of the messages of one model sharing a value
+// of the breakdown dimension, as counted by the repository.
+type UsageCount struct {
+	Key              string
+	Label            string
+	Provider         string
+	Model            string
+	Messages         int64
+	PromptTokens     int64
+	CompletionTokens int64
+	CachedTokens     int64
+	TotalTokens      int64
+}
+
+// UsageStats summarizes the token usage of a set of messages.
+type UsageStats struct {
+	Messages         int64   `json:"messages"` // Messages reporting token usage
+	PromptTokens     int64   `json:"prompt_tokens"`
+	CompletionTokens int64   `json:"completion_tokens"`
+	CachedTokens     int64   `json:"cached_tokens"`
+	TotalTokens      int64   `json:"total_tokens"`
+	Cost             float64 `json:"cost"` // Estimated cost of the priced tokens
+	// UnpricedTokens are the tokens left out of the cost: those of models
+	// without a price and those not split into prompt and completion tokens
+	UnpricedTokens int64 `json:"unpriced_tokens"`
+}
+
+// Add adds the usage of a model, priced at price, which may be nil.
+func (s *UsageStats) Add(count *UsageCount, price *ModelPrice) {
+	s.Messages += count.Messages
+	s.PromptTokens += count.PromptTokens
+	s.CompletionTokens += count.CompletionTokens
+	s.CachedTokens += count.CachedTokens
+	s.TotalTokens += count.TotalTokens
+
+	if price == nil {
+		s.UnpricedTokens += count.TotalTokens
+		return
+	}
+
+	s.Cost += price.Cost(count.PromptTokens, count.CompletionTokens, count.CachedTokens)
+	if unsplit := count.TotalTokens - count.PromptTokens - count.CompletionTokens; unsplit > 0 {
+		s.UnpricedTokens += unsplit
+	}
+}
+
+// UsageGroup is the token usage of the messages sharing a value of the
+// breakdown dimension. Key is the day (YYYY-MM-DD), model, user ID or API key
+// ID, empty for messages without one.
+type UsageGroup struct {
+	Key   string `json:"key"`
+	Label string `json:"label,omitempty"` // Provider of the model, email of the user or label of the API key
+	UsageStats
+}
+
+// UsageReport is the token usage and estimated cost of an organization over
+// a date range.
+type UsageReport struct {
+	Start    time.Time      `json:"start"`
+	End      time.Time      `json:"end"`
+	Timezone string         `json:"timezone"`
+	GroupBy  UsageDimension `json:"group_by"`
+	Currency string         `json:"currency"`
+	Totals   UsageStats     `json:"totals"`
+	Groups   []UsageGroup   `json:"groups"` // By day, or most expensive first
+}
+
+// AnalyticsRepository defines the interface for analytics queries.
+type AnalyticsRepository interface {
+	GetTimeSeries(orgID uint64, query *TimeSeriesQuery) ([]TimeSeriesPoint, error)
+	GetQualityReport(orgID uint64, query *QualityQuery) (*QualityReport, error)
+	// GetLatencyReport computes the latency report of query, counting the
+	// responses slower than sla as breaches. An sla of 0 counts no breaches.
+	GetLatencyReport(orgID uint64, query *LatencyQuery, sla float64) (*LatencyReport, error)
+	GetSlowestChats(orgID uint64, query *SlowChatQuery, sla float64) ([]SlowChat, error)
+	// GetUsage counts the token usage of query per value of the breakdown
+	// dimension and model.
+	GetUsage(orgID uint64, query *UsageQuery) ([]UsageCount, error)
+}
+
+// AnalyticsService defines the interface for analytics business logic.
+type AnalyticsService interface {
+	GetTimeSeries(orgID uint64, query *TimeSeriesQuery) (*TimeSeries, error)
+	GetQualityReport(orgID uint64, query *QualityQuery) (*QualityReport, error)
+	// GetLatencyReport reports the assistant response latency of an
+	// organization against its response time SLA.
+	GetLatencyReport(orgID uint64, query *LatencyQuery) (*LatencyReport, error)
+	// GetSlowestChats lists the chats with the slowest average response time.
+	GetSlowestChats(orgID uint64, query *SlowChatQuery) ([]SlowChat, error)
+	// GetUsageReport reports the token usage of an organization with its cost
+	// estimated from the organization's model prices.
+	GetUsageReport(orgID uint64, query *UsageQuery) (*UsageReport, error)
+}
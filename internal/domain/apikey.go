@@ -15,6 +15,7 @@ type APIKey struct {
 	Label          string     `gorm:"size:100;not null"             json:"label"`
 	CreatedAt      time.Time  `                                     json:"created_at"`
 	RevokedAt      *time.Time `                                     json:"revoked_at,omitempty"`
+	LastUsedAt     *time.Time `                                     json:"last_used_at,omitempty"` // Up to APIKeyLastUsedResolution stale
 }
 
 // APIKeyRepository defines the interface for API key data operations.
@@ -25,6 +26,8 @@ type APIKeyRepository interface {
 	ListByOrganizationID(orgID uint64) ([]APIKey, error)
 	Revoke(id uint64) error
 	Delete(id uint64) error
+	// TouchLastUsed records the use of an API key at usedAt.
+	TouchLastUsed(id uint64, usedAt time.Time) error
 }
 
 // APIKeyService defines the interface for API key business logic.
@@ -35,4 +38,7 @@ type APIKeyService interface {
 	ListByOrganizationID(orgID uint64) ([]APIKey, error)
 	RevokeKey(id uint64) error
 	DeleteKey(id uint64) error
+	// RecordUse records the use of an API key, unless its last use was
+	// recorded less than APIKeyLastUsedResolution ago.
+	RecordUse(key *APIKey) error
 }
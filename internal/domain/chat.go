@@ -28,6 +28,7 @@ type Chat struct {
 	Organization   Organization `gorm:"foreignKey:OrganizationID" json:"-"`
 	UserID         *uint64      `                                 json:"user_id,omitempty"` // Nullable for anonymous chats
 	User           *User        `gorm:"foreignKey:UserID"         json:"-"`
+	APIKeyID       *uint64      `gorm:"index"                     json:"api_key_id,omitempty"` // Key the chat was created with, nil for dashboard chats
 	Title          string       `gorm:"size:255"                  json:"title"`
 	Tags           string       `gorm:"type:jsonb"                json:"tags"`     // JSON array of tags as string
 	Metadata       string       `gorm:"type:jsonb"                json:"metadata"` // Store ChatMetadata as JSON string
@@ -87,21 +88,45 @@ func (c *Chat) SetMetadata(metadata *ChatMetadata) error {
 type ChatRepository interface {
 	Create(chat *Chat) error
 	FindByID(id uint64) (*Chat, error)
-	FindByOrganizationID(orgID uint64, limit, offset int) ([]Chat, error)
+	FindByOrganizationID(orgID uint64, filter *ChatFilter, sort *ChatSort, page *PageRequest) (*Page[Chat], error)
+	FindByOrganizationIDAfter(orgID, afterID uint64, filter *ChatFilter, limit int) ([]Chat, error)
 	FindByUserID(userID uint64, limit, offset int) ([]Chat, error)
 	Update(chat *Chat) error
 	Delete(id uint64) error
 	CountByOrgIDAndDateRange(orgID uint64, start, end time.Time) (int64, error)
-	GetTagStats(orgID uint64) (map[string]int64, error)
+	CountByOrganizationID(orgID uint64, filter *ChatFilter) (int64, error)
+	GetTagStats(orgID uint64, start, end time.Time) (map[string]int64, error)
+	ListTags(orgID uint64) ([]TagCount, error)
+	MergeTags(orgID uint64, sources []string, target string) (int64, error)
+	UpdateTags(orgID uint64, update *TagUpdate) (int64, error)
 }
 
 // ChatService defines the interface for chat business logic.
 type ChatService interface {
 	CreateChat(chat *Chat) error
 	GetByID(id uint64) (*Chat, error)
-	GetByOrganizationID(orgID uint64, limit, offset int) ([]Chat, error)
+	// GetByOrganizationID gets a page of the chats of an organization matching
+	// filter, ordered by sort. A nil filter matches every chat and a nil sort
+	// lists the newest chats first.
+	GetByOrganizationID(orgID uint64, filter *ChatFilter, sort *ChatSort, page *PageRequest) (*Page[Chat], error)
+	// IterateByOrganizationID calls fn with successive batches of at most batchSize
+	// chats, ordered by ID, until every chat of the organization matching filter
+	// has been visited. A nil filter matches every chat.
+	IterateByOrganizationID(orgID uint64, filter *ChatFilter, batchSize int, fn func(chats []Chat) error) error
+	CountByOrganizationID(orgID uint64, filter *ChatFilter) (int64, error)
 	GetByUserID(userID uint64, limit, offset int) ([]Chat, error)
 	UpdateChat(chat *Chat) error
 	DeleteChat(id uint64) error
 	GetChatStats(orgID uint64, start, end time.Time) (map[string]any, error)
+	// ListTags lists the distinct tags of an organization's chats, most used first.
+	ListTags(orgID uint64) ([]TagCount, error)
+	// RenameTag renames a tag on every chat of an organization and returns the
+	// number of chats changed. Chats already carrying the new tag keep one copy.
+	RenameTag(orgID uint64, from, to string) (int64, error)
+	// MergeTags replaces the source tags with the target tag on every chat of an
+	// organization and returns the number of chats changed.
+	MergeTags(orgID uint64, sources []string, target string) (int64, error)
+	// UpdateTags adds and removes tags on a selection of chats and returns the
+	// number of chats changed.
+	UpdateTags(orgID uint64, update *TagUpdate) (int64, error)
 }
@@ -1,6 +1,10 @@
 package domain
 
-import "time"
+import (
+	"encoding/json"
+	"errors"
+	"time"
+)
 
 // ExportStatus represents the current status of an export job
 type ExportStatus string
@@ -10,14 +14,40 @@ const (
 	ExportStatusProcessing ExportStatus = "processing"
 	ExportStatusCompleted  ExportStatus = "completed"
 	ExportStatusFailed     ExportStatus = "failed"
+	ExportStatusExpired    ExportStatus = "expired"   // The retention period has passed and the file was deleted
+	ExportStatusCancelled  ExportStatus = "cancelled" // Cancelled by a user before it completed
 )
 
+// ErrExportNotCancellable is returned when cancelling an export that already finished.
+var ErrExportNotCancellable = errors.New("only pending or processing exports can be cancelled")
+
+// IsCancellable reports whether an export in this status can still be cancelled.
+func (s ExportStatus) IsCancellable() bool {
+	return s == ExportStatusPending || s == ExportStatusProcessing
+}
+
 // ExportFormat represents the format of an export
 type ExportFormat string
 
 const (
-	ExportFormatJSON ExportFormat = "json"
-	ExportFormatCSV  ExportFormat = "csv"
+	ExportFormatJSON           ExportFormat = "json"
+	ExportFormatCSV            ExportFormat = "csv"
+	ExportFormatNDJSON         ExportFormat = "ndjson"          // One chat per line, messages embedded
+	ExportFormatNDJSONMessages ExportFormat = "ndjson_messages" // One message per line, chat fields denormalized
+	ExportFormatFineTune       ExportFormat = "finetune"        // Chat-completion fine-tuning dataset (JSONL)
+	ExportFormatMarkdown       ExportFormat = "markdown"        // Human-readable transcripts in one Markdown document
+	ExportFormatHTML           ExportFormat = "html"            // Zip of self-contained HTML transcripts with an index page
+	ExportFormatXLSX           ExportFormat = "xlsx"            // Excel workbook with summary, chats and messages sheets
+	ExportFormatParquet        ExportFormat = "parquet"         // Zip of typed chats and messages Parquet files
+)
+
+// ExportCompression represents how an export file is compressed or archived
+type ExportCompression string
+
+const (
+	ExportCompressionNone ExportCompression = "none"
+	ExportCompressionGzip ExportCompression = "gzip" // The export file, gzip-compressed
+	ExportCompressionZip  ExportCompression = "zip"  // A zip bundle of one or more files and a manifest.json
 )
 
 // ExportType represents the type of data being exported
@@ -29,33 +59,165 @@ const (
 	ExportTypeAll      ExportType = "all"
 )
 
+// IncludesMessages reports whether exports of this type need chat messages loaded.
+func (t ExportType) IncludesMessages() bool {
+	return t == ExportTypeMessages || t == ExportTypeAll
+}
+
+// FineTuneOptions controls which chats and messages end up in a fine-tuning dataset.
+type FineTuneOptions struct {
+	DropSystemMessages bool `json:"drop_system_messages,omitempty"`
+	MinUserRating      int  `json:"min_user_rating,omitempty"` // Skip chats rated lower, or not rated at all
+	ExcludeEscalated   bool `json:"exclude_escalated,omitempty"`
+}
+
+// CSVOptions controls the columns of CSV exports.
+type CSVOptions struct {
+	Columns []string `json:"columns,omitempty"` // Column names in output order, all columns when empty
+}
+
+// EncryptionOptions names the recipients an export file is encrypted to.
+type EncryptionOptions struct {
+	Recipients []string `json:"recipients"` // age X25519 public keys ("age1...")
+}
+
+// ExportOptions holds format-specific settings of an export.
+type ExportOptions struct {
+	FineTune   *FineTuneOptions   `json:"fine_tune,omitempty"`
+	CSV        *CSVOptions        `json:"csv,omitempty"`
+	Encryption *EncryptionOptions `json:"encryption,omitempty"` // Encrypt the export file; defaults to the organization's recipients
+	// DestinationIDs are the export destinations the completed export is delivered to
+	DestinationIDs []uint64 `json:"destination_ids,omitempty"`
+}
+
+// Validate performs validation on the export options.
+func (o *ExportOptions) Validate() error {
+	if o.FineTune != nil && o.FineTune.MinUserRating < 0 {
+		return errors.New("min_user_rating must not be negative")
+	}
+
+	if o.CSV != nil {
+		seen := make(map[string]bool, len(o.CSV.Columns))
+		for _, column := range o.CSV.Columns {
+			if seen[column] {
+				return errors.New("csv column " + column + " is listed twice")
+			}
+			seen[column] = true
+		}
+	}
+
+	if o.Encryption != nil && len(o.Encryption.Recipients) == 0 {
+		return errors.New("encryption requires at least one recipient")
+	}
+
+	return nil
+}
+
 // Export represents an asynchronous export job
 type Export struct {
-	ID             uint64       `json:"id" gorm:"primaryKey"`
-	OrganizationID uint64       `json:"organization_id"`
-	UserID         uint64       `json:"user_id"`
-	Format         ExportFormat `json:"format"`
-	Type           ExportType   `json:"type"`
-	Status         ExportStatus `json:"status"`
-	FilePath       string       `json:"file_path,omitempty"`
-	Error          string       `json:"error,omitempty"`
-	CreatedAt      time.Time    `json:"created_at"`
-	UpdatedAt      time.Time    `json:"updated_at"`
-	CompletedAt    *time.Time   `json:"completed_at,omitempty"`
+	ID             uint64            `json:"id" gorm:"primaryKey"`
+	OrganizationID uint64            `json:"organization_id"`
+	UserID         uint64            `json:"user_id"`
+	Format         ExportFormat      `json:"format"`
+	Type           ExportType        `json:"type"`
+	Compression    ExportCompression `json:"compression"`
+	Status         ExportStatus      `json:"status"`
+	Filters        string            `json:"filters,omitempty" gorm:"type:jsonb"` // Store ExportFilter as JSON string
+	Options        string            `json:"options,omitempty" gorm:"type:jsonb"` // Store ExportOptions as JSON string
+	ProcessedChats int               `json:"processed_chats"`                     // Chats written so far
+	TotalChats     int               `json:"total_chats"`                         // Chats matching the export, counted when processing starts
+	FilePath       string            `json:"file_path,omitempty"`                 // Storage key of the export file
+	Error          string            `json:"error,omitempty"`
+	CreatedAt      time.Time         `json:"created_at"`
+	UpdatedAt      time.Time         `json:"updated_at"`
+	CompletedAt    *time.Time        `json:"completed_at,omitempty"`
+	ExpiresAt      *time.Time        `json:"expires_at,omitempty" gorm:"index"` // Set on completion from the organization's export TTL
+	Deliveries     []ExportDelivery  `json:"deliveries,omitempty" gorm:"foreignKey:ExportID"`
+}
+
+// IsExpired reports whether the export has expired, or is past its expiry
+// time and merely waiting for the cleanup job.
+func (e *Export) IsExpired(now time.Time) bool {
+	return e.Status == ExportStatusExpired || (e.ExpiresAt != nil && !now.Before(*e.ExpiresAt))
+}
+
+// GetFilters parses the JSON filters string into the ExportFilter struct.
+// It returns nil when the export is not filtered.
+func (e *Export) GetFilters() (*ExportFilter, error) {
+	if e.Filters == "" || e.Filters == "null" || e.Filters == "{}" {
+		return nil, nil
+	}
+	var filter ExportFilter
+	if err := json.Unmarshal([]byte(e.Filters), &filter); err != nil {
+		return nil, err
+	}
+	return &filter, nil
+}
+
+// SetFilters converts the ExportFilter struct into a JSON string.
+func (e *Export) SetFilters(filter *ExportFilter) error {
+	if filter == nil {
+		e.Filters = "{}" // Store empty JSON object if nil
+		return nil
+	}
+	filtersJSON, err := json.Marshal(filter)
+	if err != nil {
+		return err
+	}
+	e.Filters = string(filtersJSON)
+	return nil
+}
+
+// GetOptions parses the JSON options string into the ExportOptions struct.
+func (e *Export) GetOptions() (*ExportOptions, error) {
+	var options ExportOptions
+	if e.Options == "" || e.Options == "null" {
+		return &options, nil // Return empty struct if no options
+	}
+	err := json.Unmarshal([]byte(e.Options), &options)
+	return &options, err
+}
+
+// SetOptions converts the ExportOptions struct into a JSON string.
+func (e *Export) SetOptions(options *ExportOptions) error {
+	if options == nil {
+		e.Options = "{}" // Store empty JSON object if nil
+		return nil
+	}
+	optionsJSON, err := json.Marshal(options)
+	if err != nil {
+		return err
+	}
+	e.Options = string(optionsJSON)
+	return nil
 }
 
 // ExportRepository defines the operations available on exports
 type ExportRepository interface {
 	Create(export *Export) error
 	GetByID(id uint64) (*Export, error)
-	GetByOrganizationID(organizationID uint64, limit, offset int) ([]*Export, error)
+	GetByOrganizationID(organizationID uint64, page *PageRequest) (*Page[*Export], error)
+	CountByOrganizationID(organizationID uint64) (int64, error)
 	UpdateStatus(id uint64, status ExportStatus, errorMsg string) error
+	// Cancel marks a pending or processing export cancelled, reporting whether it was.
+	Cancel(id uint64) (bool, error)
 	UpdateFilePath(id uint64, filePath string) error
+	UpdateProgress(id uint64, processedChats, totalChats int) error
+	// Complete records the file and expiry time of an export and marks it
+	// completed unless it was cancelled, reporting whether it was completed.
+	Complete(id uint64, filePath string, expiresAt time.Time) (bool, error)
+	FindExpired(now time.Time, limit int) ([]*Export, error)
+	MarkExpired(id uint64) error
 }
 
 // ExportService defines the interface for export-related business logic
 type ExportService interface {
-	CreateExport(orgID, userID uint64, format ExportFormat, exportType ExportType) (*Export, error)
+	CreateExport(export *Export) error
 	GetExport(id, orgID uint64) (*Export, error)
-	ListExports(orgID uint64, limit, offset int) ([]*Export, error)
+	ListExports(orgID uint64, page *PageRequest) (*Page[*Export], error)
+	// ResolveOptions applies the organization's export defaults, such as its
+	// encryption recipients, to the options of a new export.
+	ResolveOptions(orgID uint64, options *ExportOptions) (*ExportOptions, error)
+	// CancelExport stops a pending or processing export and marks it cancelled.
+	CancelExport(id, orgID uint64) (*Export, error)
 }
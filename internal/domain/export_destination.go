@@ -0,0 +1,214 @@
+package domain
+
+import (
+	"encoding/json"
+	"errors"
+	"net/url"
+	"time"
+)
+
+// ErrExportDestinationNotFound is returned when an export names a destination
+// that does not exist, belongs to another organization or is disabled.
+var ErrExportDestinationNotFound = errors.New("export destination not found")
+
+// ExportDestinationType defines where a destination delivers exports to
+type ExportDestinationType string
+
+const (
+	// ExportDestinationWebhook posts the export file, or a signed link to it, to an HTTPS endpoint
+	ExportDestinationWebhook ExportDestinationType = "webhook"
+	// ExportDestinationSFTP uploads the export file to an SFTP server
+	ExportDestinationSFTP ExportDestinationType = "sftp"
+)
+
+// WebhookDestinationConfig configures a webhook destination. Requests are signed
+// with an HMAC-SHA256 of the timestamp and body using Secret.
+type WebhookDestinationConfig struct {
+	URL      string `json:"url"`
+	Secret   string `json:"secret,omitempty"`
+	SendLink bool   `json:"send_link,omitempty"` // Post a signed download link instead of the file
+}
+
+// SFTPDestinationConfig configures an SFTP destination. Either Password or
+// PrivateKey is used to authenticate; HostKey pins the server's public key.
+type SFTPDestinationConfig struct {
+	Host       string `json:"host"`
+	Port       int    `json:"port,omitempty"` // Defaults to 22
+	Username   string `json:"username"`
+	Password   string `json:"password,omitempty"`
+	PrivateKey string `json:"private_key,omitempty"` // PEM encoded
+	HostKey    string `json:"host_key"`              // authorized_keys format, e.g. "ssh-ed25519 AAAA..."
+	Directory  string `json:"directory,omitempty"`   // Remote directory, the login directory when empty
+}
+
+// ExportDestinationConfig holds the settings of a destination. Only the field
+// matching the destination type is used.
+type ExportDestinationConfig struct {
+	Webhook *WebhookDestinationConfig `json:"webhook,omitempty"`
+	SFTP    *SFTPDestinationConfig    `json:"sftp,omitempty"`
+}
+
+// Redacted returns a copy of the config without secrets, for API responses.
+func (c *ExportDestinationConfig) Redacted() *ExportDestinationConfig {
+	redacted := &ExportDestinationConfig{}
+	if c.Webhook != nil {
+		webhook := *c.Webhook
+		webhook.Secret = ""
+		redacted.Webhook = &webhook
+	}
+	if c.SFTP != nil {
+		sftp := *c.SFTP
+		sftp.Password = ""
+		sftp.PrivateKey = ""
+		redacted.SFTP = &sftp
+	}
+	return redacted
+}
+
+// ExportDestination represents a place that completed exports of an
+// organization are delivered to.
+type ExportDestination struct {
+	ID             uint64                `json:"id" gorm:"primaryKey"`
+	OrganizationID uint64                `json:"organization_id" gorm:"index;not null"`
+	Name           string                `json:"name" gorm:"size:100;not null"`
+	Type           ExportDestinationType `json:"type" gorm:"size:20;not null"`
+	Config         string                `json:"-" gorm:"type:jsonb"` // Store ExportDestinationConfig as JSON string, including secrets
+	Enabled        bool                  `json:"enabled"`
+	CreatedAt      time.Time             `json:"created_at"`
+	UpdatedAt      time.Time             `json:"updated_at"`
+}
+
+// GetConfig parses the JSON config string into the ExportDestinationConfig struct.
+func (d *ExportDestination) GetConfig() (*ExportDestinationConfig, error) {
+	var config ExportDestinationConfig
+	if d.Config == "" || d.Config == "null" {
+		return &config, nil
+	}
+	err := json.Unmarshal([]byte(d.Config), &config)
+	return &config, err
+}
+
+// SetConfig converts the ExportDestinationConfig struct into a JSON string.
+func (d *ExportDestination) SetConfig(config *ExportDestinationConfig) error {
+	if config == nil {
+		d.Config = "{}" // Store empty JSON object if nil
+		return nil
+	}
+	configJSON, err := json.Marshal(config)
+	if err != nil {
+		return err
+	}
+	d.Config = string(configJSON)
+	return nil
+}
+
+// MarshalJSON includes the config without its secrets.
+func (d ExportDestination) MarshalJSON() ([]byte, error) {
+	type destination ExportDestination
+	config, err := d.GetConfig()
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(struct {
+		destination
+		Config *ExportDestinationConfig `json:"config"`
+	}{destination(d), config.Redacted()})
+}
+
+// Validate performs validation on the destination and its config.
+func (d *ExportDestination) Validate() error {
+	if d.Name == "" {
+		return errors.New("name is required")
+	}
+
+	config, err := d.GetConfig()
+	if err != nil {
+		return errors.New("config is not valid JSON")
+	}
+
+	switch d.Type {
+	case ExportDestinationWebhook:
+		if config.Webhook == nil {
+			return errors.New("config.webhook is required for webhook destinations")
+		}
+		u, err := url.Parse(config.Webhook.URL)
+		if err != nil || (u.Scheme != "https" && u.Scheme != "http") || u.Host == "" {
+			return errors.New("config.webhook.url must be an http(s) URL")
+		}
+		if config.Webhook.Secret == "" {
+			return errors.New("config.webhook.secret is required to sign deliveries")
+		}
+	case ExportDestinationSFTP:
+		if config.SFTP == nil {
+			return errors.New("config.sftp is required for sftp destinations")
+		}
+		if config.SFTP.Host == "" || config.SFTP.Username == "" {
+			return errors.New("config.sftp.host and config.sftp.username are required")
+		}
+		if config.SFTP.Port < 0 || config.SFTP.Port > 65535 {
+			return errors.New("config.sftp.port is out of range")
+		}
+		if config.SFTP.Password == "" && config.SFTP.PrivateKey == "" {
+			return errors.New("config.sftp.password or config.sftp.private_key is required")
+		}
+		if config.SFTP.HostKey == "" {
+			return errors.New("config.sftp.host_key is required")
+		}
+	default:
+		return errors.New("type must be webhook or sftp")
+	}
+
+	return nil
+}
+
+// ExportDeliveryStatus defines the status of an export delivery
+type ExportDeliveryStatus string
+
+const (
+	// ExportDeliveryPending means the delivery waits for the export to complete, or for a retry
+	ExportDeliveryPending ExportDeliveryStatus = "pending"
+	// ExportDeliveryDelivered means the export was delivered
+	ExportDeliveryDelivered ExportDeliveryStatus = "delivered"
+	// ExportDeliveryFailed means every delivery attempt failed
+	ExportDeliveryFailed ExportDeliveryStatus = "failed"
+)
+
+// ExportDelivery records the delivery of an export to one destination.
+type ExportDelivery struct {
+	ID            uint64               `json:"id" gorm:"primaryKey"`
+	ExportID      uint64               `json:"export_id" gorm:"index;not null"`
+	DestinationID uint64               `json:"destination_id" gorm:"index;not null"`
+	Status        ExportDeliveryStatus `json:"status" gorm:"size:20;not null"`
+	Attempts      int                  `json:"attempts"`
+	LastError     string               `json:"last_error,omitempty"`
+	DeliveredAt   *time.Time           `json:"delivered_at,omitempty"`
+	CreatedAt     time.Time            `json:"created_at"`
+	UpdatedAt     time.Time            `json:"updated_at"`
+}
+
+// ExportDestinationRepository defines the operations available on export destinations
+type ExportDestinationRepository interface {
+	Create(destination *ExportDestination) error
+	GetByID(id uint64) (*ExportDestination, error)
+	GetByOrganizationID(organizationID uint64) ([]*ExportDestination, error)
+	Update(destination *ExportDestination) error
+	Delete(id uint64) error
+}
+
+// ExportDeliveryRepository defines the operations available on export deliveries
+type ExportDeliveryRepository interface {
+	Create(delivery *ExportDelivery) error
+	GetByID(id uint64) (*ExportDelivery, error)
+	GetByExportID(exportID uint64) ([]*ExportDelivery, error)
+	// RecordAttempt stores the outcome of a delivery attempt.
+	RecordAttempt(id uint64, status ExportDeliveryStatus, errorMsg string) error
+}
+
+// ExportDestinationService defines the interface for export destination business logic
+type ExportDestinationService interface {
+	CreateDestination(destination *ExportDestination) error
+	GetDestination(id, orgID uint64) (*ExportDestination, error)
+	ListDestinations(orgID uint64) ([]*ExportDestination, error)
+	UpdateDestination(destination *ExportDestination) error
+	DeleteDestination(id, orgID uint64) error
+}
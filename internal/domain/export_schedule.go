@@ -0,0 +1,136 @@
+package domain
+
+import (
+	"encoding/json"
+	"errors"
+	"time"
+)
+
+// ErrExportScheduleDisabled is returned when a disabled export schedule is run.
+var ErrExportScheduleDisabled = errors.New("export schedule is disabled")
+
+// ExportSchedule represents a recurring export. Every time the cron expression
+// fires, a regular Export is created from the schedule and queued.
+type ExportSchedule struct {
+	ID             uint64            `json:"id" gorm:"primaryKey"`
+	OrganizationID uint64            `json:"organization_id" gorm:"index;not null"`
+	UserID         uint64            `json:"user_id"` // Creator, recorded as the requester of scheduled exports
+	Name           string            `json:"name" gorm:"size:100;not null"`
+	CronSpec       string            `json:"cron_spec" gorm:"size:100;not null"` // Standard 5-field cron expression or descriptor such as @weekly
+	Timezone       string            `json:"timezone,omitempty" gorm:"size:64"`  // IANA time zone of the cron expression, UTC when empty
+	Format         ExportFormat      `json:"format" gorm:"size:32;not null"`
+	Type           ExportType        `json:"type" gorm:"size:20;not null"`
+	Compression    ExportCompression `json:"compression" gorm:"size:10"`
+	Filters        string            `json:"filters,omitempty" gorm:"type:jsonb"` // Store ExportFilter as JSON string
+	Options        string            `json:"options,omitempty" gorm:"type:jsonb"` // Store ExportOptions as JSON string
+	LookbackHours  int               `json:"lookback_hours,omitempty"`            // Only export chats created in the last N hours of each run
+	Enabled        bool              `json:"enabled"`
+	LastRunAt      *time.Time        `json:"last_run_at,omitempty"`
+	LastExportID   *uint64           `json:"last_export_id,omitempty"`
+	CreatedAt      time.Time         `json:"created_at"`
+	UpdatedAt      time.Time         `json:"updated_at"`
+}
+
+// CronExpression returns the cron expression including its time zone, in the
+// CRON_TZ notation understood by the scheduler.
+func (s *ExportSchedule) CronExpression() string {
+	if s.Timezone == "" {
+		return s.CronSpec
+	}
+	return "CRON_TZ=" + s.Timezone + " " + s.CronSpec
+}
+
+// Validate performs validation on the schedule fields that do not depend on
+// the cron parser.
+func (s *ExportSchedule) Validate() error {
+	if s.Name == "" {
+		return errors.New("name is required")
+	}
+	if s.CronSpec == "" {
+		return errors.New("cron_spec is required")
+	}
+	if s.Timezone != "" {
+		if _, err := time.LoadLocation(s.Timezone); err != nil {
+			return errors.New("timezone is not a valid IANA time zone")
+		}
+	}
+	if s.LookbackHours < 0 {
+		return errors.New("lookback_hours must not be negative")
+	}
+
+	return nil
+}
+
+// GetFilters parses the JSON filters string into the ExportFilter struct.
+// It returns nil when the schedule is not filtered.
+func (s *ExportSchedule) GetFilters() (*ExportFilter, error) {
+	if s.Filters == "" || s.Filters == "null" || s.Filters == "{}" {
+		return nil, nil
+	}
+	var filter ExportFilter
+	if err := json.Unmarshal([]byte(s.Filters), &filter); err != nil {
+		return nil, err
+	}
+	return &filter, nil
+}
+
+// SetFilters converts the ExportFilter struct into a JSON string.
+func (s *ExportSchedule) SetFilters(filter *ExportFilter) error {
+	if filter == nil {
+		s.Filters = "{}" // Store empty JSON object if nil
+		return nil
+	}
+	filtersJSON, err := json.Marshal(filter)
+	if err != nil {
+		return err
+	}
+	s.Filters = string(filtersJSON)
+	return nil
+}
+
+// GetOptions parses the JSON options string into the ExportOptions struct.
+func (s *ExportSchedule) GetOptions() (*ExportOptions, error) {
+	var options ExportOptions
+	if s.Options == "" || s.Options == "null" {
+		return &options, nil // Return empty struct if no options
+	}
+	err := json.Unmarshal([]byte(s.Options), &options)
+	return &options, err
+}
+
+// SetOptions converts the ExportOptions struct into a JSON string.
+func (s *ExportSchedule) SetOptions(options *ExportOptions) error {
+	if options == nil {
+		s.Options = "{}" // Store empty JSON object if nil
+		return nil
+	}
+	optionsJSON, err := json.Marshal(options)
+	if err != nil {
+		return err
+	}
+	s.Options = string(optionsJSON)
+	return nil
+}
+
+// ExportScheduleRepository defines the operations available on export schedules
+type ExportScheduleRepository interface {
+	Create(schedule *ExportSchedule) error
+	GetByID(id uint64) (*ExportSchedule, error)
+	GetByOrganizationID(organizationID uint64) ([]*ExportSchedule, error)
+	ListEnabled() ([]*ExportSchedule, error)
+	Update(schedule *ExportSchedule) error
+	Delete(id uint64) error
+	UpdateLastRun(id uint64, runAt time.Time, exportID uint64) error
+}
+
+// ExportScheduleService defines the interface for export schedule business logic
+type ExportScheduleService interface {
+	CreateSchedule(schedule *ExportSchedule) error
+	GetSchedule(id, orgID uint64) (*ExportSchedule, error)
+	ListSchedules(orgID uint64) ([]*ExportSchedule, error)
+	UpdateSchedule(schedule *ExportSchedule) error
+	DeleteSchedule(id, orgID uint64) error
+	ListEnabledSchedules() ([]*ExportSchedule, error)
+	// RunSchedule creates and queues an export from the schedule.
+	RunSchedule(id uint64) (*Export, error)
+}
@@ -0,0 +1,125 @@
+package domain
+
+import (
+	"errors"
+	"time"
+)
+
+// TagMatch determines how the tags of a chat filter are matched.
+type TagMatch string
+
+// Tag match constants define how a chat must carry the tags of a filter.
+const (
+	// TagMatchAny requires a chat to carry at least one of the tags.
+	TagMatchAny TagMatch = "any"
+	// TagMatchAll requires a chat to carry every tag.
+	TagMatchAll TagMatch = "all"
+)
+
+// ChatFilter narrows down a set of chats. Zero-valued fields do not restrict the result.
+type ChatFilter struct {
+	CreatedFrom      *time.Time `json:"created_from,omitempty"`
+	CreatedTo        *time.Time `json:"created_to,omitempty"`
+	UpdatedFrom      *time.Time `json:"updated_from,omitempty"`
+	UpdatedTo        *time.Time `json:"updated_to,omitempty"`
+	Tags             []string   `json:"tags,omitempty"`         // Chat must carry these tags, see TagMatch
+	TagMatch         TagMatch   `json:"tag_match,omitempty"`    // Defaults to "any"
+	ExcludeTags      []string   `json:"exclude_tags,omitempty"` // Chat must carry none of these tags
+	UserID           *uint64    `json:"user_id,omitempty"`
+	TitleContains    string     `json:"title_contains,omitempty"` // Case-insensitive
+	IsEscalated      *bool      `json:"is_escalated,omitempty"`
+	CountryCode      string     `json:"country_code,omitempty"`
+	LanguageCode     string     `json:"language_code,omitempty"`
+	Sentiment        string     `json:"sentiment,omitempty"`
+	QuestionCategory string     `json:"question_category,omitempty"`
+	MinRating        *int       `json:"min_rating,omitempty"` // Chats without a rating never match a rating range
+	MaxRating        *int       `json:"max_rating,omitempty"`
+}
+
+// Validate performs validation on the chat filter.
+func (f *ChatFilter) Validate() error {
+	if f.CreatedFrom != nil && f.CreatedTo != nil && f.CreatedFrom.After(*f.CreatedTo) {
+		return errors.New("created_from must not be after created_to")
+	}
+
+	if f.UpdatedFrom != nil && f.UpdatedTo != nil && f.UpdatedFrom.After(*f.UpdatedTo) {
+		return errors.New("updated_from must not be after updated_to")
+	}
+
+	switch f.TagMatch {
+	case "", TagMatchAny, TagMatchAll:
+	default:
+		return errors.New("invalid tag_match, must be 'any' or 'all'")
+	}
+
+	if f.MinRating != nil && f.MaxRating != nil && *f.MinRating > *f.MaxRating {
+		return errors.New("min_rating must not be greater than max_rating")
+	}
+
+	return nil
+}
+
+// ChatSortField is a key chats can be sorted by.
+type ChatSortField string
+
+// Chat sort field constants define the keys chats can be sorted by.
+const (
+	ChatSortCreatedAt    ChatSortField = "created_at"
+	ChatSortUpdatedAt    ChatSortField = "updated_at"
+	ChatSortTitle        ChatSortField = "title"
+	ChatSortUserRating   ChatSortField = "user_rating"
+	ChatSortTokenCount   ChatSortField = "token_count"
+	ChatSortMessageCount ChatSortField = "message_count"
+)
+
+// IsValid checks if the sort field is valid.
+func (f ChatSortField) IsValid() bool {
+	switch f {
+	case ChatSortCreatedAt, ChatSortUpdatedAt, ChatSortTitle,
+		ChatSortUserRating, ChatSortTokenCount, ChatSortMessageCount:
+		return true
+	}
+
+	return false
+}
+
+// ChatSort orders a list of chats. Chats with equal keys are ordered by ID in
+// the same direction, so pages are stable.
+type ChatSort struct {
+	Field      ChatSortField `json:"field"`
+	Descending bool          `json:"descending"`
+}
+
+// DefaultChatSort lists the newest chats first.
+var DefaultChatSort = ChatSort{Field: ChatSortCreatedAt, Descending: true}
+
+// Validate performs validation on the chat sort.
+func (s *ChatSort) Validate() error {
+	if !s.Field.IsValid() {
+		return errors.New("invalid sort field, must be one of created_at, updated_at, title, " +
+			"user_rating, token_count or message_count")
+	}
+
+	return nil
+}
+
+// ExportFilter selects the chats and messages included in an export.
+type ExportFilter struct {
+	ChatFilter
+	Roles []MessageRole `json:"roles,omitempty"` // Only export messages with these roles
+}
+
+// Validate performs validation on the export filter.
+func (f *ExportFilter) Validate() error {
+	if err := f.ChatFilter.Validate(); err != nil {
+		return err
+	}
+
+	for _, role := range f.Roles {
+		if !role.IsValid() {
+			return errors.New("invalid message role in filter, must be 'user', 'assistant', or 'system'")
+		}
+	}
+
+	return nil
+}
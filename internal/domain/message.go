@@ -31,11 +31,29 @@ func (r MessageRole) IsValid() bool {
 
 // MessageMetadata represents extended information about a message.
 type MessageMetadata struct {
-	TokenCount   int     `json:"token_count,omitempty"`
-	ResponseTime float64 `json:"response_time,omitempty"` // In milliseconds
+	TokenCount       int     `json:"token_count,omitempty"`   // Total tokens, prompt plus completion tokens when not reported
+	ResponseTime     float64 `json:"response_time,omitempty"` // In milliseconds
+	Model            string  `json:"model,omitempty"`         // Model that generated the message (e.g., "gpt-4o")
+	Provider         string  `json:"provider,omitempty"`      // Provider of the model (e.g., "openai")
+	PromptTokens     int     `json:"prompt_tokens,omitempty"`
+	CompletionTokens int     `json:"completion_tokens,omitempty"`
+	CachedTokens     int     `json:"cached_tokens,omitempty"` // Prompt tokens read from the provider's cache
 	// Add other message-specific metadata fields here if needed
 }
 
+// Validate performs validation on the message metadata.
+func (m *MessageMetadata) Validate() error {
+	if m.TokenCount < 0 || m.PromptTokens < 0 || m.CompletionTokens < 0 || m.CachedTokens < 0 {
+		return errors.New("token counts cannot be negative")
+	}
+
+	if m.CachedTokens > m.PromptTokens {
+		return errors.New("cached_tokens cannot exceed prompt_tokens")
+	}
+
+	return nil
+}
+
 // Message represents a single message in a chat.
 type Message struct {
 	ID        uint64      `gorm:"primaryKey"         json:"id"`
@@ -56,13 +74,21 @@ func (m *Message) GetMetadata() (*MessageMetadata, error) {
 	return &metadata, err
 }
 
-// SetMetadata converts the MessageMetadata struct into a JSON string.
+// SetMetadata converts the MessageMetadata struct into a JSON string. A missing
+// token count is derived from the prompt and completion tokens.
 func (m *Message) SetMetadata(metadata *MessageMetadata) error {
 	if metadata == nil {
 		m.Metadata = "{}" // Store empty JSON object if nil
 		return nil
 	}
-	metadataJSON, err := json.Marshal(metadata)
+
+	// Derive the total from the split token counts when only those are reported
+	stored := *metadata
+	if stored.TokenCount == 0 {
+		stored.TokenCount = stored.PromptTokens + stored.CompletionTokens
+	}
+
+	metadataJSON, err := json.Marshal(stored)
 	if err != nil {
 		return err
 	}
@@ -89,8 +115,12 @@ type MessageRepository interface {
 	Create(message *Message) error
 	FindByID(id uint64) (*Message, error)
 	FindByChatID(chatID uint64) ([]Message, error)
+	FindPageByChatID(chatID uint64, page *PageRequest) (*Page[Message], error)
+	CountByChatID(chatID uint64) (int64, error)
+	FindByChatIDs(chatIDs []uint64, roles []MessageRole) ([]Message, error)
 	CountByOrgIDAndDateRange(orgID uint64, start, end time.Time) (int64, error)
 	GetRoleStats(orgID uint64) (map[MessageRole]int64, error)
+	Search(orgID uint64, query *MessageSearchQuery) (*MessageSearchResult, error)
 	// Remove or update methods related to deprecated fields if they exist
 	// GetLatencyStats(orgID uint64) (map[string]float64, error)  // min, max, avg
 	// GetTokenCountStats(orgID uint64) (map[string]int64, error) // total, avg
@@ -101,6 +131,9 @@ type MessageService interface {
 	CreateMessage(message *Message) error
 	GetByID(id uint64) (*Message, error)
 	GetByChatID(chatID uint64) ([]Message, error)
+	GetPageByChatID(chatID uint64, page *PageRequest) (*Page[Message], error)
+	GetByChatIDs(chatIDs []uint64, roles []MessageRole) ([]Message, error)
+	SearchMessages(orgID uint64, query *MessageSearchQuery) (*MessageSearchResult, error)
 	// Analytics methods for messages
 	GetMessageStats(orgID uint64, start, end time.Time) (map[string]interface{}, error)
 }
@@ -1,9 +1,30 @@
 package domain
 
 import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
 	"time"
 )
 
+// DefaultExportTTL is how long export files are kept when an organization has
+// not configured its own retention period.
+const DefaultExportTTL = 7 * 24 * time.Hour
+
+// maxExportTTLHours caps the configurable export retention at one year.
+const maxExportTTLHours = 365 * 24
+
+// DefaultPriceCurrency is the currency of model prices when an organization
+// has not configured one.
+const DefaultPriceCurrency = "USD"
+
+// maxResponseTimeSLA caps the configurable response time SLA at one hour, in milliseconds.
+const maxResponseTimeSLA = 60 * 60 * 1000
+
+// currencyPattern matches ISO-4217 currency codes.
+var currencyPattern = regexp.MustCompile(`^[A-Z]{3}$`)
+
 // Organization represents a tenant in the multi-tenant system.
 type Organization struct {
 	ID        uint64    `gorm:"primaryKey"                                json:"id"`
@@ -17,6 +38,147 @@ type Organization struct {
 	Chats     []Chat    `gorm:"foreignKey:OrganizationID"                 json:"-"`
 }
 
+// OrganizationSettings represents the organization-wide settings stored as JSON.
+type OrganizationSettings struct {
+	ExportTTLHours int `json:"export_ttl_hours,omitempty"` // Hours until export files are deleted, 0 for the default
+	// ExportRecipients are age public keys that every export is encrypted to,
+	// unless the export request names its own recipients
+	ExportRecipients []string `json:"export_recipients,omitempty"`
+	// ResponseTimeSLA is the assistant response time, in milliseconds, that
+	// latency reports count breaches of, 0 for none
+	ResponseTimeSLA float64 `json:"response_time_sla_ms,omitempty"`
+	// ModelPrices are the token prices usage reports estimate the cost of
+	// messages with, in PriceCurrency
+	ModelPrices   []ModelPrice `json:"model_prices,omitempty"`
+	PriceCurrency string       `json:"price_currency,omitempty"` // ISO-4217 code, defaults to USD
+	// Quotas limit the chats, messages and bytes the organization ingests. They
+	// are set by the plan of the organization and only superadmins change them.
+	Quotas *UsageQuotas `json:"quotas,omitempty"`
+}
+
+// ModelPrice is the price of the tokens of a model, per million tokens.
+type ModelPrice struct {
+	Provider        string   `json:"provider,omitempty"` // Empty matches the model of any provider
+	Model           string   `json:"model"`
+	PromptPrice     float64  `json:"prompt_price"`
+	CompletionPrice float64  `json:"completion_price"`
+	CachedPrice     *float64 `json:"cached_price,omitempty"` // Price of cached prompt tokens, defaults to the prompt price
+}
+
+// Cost estimates the cost of token counts. Cached tokens are part of the
+// prompt tokens, so they are charged at the cached price instead.
+func (p *ModelPrice) Cost(promptTokens, completionTokens, cachedTokens int64) float64 {
+	cachedPrice := p.PromptPrice
+	if p.CachedPrice != nil {
+		cachedPrice = *p.CachedPrice
+	}
+
+	return (float64(promptTokens-cachedTokens)*p.PromptPrice +
+		float64(cachedTokens)*cachedPrice +
+		float64(completionTokens)*p.CompletionPrice) / 1_000_000
+}
+
+// FindModelPrice returns the price of a model, preferring a price for its
+// provider over one for any provider, or nil when the model has no price.
+func (s *OrganizationSettings) FindModelPrice(provider, model string) *ModelPrice {
+	var match *ModelPrice
+	for i := range s.ModelPrices {
+		price := &s.ModelPrices[i]
+		if price.Model != model {
+			continue
+		}
+
+		if price.Provider == provider {
+			return price
+		}
+		if price.Provider == "" {
+			match = price
+		}
+	}
+
+	return match
+}
+
+// Currency returns the currency of the model prices.
+func (s *OrganizationSettings) Currency() string {
+	if s.PriceCurrency == "" {
+		return DefaultPriceCurrency
+	}
+	return s.PriceCurrency
+}
+
+// Validate performs validation on the organization settings.
+func (s *OrganizationSettings) Validate() error {
+	if s.ExportTTLHours < 0 || s.ExportTTLHours > maxExportTTLHours {
+		return errors.New("export_ttl_hours must be between 0 and 8760")
+	}
+
+	if s.ResponseTimeSLA < 0 || s.ResponseTimeSLA > maxResponseTimeSLA {
+		return errors.New("response_time_sla_ms must be between 0 and 3600000")
+	}
+
+	if s.PriceCurrency != "" && !currencyPattern.MatchString(s.PriceCurrency) {
+		return errors.New("price_currency must be an ISO-4217 code, e.g. USD")
+	}
+
+	if s.Quotas != nil {
+		if err := s.Quotas.Validate(); err != nil {
+			return err
+		}
+	}
+
+	seen := make(map[[2]string]bool, len(s.ModelPrices))
+	for _, price := range s.ModelPrices {
+		if price.Model == "" {
+			return errors.New("model_prices require a model")
+		}
+
+		if price.PromptPrice < 0 || price.CompletionPrice < 0 || (price.CachedPrice != nil && *price.CachedPrice < 0) {
+			return fmt.Errorf("model_prices of %s cannot be negative", price.Model)
+		}
+
+		key := [2]string{price.Provider, price.Model}
+		if seen[key] {
+			return fmt.Errorf("model_prices lists %s more than once", price.Model)
+		}
+		seen[key] = true
+	}
+
+	return nil
+}
+
+// ExportTTL returns how long export files of the organization are kept.
+func (s *OrganizationSettings) ExportTTL() time.Duration {
+	if s.ExportTTLHours <= 0 {
+		return DefaultExportTTL
+	}
+	return time.Duration(s.ExportTTLHours) * time.Hour
+}
+
+// GetSettings parses the JSON settings string into the OrganizationSettings struct.
+func (o *Organization) GetSettings() (*OrganizationSettings, error) {
+	var settings OrganizationSettings
+	if o.Settings == "" || o.Settings == "null" {
+		return &settings, nil // Return empty struct if no settings
+	}
+	err := json.Unmarshal([]byte(o.Settings), &settings)
+	return &settings, err
+}
+
+// SetSettings converts the OrganizationSettings struct into a JSON string.
+func (o *Organization) SetSettings(settings *OrganizationSettings) error {
+	if settings == nil {
+		o.Settings = "{}" // Store empty JSON object if nil
+		return nil
+	}
+	settingsJSON, err := json.Marshal(settings)
+	if err != nil {
+		return err
+	}
+	o.Settings = string(settingsJSON)
+	return nil
+}
+
 // OrganizationRepository defines the interface for organization data operations.
 type OrganizationRepository interface {
 	Create(org *Organization) error
@@ -35,4 +197,8 @@ type OrganizationService interface {
 	Update(org *Organization) error
 	Delete(id uint64) error
 	List(limit, offset int) ([]Organization, error)
+	GetSettings(id uint64) (*OrganizationSettings, error)
+	UpdateSettings(id uint64, settings *OrganizationSettings) error
+	// UpdateQuotas replaces the usage quotas of an organization and returns its settings.
+	UpdateQuotas(id uint64, quotas *UsageQuotas) (*OrganizationSettings, error)
 }
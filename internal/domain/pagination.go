@@ -0,0 +1,76 @@
+package domain
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+)
+
+// Page size limits of paginated listings.
+const (
+	// DefaultPageLimit is the page size used when none is requested.
+	DefaultPageLimit = 20
+	// MaxPageLimit is the largest page size.
+	MaxPageLimit = 100
+)
+
+// ErrInvalidCursor is returned when a pagination cursor is malformed or was
+// issued for a listing with a different ordering.
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+// Cursor marks the position of a row in a keyset-paginated listing. Unlike an
+// offset, it keeps pointing at the same row while rows are inserted before it.
+// Clients receive cursors as opaque strings.
+type Cursor struct {
+	Key      *string `json:"k,omitempty"` // Sort key of the row as text; nil when NULL or ordering by ID only
+	ID       uint64  `json:"i"`
+	Backward bool    `json:"b,omitempty"` // Points to the page before the row rather than after it
+	Order    string  `json:"o"`           // Ordering of the listing the cursor was issued for
+}
+
+// Encode returns the opaque string form of the cursor.
+func (c *Cursor) Encode() string {
+	// Marshalling the cursor cannot fail
+	data, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// DecodeCursor parses the opaque string form of a cursor.
+func DecodeCursor(s string) (*Cursor, error) {
+	data, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+
+	var cursor Cursor
+	if err := json.Unmarshal(data, &cursor); err != nil || cursor.Order == "" {
+		return nil, ErrInvalidCursor
+	}
+
+	return &cursor, nil
+}
+
+// PageRequest selects a page of a listing.
+type PageRequest struct {
+	Limit        int
+	Cursor       *Cursor // Nil selects the first page
+	IncludeTotal bool    // Count every row of the listing, which costs an extra query
+}
+
+// Normalize applies the default page size and caps it at MaxPageLimit.
+func (p *PageRequest) Normalize() {
+	if p.Limit <= 0 {
+		p.Limit = DefaultPageLimit
+	}
+	if p.Limit > MaxPageLimit {
+		p.Limit = MaxPageLimit
+	}
+}
+
+// Page is a page of a listing with the cursors of the pages around it.
+type Page[T any] struct {
+	Items      []T
+	NextCursor *Cursor // Nil on the last page
+	PrevCursor *Cursor // Nil on the first page
+	TotalCount *int64  // Set when requested
+}
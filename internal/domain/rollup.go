@@ -0,0 +1,103 @@
+package domain
+
+import (
+	"errors"
+	"time"
+)
+
+// Rollup timing.
+const (
+	// RollupLookback is how far before the last rollup run the next run starts
+	// recomputing, to catch rows committed late.
+	RollupLookback = time.Hour
+	// RollupChunk is the time range recomputed per transaction.
+	RollupChunk = 24 * time.Hour
+)
+
+// ErrInvalidBackfillRange is returned when a backfill range is empty.
+var ErrInvalidBackfillRange = errors.New("backfill start must be before end")
+
+// ResponseTimeHistogramBounds are the upper bounds, in milliseconds, of the
+// ranges response times are counted in by the analytics rollups. A histogram
+// has one more slot than there are bounds: slot 0 counts response times below
+// the first bound and the last slot those of at least the last bound.
+var ResponseTimeHistogramBounds = []float64{
+	100, 250, 500, 750, 1000, 1500, 2000, 3000, 5000, 7500, 10000, 15000, 20000, 30000, 60000,
+}
+
+// ResponseTimeHistogram counts response times per range of ResponseTimeHistogramBounds.
+type ResponseTimeHistogram []int64
+
+// Add adds the counts of another histogram.
+func (h ResponseTimeHistogram) Add(other ResponseTimeHistogram) ResponseTimeHistogram {
+	for len(h) < len(other) {
+		h = append(h, 0)
+	}
+	for i, n := range other {
+		h[i] += n
+	}
+
+	return h
+}
+
+// Percentile estimates the p-th percentile (0-1) of the counted response
+// times by interpolating within the range it falls in. Response times beyond
+// the last bound are reported as the last bound. It returns nil for an empty
+// histogram.
+func (h ResponseTimeHistogram) Percentile(p float64) *float64 {
+	var total int64
+	for _, n := range h {
+		total += n
+	}
+	if total == 0 {
+		return nil
+	}
+
+	rank := p * float64(total)
+	var below int64
+	for slot, n := range h {
+		if n == 0 || float64(below+n) < rank {
+			below += n
+			continue
+		}
+
+		lower := 0.0
+		if slot > 0 {
+			lower = ResponseTimeHistogramBounds[min(slot, len(ResponseTimeHistogramBounds))-1]
+		}
+		if slot >= len(ResponseTimeHistogramBounds) {
+			return &lower
+		}
+
+		upper := ResponseTimeHistogramBounds[slot]
+		value := lower + (upper-lower)*(rank-float64(below))/float64(n)
+
+		return &value
+	}
+
+	// Only reached through rounding errors for p close to 1
+	last := ResponseTimeHistogramBounds[len(ResponseTimeHistogramBounds)-1]
+
+	return &last
+}
+
+// RollupRepository defines the interface for maintaining the analytics rollups.
+type RollupRepository interface {
+	// Rebuild recomputes the hourly rollups of the hours in [from, to) and the
+	// daily rollups of the days they fall in. from and to are hour-aligned.
+	// Concurrent rebuilds run one after another.
+	Rebuild(from, to time.Time) error
+	// GetWatermark returns the time everything before has been rolled up, or
+	// nil before the first rollup.
+	GetWatermark() (*time.Time, error)
+	SetWatermark(watermark time.Time) error
+}
+
+// RollupService defines the interface for the analytics rollup logic.
+type RollupService interface {
+	// Refresh rolls up the activity since the last refresh.
+	Refresh() error
+	// Backfill recomputes the rollups of a historical time range, reporting
+	// each completed chunk to progress, which may be nil.
+	Backfill(from, to time.Time, progress func(done time.Time)) error
+}
@@ -0,0 +1,90 @@
+package domain
+
+import (
+	"errors"
+	"time"
+)
+
+// Search limits.
+const (
+	// MaxSearchQueryLength is the longest search query accepted.
+	MaxSearchQueryLength = 500
+	// DefaultSearchHitsPerChat is the number of hits returned per chat when none is requested.
+	DefaultSearchHitsPerChat = 3
+	// MaxSearchHitsPerChat is the most hits returned per chat.
+	MaxSearchHitsPerChat = 20
+)
+
+// MessageSearchQuery describes a full-text search across the messages of an
+// organization. Zero-valued filters do not restrict the result.
+type MessageSearchQuery struct {
+	// Query uses web search syntax: words are combined with AND, "quoted text"
+	// matches a phrase, "or" combines alternatives and -word excludes a word.
+	Query string
+	// Language is the ISO-639 code whose stemming is applied to the query, so
+	// "refunds" also finds "refund" in chats of that language. Without a
+	// language, words are matched exactly across all languages.
+	Language    string
+	Roles       []MessageRole
+	CreatedFrom *time.Time
+	CreatedTo   *time.Time
+	Tags        []string // Chat must carry at least one of these tags
+	HitsPerChat int
+	Limit       int // Chats per page
+	Offset      int
+}
+
+// Validate performs validation on the search query.
+func (q *MessageSearchQuery) Validate() error {
+	if q.Query == "" {
+		return errors.New("search query cannot be empty")
+	}
+
+	if len(q.Query) > MaxSearchQueryLength {
+		return errors.New("search query is too long")
+	}
+
+	for _, role := range q.Roles {
+		if !role.IsValid() {
+			return errors.New("invalid message role, must be 'user', 'assistant', or 'system'")
+		}
+	}
+
+	if q.CreatedFrom != nil && q.CreatedTo != nil && q.CreatedFrom.After(*q.CreatedTo) {
+		return errors.New("created_from must not be after created_to")
+	}
+
+	if q.HitsPerChat < 0 || q.HitsPerChat > MaxSearchHitsPerChat {
+		return errors.New("hits_per_chat must be between 1 and 20")
+	}
+
+	return nil
+}
+
+// MessageSearchHit is a message matching a search.
+type MessageSearchHit struct {
+	MessageID uint64      `json:"message_id"`
+	Role      MessageRole `json:"role"`
+	// Snippet is an HTML-escaped excerpt of the content with the matching words
+	// wrapped in <mark> tags, safe to render as HTML.
+	Snippet   string    `json:"snippet"`
+	Rank      float64   `json:"rank"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// ChatSearchResult groups the hits of a search within one chat.
+type ChatSearchResult struct {
+	ChatID    uint64             `json:"chat_id"`
+	Title     string             `json:"title"`
+	Tags      []string           `json:"tags"`
+	Rank      float64            `json:"rank"`      // Rank of the best hit in the chat
+	HitCount  int64              `json:"hit_count"` // Number of matching messages in the chat
+	Hits      []MessageSearchHit `json:"hits"`      // Best hits of the chat, best first
+	CreatedAt time.Time          `json:"created_at"`
+}
+
+// MessageSearchResult is a page of chats matching a search, best match first.
+type MessageSearchResult struct {
+	Chats      []ChatSearchResult `json:"chats"`
+	TotalChats int64              `json:"total_chats"`
+}
@@ -0,0 +1,96 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+)
+
+// MaxTagLength is the longest tag accepted by tag management.
+const MaxTagLength = 100
+
+// ErrInvalidTagChange is returned when a tag rename, merge or update is invalid.
+var ErrInvalidTagChange = errors.New("invalid tag change")
+
+// TagCount is a tag with the number of chats carrying it.
+type TagCount struct {
+	Tag       string `json:"tag"`
+	ChatCount int64  `json:"chat_count"`
+}
+
+// ValidateTag checks that a tag is not blank, has no surrounding whitespace
+// and is at most MaxTagLength characters long.
+func ValidateTag(tag string) error {
+	if strings.TrimSpace(tag) == "" {
+		return errors.New("tag cannot be empty")
+	}
+
+	if strings.TrimSpace(tag) != tag {
+		return fmt.Errorf("tag %q has leading or trailing whitespace", tag)
+	}
+
+	if utf8.RuneCountInString(tag) > MaxTagLength {
+		return fmt.Errorf("tag %q is longer than %d characters", tag, MaxTagLength)
+	}
+
+	return nil
+}
+
+// ChatSelection selects the chats of an organization a bulk operation applies
+// to: the chats with the given IDs, the chats matching the filter, or, when
+// both are set, the chats with the given IDs that match the filter.
+type ChatSelection struct {
+	ChatIDs []uint64    `json:"chat_ids,omitempty"`
+	Filter  *ChatFilter `json:"filter,omitempty"` // An empty filter selects every chat
+}
+
+// Validate performs validation on the chat selection.
+func (s *ChatSelection) Validate() error {
+	if len(s.ChatIDs) == 0 && s.Filter == nil {
+		return errors.New("selection needs chat_ids or a filter")
+	}
+
+	if s.Filter != nil {
+		return s.Filter.Validate()
+	}
+
+	return nil
+}
+
+// TagUpdate adds and removes tags on a selection of chats.
+type TagUpdate struct {
+	Selection ChatSelection `json:"selection"`
+	Add       []string      `json:"add,omitempty"`
+	Remove    []string      `json:"remove,omitempty"`
+}
+
+// Validate performs validation on the tag update.
+func (u *TagUpdate) Validate() error {
+	if err := u.Selection.Validate(); err != nil {
+		return err
+	}
+
+	if len(u.Add) == 0 && len(u.Remove) == 0 {
+		return errors.New("tag update needs tags to add or remove")
+	}
+
+	removed := make(map[string]bool, len(u.Remove))
+	for _, tag := range u.Remove {
+		if err := ValidateTag(tag); err != nil {
+			return err
+		}
+		removed[tag] = true
+	}
+
+	for _, tag := range u.Add {
+		if err := ValidateTag(tag); err != nil {
+			return err
+		}
+		if removed[tag] {
+			return fmt.Errorf("tag %q cannot be both added and removed", tag)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,227 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+// Usage limits.
+const (
+	// MaxUsageDays is the longest date range the usage of an organization can be listed for.
+	MaxUsageDays = 366
+	// APIKeyLastUsedResolution is how stale the last use of an API key may get
+	// before it is recorded again, limiting the writes to one per interval.
+	APIKeyLastUsedResolution = time.Minute
+)
+
+// ErrQuotaExceeded is returned when a usage quota of an organization is reached.
+var ErrQuotaExceeded = errors.New("usage quota exceeded")
+
+// UsageResource is a resource whose ingestion is counted and limited.
+type UsageResource string
+
+// Usage resource constants define the resources counted.
+const (
+	UsageChats    UsageResource = "chats"
+	UsageMessages UsageResource = "messages"
+)
+
+// UsageCounts are the chats, messages and request bytes ingested.
+type UsageCounts struct {
+	Chats    int64 `gorm:"not null;default:0" json:"chats"`
+	Messages int64 `gorm:"not null;default:0" json:"messages"`
+	Bytes    int64 `gorm:"not null;default:0" json:"bytes"` // Size of the request bodies
+}
+
+// Add adds other counts.
+func (c *UsageCounts) Add(other UsageCounts) {
+	c.Chats += other.Chats
+	c.Messages += other.Messages
+	c.Bytes += other.Bytes
+}
+
+// UsageCounter is the ingestion of an organization through one API key, or
+// the dashboard, on one UTC day.
+type UsageCounter struct {
+	OrganizationID uint64    `gorm:"primaryKey;autoIncrement:false" json:"organization_id"`
+	APIKeyID       uint64    `gorm:"primaryKey;autoIncrement:false" json:"api_key_id"` // 0 for the dashboard
+	Day            time.Time `gorm:"primaryKey;type:date"           json:"day"`
+	UsageCounts    `gorm:"embedded"`
+	UpdatedAt      time.Time `                                      json:"updated_at"`
+}
+
+// UsageQuotas limit the ingestion of an organization per UTC day and month.
+// Zero-valued quotas are unlimited.
+type UsageQuotas struct {
+	DailyChats      int64 `json:"daily_chats,omitempty"`
+	DailyMessages   int64 `json:"daily_messages,omitempty"`
+	DailyBytes      int64 `json:"daily_bytes,omitempty"`
+	MonthlyChats    int64 `json:"monthly_chats,omitempty"`
+	MonthlyMessages int64 `json:"monthly_messages,omitempty"`
+	MonthlyBytes    int64 `json:"monthly_bytes,omitempty"`
+}
+
+// Validate performs validation on the usage quotas.
+func (q *UsageQuotas) Validate() error {
+	if q.DailyChats < 0 || q.DailyMessages < 0 || q.DailyBytes < 0 ||
+		q.MonthlyChats < 0 || q.MonthlyMessages < 0 || q.MonthlyBytes < 0 {
+		return errors.New("quotas cannot be negative")
+	}
+
+	return nil
+}
+
+// IsZero reports whether no quota is set.
+func (q *UsageQuotas) IsZero() bool {
+	return q == nil || *q == UsageQuotas{}
+}
+
+// Equal reports whether q and other set the same quotas. Nil quotas equal
+// zero-valued ones.
+func (q *UsageQuotas) Equal(other *UsageQuotas) bool {
+	if q.IsZero() || other.IsZero() {
+		return q.IsZero() && other.IsZero()
+	}
+
+	return *q == *other
+}
+
+// Check returns a QuotaError when the usage of today or this month has
+// reached a quota limiting the ingestion of resource. Request bytes count
+// towards the byte quotas of every resource.
+func (q *UsageQuotas) Check(resource UsageResource, today, month UsageCounts, now time.Time) error {
+	dayStart := UsageDay(now)
+	nextDay := dayStart.AddDate(0, 0, 1)
+	nextMonth := UsageMonth(now).AddDate(0, 1, 0)
+
+	checks := []QuotaError{
+		{Quota: "daily_bytes", Limit: q.DailyBytes, Used: today.Bytes, ResetAt: nextDay},
+		{Quota: "monthly_bytes", Limit: q.MonthlyBytes, Used: month.Bytes, ResetAt: nextMonth},
+	}
+	switch resource {
+	case UsageChats:
+		checks = append(checks,
+			QuotaError{Quota: "daily_chats", Limit: q.DailyChats, Used: today.Chats, ResetAt: nextDay},
+			QuotaError{Quota: "monthly_chats", Limit: q.MonthlyChats, Used: month.Chats, ResetAt: nextMonth},
+		)
+	case UsageMessages:
+		checks = append(checks,
+			QuotaError{Quota: "daily_messages", Limit: q.DailyMessages, Used: today.Messages, ResetAt: nextDay},
+			QuotaError{Quota: "monthly_messages", Limit: q.MonthlyMessages, Used: month.Messages, ResetAt: nextMonth},
+		)
+	}
+
+	for _, check := range checks {
+		if check.Limit > 0 && check.Used >= check.Limit {
+			return &check
+		}
+	}
+
+	return nil
+}
+
+// QuotaError reports a reached usage quota.
+type QuotaError struct {
+	Quota   string    `json:"quota"` // Name of the quota setting, e.g. daily_chats
+	Limit   int64     `json:"limit"`
+	Used    int64     `json:"used"`
+	ResetAt time.Time `json:"reset_at"` // Start of the next UTC day or month
+}
+
+// Error implements the error interface.
+func (e *QuotaError) Error() string {
+	return fmt.Sprintf("%s quota of %d reached, resets at %s", e.Quota, e.Limit, e.ResetAt.Format(time.RFC3339))
+}
+
+// Unwrap makes a QuotaError match ErrQuotaExceeded.
+func (e *QuotaError) Unwrap() error {
+	return ErrQuotaExceeded
+}
+
+// UsageDay returns the start of the UTC day usage at t is counted on.
+func UsageDay(t time.Time) time.Time {
+	t = t.UTC()
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+// UsageMonth returns the start of the UTC month of t.
+func UsageMonth(t time.Time) time.Time {
+	t = t.UTC()
+	return time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, time.UTC)
+}
+
+// UsageViewQuery selects the days the usage of an organization is listed for.
+type UsageViewQuery struct {
+	Start time.Time
+	End   time.Time
+}
+
+// Normalize applies the defaults: the last 30 days.
+func (q *UsageViewQuery) Normalize() {
+	if q.End.IsZero() {
+		q.End = time.Now()
+	}
+	if q.Start.IsZero() {
+		q.Start = q.End.AddDate(0, 0, -30)
+	}
+}
+
+// Validate performs validation on the usage view query.
+func (q *UsageViewQuery) Validate() error {
+	if q.Start.After(q.End) {
+		return errors.New("start must not be after end")
+	}
+
+	if UsageDay(q.End).Sub(UsageDay(q.Start)) >= MaxUsageDays*24*time.Hour {
+		return fmt.Errorf("date range spans more than %d days", MaxUsageDays)
+	}
+
+	return nil
+}
+
+// DailyUsage is the ingestion of an organization on one UTC day.
+type DailyUsage struct {
+	Day string `json:"day"` // YYYY-MM-DD
+	UsageCounts
+}
+
+// APIKeyUsage is the ingestion of an organization through one API key.
+type APIKeyUsage struct {
+	APIKeyID uint64 `json:"api_key_id"`      // 0 for the dashboard
+	Label    string `json:"label,omitempty"` // Empty for the dashboard and deleted keys
+	UsageCounts
+}
+
+// OrganizationUsage is the ingestion of an organization over a range of UTC
+// days, next to its current usage and quotas.
+type OrganizationUsage struct {
+	Start     time.Time     `json:"start"` // Start of the first day
+	End       time.Time     `json:"end"`   // End of the last day
+	Totals    UsageCounts   `json:"totals"`
+	Days      []DailyUsage  `json:"days"`     // Days with usage, oldest first
+	APIKeys   []APIKeyUsage `json:"api_keys"` // Most messages first
+	Today     UsageCounts   `json:"today"`
+	ThisMonth UsageCounts   `json:"this_month"`
+	Quotas    *UsageQuotas  `json:"quotas,omitempty"`
+}
+
+// UsageRepository defines the interface for usage counter data operations.
+type UsageRepository interface {
+	// Increment adds counts to the counter of an API key on a day.
+	Increment(orgID, apiKeyID uint64, day time.Time, counts UsageCounts) error
+	// Sum sums the counters of an organization on the days in [from, to).
+	Sum(orgID uint64, from, to time.Time) (*UsageCounts, error)
+	// FindByOrganizationID lists the counters of an organization on the days in [from, to).
+	FindByOrganizationID(orgID uint64, from, to time.Time) ([]UsageCounter, error)
+}
+
+// UsageService defines the interface for usage accounting and quotas.
+type UsageService interface {
+	// CheckQuota returns a QuotaError when the organization has reached a
+	// quota limiting the ingestion of resource.
+	CheckQuota(orgID uint64, resource UsageResource) error
+	// Record counts ingested resources towards an API key, 0 for the dashboard.
+	Record(orgID, apiKeyID uint64, counts UsageCounts) error
+	GetUsage(orgID uint64, query *UsageViewQuery) (*OrganizationUsage, error)
+}
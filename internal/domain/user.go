@@ -35,7 +35,8 @@ type UserRepository interface {
 	Create(user *User) error
 	FindByID(id uint64) (*User, error)
 	FindByEmail(email string) (*User, error)
-	FindByOrganizationID(orgID uint64, limit, offset int) ([]User, error)
+	FindByOrganizationID(orgID uint64, page *PageRequest) (*Page[User], error)
+	CountByOrganizationID(orgID uint64) (int64, error)
 	Update(user *User) error
 	Delete(id uint64) error
 }
@@ -46,7 +47,7 @@ type UserService interface {
 	Register(user *User, password string) error
 	GetByID(id uint64) (*User, error)
 	GetByEmail(email string) (*User, error)
-	GetByOrganizationID(orgID uint64, limit, offset int) ([]User, error)
+	GetByOrganizationID(orgID uint64, page *PageRequest) (*Page[User], error)
 	UpdateUser(user *User) error
 	ChangePassword(userID uint64, currentPassword, newPassword string) error
 	DeleteUser(id uint64) error
@@ -0,0 +1,296 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/kjanat/chatlogger-api-go/internal/domain"
+
+	"github.com/gin-gonic/gin"
+)
+
+// AnalyticsHandler handles analytics requests.
+type AnalyticsHandler struct {
+	analyticsService domain.AnalyticsService
+}
+
+// NewAnalyticsHandler creates a new analytics handler.
+func NewAnalyticsHandler(analyticsService domain.AnalyticsService) *AnalyticsHandler {
+	return &AnalyticsHandler{
+		analyticsService: analyticsService,
+	}
+}
+
+// GetTimeSeries handles the request to get the activity of an organization as a time series.
+//
+//	@Summary		Get Time Series
+//	@Description	Buckets the chats, messages per role, token counts and response times of the user's organization by hour, day, week or month. Buckets follow the calendar of the timezone and empty buckets are included, so the points can be charted directly. Data comes from rollups the worker refreshes every 5 minutes; response-time percentiles are estimated from histograms.
+//	@Tags			Analytics
+//	@Produce		json
+//	@Param			start		query		string				false	"Start date (RFC3339 format, e.g., 2023-01-01T00:00:00Z). Defaults to 30 days before end."
+//	@Param			end			query		string				false	"End date (RFC3339 format, e.g., 2023-01-31T23:59:59Z). Defaults to now."
+//	@Param			bucket		query		string				false	"Bucket width"										Enums(hour, day, week, month)	default(day)
+//	@Param			timezone	query		string				false	"IANA timezone the buckets are aligned to (e.g., Europe/Amsterdam)"	default(UTC)
+//	@Success		200			{object}	domain.TimeSeries	"Bucketed activity"
+//	@Failure		400			{object}	map[string]string	"Invalid date format, bucket or timezone"
+//	@Failure		401			{object}	map[string]string	"Unauthorized (JWT invalid/missing or Org ID not found)"
+//	@Failure		500			{object}	map[string]string	"Failed to get time series"
+//	@Security		BearerAuth
+//	@Router			/v1/analytics/timeseries [get]
+func (h *AnalyticsHandler) GetTimeSeries(c *gin.Context) {
+	// Get organization ID from context
+	orgID, exists := c.Get("orgID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Organization ID not found in context"})
+		return
+	}
+
+	params := newQueryParser(c)
+	query := &domain.TimeSeriesQuery{
+		Bucket:   domain.TimeBucket(c.Query("bucket")),
+		Timezone: c.Query("timezone"),
+	}
+	if start := params.timeValue("start"); start != nil {
+		query.Start = *start
+	}
+	if end := params.timeValue("end"); end != nil {
+		query.End = *end
+	}
+
+	if params.err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": params.err.Error()})
+		return
+	}
+
+	series, err := h.analyticsService.GetTimeSeries(orgID.(uint64), query)
+	if err != nil {
+		respondAnalyticsError(c, "Failed to get time series", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, series)
+}
+
+// GetQualityReport handles the request to get the conversation quality of an organization.
+//
+//	@Summary		Get Quality Report
+//	@Description	Summarizes the user ratings (average, CSAT and distribution), sentiment, escalation and HR-forward rates and top question categories of the chats created in a date range, in total and optionally broken down by country, language or tag.
+//	@Tags			Analytics
+//	@Produce		json
+//	@Param			start			query		string					false	"Start date (RFC3339 format, e.g., 2023-01-01T00:00:00Z). Defaults to 30 days before end."
+//	@Param			end				query		string					false	"End date (RFC3339 format, e.g., 2023-01-31T23:59:59Z). Defaults to now."
+//	@Param			group_by		query		string					false	"Break the report down by this chat attribute"	Enums(country, language, tag)
+//	@Param			country_code	query		string					false	"Only include chats from this ISO-3166 country"
+//	@Param			language_code	query		string					false	"Only include chats in this ISO-639 language"
+//	@Param			tags			query		[]string				false	"Only include chats carrying at least one of these tags"	collectionFormat(csv)
+//	@Param			top_categories	query		int						false	"Number of question categories reported (max 50)"		default(10)
+//	@Success		200				{object}	domain.QualityReport	"Quality report"
+//	@Failure		400				{object}	map[string]string		"Invalid date format or report parameters"
+//	@Failure		401				{object}	map[string]string		"Unauthorized (JWT invalid/missing or Org ID not found)"
+//	@Failure		500				{object}	map[string]string		"Failed to get quality report"
+//	@Security		BearerAuth
+//	@Router			/v1/analytics/quality [get]
+func (h *AnalyticsHandler) GetQualityReport(c *gin.Context) {
+	// Get organization ID from context
+	orgID, exists := c.Get("orgID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Organization ID not found in context"})
+		return
+	}
+
+	params := newQueryParser(c)
+	query := &domain.QualityQuery{
+		GroupBy:      domain.QualityDimension(c.Query("group_by")),
+		CountryCode:  c.Query("country_code"),
+		LanguageCode: c.Query("language_code"),
+		Tags:         params.list("tags"),
+	}
+	if start := params.timeValue("start"); start != nil {
+		query.Start = *start
+	}
+	if end := params.timeValue("end"); end != nil {
+		query.End = *end
+	}
+	if n := params.intValue("top_categories"); n != nil {
+		query.TopCategories = *n
+	}
+
+	if params.err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": params.err.Error()})
+		return
+	}
+
+	report, err := h.analyticsService.GetQualityReport(orgID.(uint64), query)
+	if err != nil {
+		respondAnalyticsError(c, "Failed to get quality report", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, report)
+}
+
+// GetLatencyReport handles the request to get the assistant response latency of an organization.
+//
+//	@Summary		Get Latency Report
+//	@Description	Computes the p50, p90 and p99 assistant response times of the user's organization by hour, day, week or month, in total and optionally per tag or API key. When the organization has configured a response time SLA (response_time_sla_ms in the organization settings), the responses slower than it are counted as breaches.
+//	@Tags			Analytics
+//	@Produce		json
+//	@Param			start		query		string					false	"Start date (RFC3339 format, e.g., 2023-01-01T00:00:00Z). Defaults to 30 days before end."
+//	@Param			end			query		string					false	"End date (RFC3339 format, e.g., 2023-01-31T23:59:59Z). Defaults to now."
+//	@Param			bucket		query		string					false	"Bucket width"										Enums(hour, day, week, month)	default(day)
+//	@Param			timezone	query		string					false	"IANA timezone the buckets are aligned to (e.g., Europe/Amsterdam)"	default(UTC)
+//	@Param			group_by	query		string					false	"Break the report down by tag or by the API key the chat was created with"	Enums(tag, api_key)
+//	@Param			tags		query		[]string				false	"Only include chats carrying at least one of these tags"	collectionFormat(csv)
+//	@Success		200			{object}	domain.LatencyReport	"Latency report"
+//	@Failure		400			{object}	map[string]string		"Invalid date format or report parameters"
+//	@Failure		401			{object}	map[string]string		"Unauthorized (JWT invalid/missing or Org ID not found)"
+//	@Failure		500			{object}	map[string]string		"Failed to get latency report"
+//	@Security		BearerAuth
+//	@Router			/v1/analytics/latency [get]
+func (h *AnalyticsHandler) GetLatencyReport(c *gin.Context) {
+	// Get organization ID from context
+	orgID, exists := c.Get("orgID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Organization ID not found in context"})
+		return
+	}
+
+	params := newQueryParser(c)
+	query := &domain.LatencyQuery{
+		TimeSeriesQuery: domain.TimeSeriesQuery{
+			Bucket:   domain.TimeBucket(c.Query("bucket")),
+			Timezone: c.Query("timezone"),
+		},
+		GroupBy: domain.LatencyDimension(c.Query("group_by")),
+		Tags:    params.list("tags"),
+	}
+	if start := params.timeValue("start"); start != nil {
+		query.Start = *start
+	}
+	if end := params.timeValue("end"); end != nil {
+		query.End = *end
+	}
+
+	if params.err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": params.err.Error()})
+		return
+	}
+
+	report, err := h.analyticsService.GetLatencyReport(orgID.(uint64), query)
+	if err != nil {
+		respondAnalyticsError(c, "Failed to get latency report", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, report)
+}
+
+// GetSlowestChats handles the request to list the chats with the slowest responses.
+//
+//	@Summary		Get Slowest Chats
+//	@Description	Lists the chats created in a date range with the slowest average assistant response time, with their maximum response time and, when the organization has configured a response time SLA, the number of responses breaching it.
+//	@Tags			Analytics
+//	@Produce		json
+//	@Param			start	query		string					false	"Start date (RFC3339 format, e.g., 2023-01-01T00:00:00Z). Defaults to 30 days before end."
+//	@Param			end		query		string					false	"End date (RFC3339 format, e.g., 2023-01-31T23:59:59Z). Defaults to now."
+//	@Param			tags	query		[]string				false	"Only include chats carrying at least one of these tags"	collectionFormat(csv)
+//	@Param			limit	query		int						false	"Number of chats listed (max 100)"						default(10)
+//	@Success		200		{object}	map[string]interface{}	"chats: []domain.SlowChat, slowest first"
+//	@Failure		400		{object}	map[string]string		"Invalid date format or limit"
+//	@Failure		401		{object}	map[string]string		"Unauthorized (JWT invalid/missing or Org ID not found)"
+//	@Failure		500		{object}	map[string]string		"Failed to get slowest chats"
+//	@Security		BearerAuth
+//	@Router			/v1/analytics/latency/slowest-chats [get]
+func (h *AnalyticsHandler) GetSlowestChats(c *gin.Context) {
+	// Get organization ID from context
+	orgID, exists := c.Get("orgID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Organization ID not found in context"})
+		return
+	}
+
+	params := newQueryParser(c)
+	query := &domain.SlowChatQuery{Tags: params.list("tags")}
+	if start := params.timeValue("start"); start != nil {
+		query.Start = *start
+	}
+	if end := params.timeValue("end"); end != nil {
+		query.End = *end
+	}
+	if limit := params.intValue("limit"); limit != nil {
+		query.Limit = *limit
+	}
+
+	if params.err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": params.err.Error()})
+		return
+	}
+
+	chats, err := h.analyticsService.GetSlowestChats(orgID.(uint64), query)
+	if err != nil {
+		respondAnalyticsError(c, "Failed to get slowest chats", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"chats": chats})
+}
+
+// GetUsageReport handles the request to get the token usage and cost of an organization.
+//
+//	@Summary		Get Usage Report
+//	@Description	Sums the prompt, completion, cached and total tokens of the messages of the user's organization sent in a date range, broken down by day, model, user or API key. The cost is estimated from the model prices in the organization settings (model_prices, per million tokens, in price_currency); tokens of models without a price are reported as unpriced.
+//	@Tags			Analytics
+//	@Produce		json
+//	@Param			start		query		string				false	"Start date (RFC3339 format, e.g., 2023-01-01T00:00:00Z). Defaults to 30 days before end."
+//	@Param			end			query		string				false	"End date (RFC3339 format, e.g., 2023-01-31T23:59:59Z). Defaults to now."
+//	@Param			group_by	query		string				false	"Break the report down by this attribute"	Enums(day, model, user, api_key)	default(day)
+//	@Param			timezone	query		string				false	"IANA timezone the days are aligned to (e.g., Europe/Amsterdam)"	default(UTC)
+//	@Success		200			{object}	domain.UsageReport	"Usage report"
+//	@Failure		400			{object}	map[string]string	"Invalid date format or report parameters"
+//	@Failure		401			{object}	map[string]string	"Unauthorized (JWT invalid/missing or Org ID not found)"
+//	@Failure		500			{object}	map[string]string	"Failed to get usage report"
+//	@Security		BearerAuth
+//	@Router			/v1/analytics/usage [get]
+func (h *AnalyticsHandler) GetUsageReport(c *gin.Context) {
+	// Get organization ID from context
+	orgID, exists := c.Get("orgID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Organization ID not found in context"})
+		return
+	}
+
+	params := newQueryParser(c)
+	query := &domain.UsageQuery{
+		GroupBy:  domain.UsageDimension(c.Query("group_by")),
+		Timezone: c.Query("timezone"),
+	}
+	if start := params.timeValue("start"); start != nil {
+		query.Start = *start
+	}
+	if end := params.timeValue("end"); end != nil {
+		query.End = *end
+	}
+
+	if params.err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": params.err.Error()})
+		return
+	}
+
+	report, err := h.analyticsService.GetUsageReport(orgID.(uint64), query)
+	if err != nil {
+		respondAnalyticsError(c, "Failed to get usage report", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, report)
+}
+
+// respondAnalyticsError writes the error response of a failed analytics query.
+func respondAnalyticsError(c *gin.Context, message string, err error) {
+	if errors.Is(err, domain.ErrInvalidAnalyticsQuery) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusInternalServerError, gin.H{"error": message})
+}
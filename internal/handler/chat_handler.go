@@ -1,6 +1,7 @@
 package handler
 
 import (
+	"errors"
 	"net/http"
 	"strconv"
 	"time"
@@ -87,6 +88,12 @@ func (h *ChatHandler) CreateChat(c *gin.Context) {
 		UpdatedAt:      time.Now(),
 	}
 
+	// Record the API key the chat is created with, for per-key analytics
+	if apiKeyID, exists := c.Get("apiKeyID"); exists {
+		keyID := apiKeyID.(uint64)
+		chat.APIKeyID = &keyID
+	}
+
 	// Set tags using the helper method
 	if err := chat.SetTags(req.Tags); err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to process tags: " + err.Error()})
@@ -211,14 +218,34 @@ func (h *ChatHandler) GetChat(c *gin.Context) {
 // ListChats handles the request to list chats for the current organization.
 //
 //	@Summary		List Chats
-//	@Description	Retrieves a paginated list of chat sessions for the user's organization.
+//	@Description	Retrieves a filtered, sorted and cursor-paginated list of chat sessions for the user's organization. Filters on chat metadata match its JSON fields. Cursors are only valid for the sort they were issued for.
 //	@Tags			Chats
 //	@Produce		json
-//	@Param			limit	query		int					false	"Number of chats per page"	default(20)
-//	@Param			offset	query		int					false	"Offset for pagination"		default(0)
-//	@Success		200		{array}		domain.Chat			"List of chats"
-//	@Failure		401		{object}	map[string]string	"Unauthorized (JWT invalid/missing or Org ID not found)"
-//	@Failure		500		{object}	map[string]string	"Failed to list chats"
+//	@Param			limit				query		int					false	"Number of chats per page (max 100)"	default(20)
+//	@Param			cursor				query		string				false	"Cursor of the page to fetch, from next_cursor or prev_cursor"
+//	@Param			include_total		query		bool				false	"Include the total number of matching chats"	default(false)
+//	@Param			sort				query		string				false	"Sort key"					Enums(created_at, updated_at, title, user_rating, token_count, message_count)	default(created_at)
+//	@Param			order				query		string				false	"Sort direction"			Enums(asc, desc)	default(desc)
+//	@Param			tags				query		[]string			false	"Only chats carrying these tags"	collectionFormat(csv)
+//	@Param			tag_match			query		string				false	"Whether chats must carry any or all of the tags"	Enums(any, all)	default(any)
+//	@Param			exclude_tags		query		[]string			false	"Only chats carrying none of these tags"	collectionFormat(csv)
+//	@Param			user_id				query		uint64				false	"Only chats of this user"
+//	@Param			created_from		query		string				false	"Only chats created at or after this time (RFC3339)"
+//	@Param			created_to			query		string				false	"Only chats created at or before this time (RFC3339)"
+//	@Param			updated_from		query		string				false	"Only chats updated at or after this time (RFC3339)"
+//	@Param			updated_to			query		string				false	"Only chats updated at or before this time (RFC3339)"
+//	@Param			title				query		string				false	"Only chats whose title contains this text (case-insensitive)"
+//	@Param			sentiment			query		string				false	"Only chats with this sentiment"
+//	@Param			is_escalated		query		bool				false	"Only escalated or non-escalated chats"
+//	@Param			country_code		query		string				false	"Only chats from this ISO-3166 country code"
+//	@Param			language_code		query		string				false	"Only chats in this ISO-639 language code"
+//	@Param			question_category	query		string				false	"Only chats in this question category"
+//	@Param			min_rating			query		int					false	"Only chats rated at least this"
+//	@Param			max_rating			query		int					false	"Only chats rated at most this"
+//	@Success		200					{object}	PageResponse[domain.Chat]	"Page of chats"
+//	@Failure		400					{object}	map[string]string	"Invalid filter, sort or cursor"
+//	@Failure		401					{object}	map[string]string	"Unauthorized (JWT invalid/missing or Org ID not found)"
+//	@Failure		500					{object}	map[string]string	"Failed to list chats"
 //	@Security		BearerAuth
 //	@Router			/v1/chats [get]
 func (h *ChatHandler) ListChats(c *gin.Context) {
@@ -229,18 +256,69 @@ func (h *ChatHandler) ListChats(c *gin.Context) {
 		return
 	}
 
-	// Parse pagination parameters
-	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
-	offset, _ := strconv.Atoi(c.DefaultQuery("offset", "0"))
+	// Parse pagination, filter and sort parameters
+	params := newQueryParser(c)
+	page := params.pageRequest()
+	filter := &domain.ChatFilter{
+		CreatedFrom:      params.timeValue("created_from"),
+		CreatedTo:        params.timeValue("created_to"),
+		UpdatedFrom:      params.timeValue("updated_from"),
+		UpdatedTo:        params.timeValue("updated_to"),
+		Tags:             params.list("tags"),
+		TagMatch:         domain.TagMatch(c.Query("tag_match")),
+		ExcludeTags:      params.list("exclude_tags"),
+		UserID:           params.uintValue("user_id"),
+		TitleContains:    c.Query("title"),
+		IsEscalated:      params.boolValue("is_escalated"),
+		CountryCode:      c.Query("country_code"),
+		LanguageCode:     c.Query("language_code"),
+		Sentiment:        c.Query("sentiment"),
+		QuestionCategory: c.Query("question_category"),
+		MinRating:        params.intValue("min_rating"),
+		MaxRating:        params.intValue("max_rating"),
+	}
+	if params.err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request: " + params.err.Error()})
+		return
+	}
+
+	if err := filter.Validate(); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid filter: " + err.Error()})
+		return
+	}
+
+	sort := domain.DefaultChatSort
+	if field := c.Query("sort"); field != "" {
+		sort.Field = domain.ChatSortField(field)
+	}
+	switch c.Query("order") {
+	case "":
+	case "asc":
+		sort.Descending = false
+	case "desc":
+		sort.Descending = true
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid sort: order must be 'asc' or 'desc'"})
+		return
+	}
+
+	if err := sort.Validate(); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid sort: " + err.Error()})
+		return
+	}
 
 	// Get chats
-	chats, err := h.chatService.GetByOrganizationID(orgID.(uint64), limit, offset)
+	chats, err := h.chatService.GetByOrganizationID(orgID.(uint64), filter, &sort, page)
 	if err != nil {
+		if errors.Is(err, domain.ErrInvalidCursor) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid cursor for this sort"})
+			return
+		}
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to list chats"})
 		return
 	}
 
-	c.JSON(http.StatusOK, chats)
+	c.JSON(http.StatusOK, newPageResponse(chats, page.Limit))
 }
 
 // UpdateChatRequest represents the request to update a chat.
@@ -421,3 +499,57 @@ func (h *ChatHandler) DeleteChat(c *gin.Context) {
 
 	c.JSON(http.StatusOK, gin.H{"message": "Chat deleted successfully"})
 }
+
+// GetChatStats handles the request to get chat statistics for an organization.
+//
+//	@Summary		Get Chat Statistics
+//	@Description	Retrieves the number of chats created within a specified date range for the user's organization, and how many of them carry each tag.
+//	@Tags			Analytics
+//	@Produce		json
+//	@Param			start	query		string					false	"Start date (RFC3339 format, e.g., 2023-01-01T00:00:00Z). Defaults to 30 days ago."
+//	@Param			end		query		string					false	"End date (RFC3339 format, e.g., 2023-01-31T23:59:59Z). Defaults to now."
+//	@Success		200		{object}	map[string]interface{}	"total_chats, tag_stats (chats per tag) and date_range"
+//	@Failure		400		{object}	map[string]string		"Invalid date format"
+//	@Failure		401		{object}	map[string]string		"Unauthorized (JWT invalid/missing or Org ID not found)"
+//	@Failure		500		{object}	map[string]string		"Failed to get chat statistics"
+//	@Security		BearerAuth
+//	@Router			/v1/analytics/chats [get]
+func (h *ChatHandler) GetChatStats(c *gin.Context) {
+	// Get organization ID from context
+	orgID, exists := c.Get("orgID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Organization ID not found in context"})
+		return
+	}
+
+	// Parse date range parameters, defaulting to the last 30 days
+	end := time.Now()
+	start := end.AddDate(0, 0, -30)
+
+	if startStr := c.Query("start"); startStr != "" {
+		parsed, err := time.Parse(time.RFC3339, startStr)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid start date format"})
+			return
+		}
+		start = parsed
+	}
+
+	if endStr := c.Query("end"); endStr != "" {
+		parsed, err := time.Parse(time.RFC3339, endStr)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid end date format"})
+			return
+		}
+		end = parsed
+	}
+
+	// Get chat statistics
+	stats, err := h.chatService.GetChatStats(orgID.(uint64), start, end)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get chat statistics"})
+		return
+	}
+
+	c.JSON(http.StatusOK, stats)
+}
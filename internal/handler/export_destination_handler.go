@@ -0,0 +1,263 @@
+// Package handler provides HTTP request handlers for the ChatLogger API.
+// This file implements handlers for managing the destinations completed
+// exports are delivered to.
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"github.com/kjanat/chatlogger-api-go/internal/domain"
+	"github.com/kjanat/chatlogger-api-go/internal/middleware"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ExportDestinationHandler handles export destination requests.
+type ExportDestinationHandler struct {
+	destinationService domain.ExportDestinationService
+}
+
+// NewExportDestinationHandler creates a new export destination handler.
+func NewExportDestinationHandler(destinationService domain.ExportDestinationService) *ExportDestinationHandler {
+	return &ExportDestinationHandler{
+		destinationService: destinationService,
+	}
+}
+
+// CreateExportDestinationRequest represents the request to create an export destination.
+type CreateExportDestinationRequest struct {
+	Name    string                          `binding:"required,max=100" json:"name"`
+	Type    string                          `binding:"required,oneof=webhook sftp" json:"type"`
+	Config  *domain.ExportDestinationConfig `binding:"required" json:"config"`
+	Enabled *bool                           `json:"enabled,omitempty"` // Defaults to true
+}
+
+// UpdateExportDestinationRequest represents the request to update an export destination.
+// Fields missing from the request keep their current value; the config is
+// merged into the current config, so secrets only need to be sent to change them.
+type UpdateExportDestinationRequest struct {
+	Name    *string         `binding:"omitempty,max=100" json:"name,omitempty"`
+	Config  json.RawMessage `json:"config,omitempty" swaggertype:"object"`
+	Enabled *bool           `json:"enabled,omitempty"`
+}
+
+// CreateDestination handles the request to create an export destination
+//
+//	@Summary		Create Export Destination
+//	@Description	Creates a destination that completed exports can be delivered to: an HTTPS webhook receiving the file or a signed download link, or an SFTP server. Secrets are never returned.
+//	@Tags			Export Destinations (Admin)
+//	@Accept			json
+//	@Produce		json
+//	@Param			request	body		CreateExportDestinationRequest	true	"Destination parameters"
+//	@Success		201		{object}	domain.ExportDestination		"Created export destination"
+//	@Failure		400		{object}	map[string]string				"Invalid request data or destination config"
+//	@Failure		401		{object}	map[string]string				"Unauthorized (JWT invalid/missing or Org ID not found)"
+//	@Security		BearerAuth
+//	@Router			/v1/orgs/me/destinations [post]
+func (h *ExportDestinationHandler) CreateDestination(c *gin.Context) {
+	var req CreateExportDestinationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request data: " + err.Error()})
+		return
+	}
+
+	// Get organization ID from context
+	orgID, exists := c.Get(middleware.OrganizationIDKey)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Organization not found"})
+		return
+	}
+
+	destination := &domain.ExportDestination{
+		OrganizationID: orgID.(uint64),
+		Name:           req.Name,
+		Type:           domain.ExportDestinationType(req.Type),
+		Enabled:        req.Enabled == nil || *req.Enabled,
+	}
+
+	if err := destination.SetConfig(req.Config); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to process config: " + err.Error()})
+		return
+	}
+
+	if err := h.destinationService.CreateDestination(destination); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Failed to create export destination: " + err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, destination)
+}
+
+// ListDestinations handles the request to list the export destinations of an organization
+//
+//	@Summary		List Export Destinations
+//	@Description	Retrieves all export destinations of the user's organization.
+//	@Tags			Export Destinations (Admin)
+//	@Produce		json
+//	@Success		200	{array}		domain.ExportDestination	"List of export destinations"
+//	@Failure		401	{object}	map[string]string			"Unauthorized (JWT invalid/missing or Org ID not found)"
+//	@Failure		500	{object}	map[string]string			"Failed to fetch export destinations"
+//	@Security		BearerAuth
+//	@Router			/v1/orgs/me/destinations [get]
+func (h *ExportDestinationHandler) ListDestinations(c *gin.Context) {
+	// Get organization ID from context
+	orgID, exists := c.Get(middleware.OrganizationIDKey)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Organization not found"})
+		return
+	}
+
+	destinations, err := h.destinationService.ListDestinations(orgID.(uint64))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch export destinations"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"destinations": destinations})
+}
+
+// GetDestination handles the request to get an export destination
+//
+//	@Summary		Get Export Destination
+//	@Description	Retrieves a single export destination of the user's organization, without its secrets.
+//	@Tags			Export Destinations (Admin)
+//	@Produce		json
+//	@Param			id	path		uint64						true	"Export Destination ID"
+//	@Success		200	{object}	domain.ExportDestination	"Export destination details"
+//	@Failure		400	{object}	map[string]string			"Invalid export destination ID"
+//	@Failure		401	{object}	map[string]string			"Unauthorized (JWT invalid/missing or Org ID not found)"
+//	@Failure		404	{object}	map[string]string			"Export destination not found"
+//	@Security		BearerAuth
+//	@Router			/v1/orgs/me/destinations/{id} [get]
+func (h *ExportDestinationHandler) GetDestination(c *gin.Context) {
+	destination, ok := h.loadDestination(c)
+	if !ok {
+		return
+	}
+
+	c.JSON(http.StatusOK, destination)
+}
+
+// UpdateDestination handles the request to update an export destination
+//
+//	@Summary		Update Export Destination
+//	@Description	Updates an export destination of the user's organization. Fields missing from the request keep their current value, including secrets in the config.
+//	@Tags			Export Destinations (Admin)
+//	@Accept			json
+//	@Produce		json
+//	@Param			id		path		uint64							true	"Export Destination ID"
+//	@Param			request	body		UpdateExportDestinationRequest	true	"Fields to update"
+//	@Success		200		{object}	domain.ExportDestination		"Updated export destination"
+//	@Failure		400		{object}	map[string]string				"Invalid export destination ID, request data or destination config"
+//	@Failure		401		{object}	map[string]string				"Unauthorized (JWT invalid/missing or Org ID not found)"
+//	@Failure		404		{object}	map[string]string				"Export destination not found"
+//	@Security		BearerAuth
+//	@Router			/v1/orgs/me/destinations/{id} [patch]
+func (h *ExportDestinationHandler) UpdateDestination(c *gin.Context) {
+	var req UpdateExportDestinationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request data: " + err.Error()})
+		return
+	}
+
+	destination, ok := h.loadDestination(c)
+	if !ok {
+		return
+	}
+
+	// Update destination fields if provided
+	if req.Name != nil {
+		destination.Name = *req.Name
+	}
+	if req.Enabled != nil {
+		destination.Enabled = *req.Enabled
+	}
+
+	if len(req.Config) > 0 {
+		config, err := destination.GetConfig()
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to process config: " + err.Error()})
+			return
+		}
+
+		// Decode the request on top of the current config
+		if err := json.Unmarshal(req.Config, config); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid config: " + err.Error()})
+			return
+		}
+
+		if err := destination.SetConfig(config); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to process config: " + err.Error()})
+			return
+		}
+	}
+
+	if err := h.destinationService.UpdateDestination(destination); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Failed to update export destination: " + err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, destination)
+}
+
+// DeleteDestination handles the request to delete an export destination
+//
+//	@Summary		Delete Export Destination
+//	@Description	Deletes an export destination of the user's organization. Pending deliveries to it fail.
+//	@Tags			Export Destinations (Admin)
+//	@Produce		json
+//	@Param			id	path		uint64	true	"Export Destination ID"
+//	@Success		204	"Export destination deleted"
+//	@Failure		400	{object}	map[string]string	"Invalid export destination ID"
+//	@Failure		401	{object}	map[string]string	"Unauthorized (JWT invalid/missing or Org ID not found)"
+//	@Failure		404	{object}	map[string]string	"Export destination not found"
+//	@Security		BearerAuth
+//	@Router			/v1/orgs/me/destinations/{id} [delete]
+func (h *ExportDestinationHandler) DeleteDestination(c *gin.Context) {
+	destinationID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid export destination ID"})
+		return
+	}
+
+	// Get organization ID from context
+	orgID, exists := c.Get(middleware.OrganizationIDKey)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Organization not found"})
+		return
+	}
+
+	if err := h.destinationService.DeleteDestination(destinationID, orgID.(uint64)); err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Export destination not found"})
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// loadDestination gets the export destination of the request's ID parameter,
+// writing an error response when it is not available.
+func (h *ExportDestinationHandler) loadDestination(c *gin.Context) (*domain.ExportDestination, bool) {
+	destinationID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid export destination ID"})
+		return nil, false
+	}
+
+	// Get organization ID from context
+	orgID, exists := c.Get(middleware.OrganizationIDKey)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Organization not found"})
+		return nil, false
+	}
+
+	destination, err := h.destinationService.GetDestination(destinationID, orgID.(uint64))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Export destination not found"})
+		return nil, false
+	}
+
+	return destination, true
+}
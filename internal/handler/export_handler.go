@@ -1,18 +1,20 @@
 // Package handler provides HTTP request handlers for the ChatLogger API.
 // This file implements handlers for chat data export functionality, supporting both
-// synchronous and asynchronous export operations in different formats (JSON, CSV).
+// synchronous and asynchronous export operations in different formats (JSON, CSV, NDJSON, fine-tuning JSONL, Markdown, HTML, XLSX, Parquet).
 package handler
 
 import (
+	"errors"
 	"fmt"
+	"log"
 	"net/http"
-	"os"
 	"path/filepath"
 	"strconv"
 	"time"
 
 	"github.com/kjanat/chatlogger-api-go/internal/domain"
 	"github.com/kjanat/chatlogger-api-go/internal/middleware"
+	"github.com/kjanat/chatlogger-api-go/internal/storage"
 	"github.com/kjanat/chatlogger-api-go/internal/strategy"
 
 	"github.com/gin-gonic/gin"
@@ -23,40 +25,96 @@ type ExportHandler struct {
 	exportService  domain.ExportService
 	chatService    domain.ChatService
 	messageService domain.MessageService
-	exportDir      string
+	store          storage.Storage
+	presignTTL     time.Duration
 }
 
 // NewExportHandler creates a new export handler.
+// Downloads are redirected to presigned URLs valid for presignTTL when the
+// storage backend supports them; a zero presignTTL streams every download
+// through the server.
 func NewExportHandler(
 	exportService domain.ExportService,
 	chatService domain.ChatService,
 	messageService domain.MessageService,
-	exportDir string,
+	store storage.Storage,
+	presignTTL time.Duration,
 ) *ExportHandler {
 	return &ExportHandler{
 		exportService:  exportService,
 		chatService:    chatService,
 		messageService: messageService,
-		exportDir:      exportDir,
+		store:          store,
+		presignTTL:     presignTTL,
 	}
 }
 
 // ExportRequest represents the request to export data.
 type ExportRequest struct {
-	Format string `binding:"required,oneof=json csv" json:"format"`
-	Type   string `binding:"required,oneof=chats messages all" json:"type"`
+	Format      string                `binding:"required,oneof=json csv ndjson ndjson_messages finetune markdown html xlsx parquet" json:"format"`
+	Type        string                `binding:"required,oneof=chats messages all" json:"type"`
+	Compression string                `binding:"omitempty,oneof=none gzip zip" json:"compression,omitempty"` // Optional, defaults to none
+	Filters     *domain.ExportFilter  `json:"filters,omitempty"`                                             // Optional, restricts the exported chats and messages
+	Options     *domain.ExportOptions `json:"options,omitempty"`                                             // Optional, format-specific settings
+}
+
+// compression returns the requested export compression, defaulting to none.
+func (r *ExportRequest) compression() domain.ExportCompression {
+	if r.Compression == "" {
+		return domain.ExportCompressionNone
+	}
+	return domain.ExportCompression(r.Compression)
+}
+
+// validateExportSettings validates the filters and options of an export request
+// of the given type.
+func validateExportSettings(
+	c *gin.Context,
+	exportType domain.ExportType,
+	filters *domain.ExportFilter,
+	options *domain.ExportOptions,
+) bool {
+	if filters != nil {
+		if err := filters.Validate(); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid filters: " + err.Error()})
+			return false
+		}
+	}
+
+	if options != nil {
+		if err := options.Validate(); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid options: " + err.Error()})
+			return false
+		}
+
+		if options.CSV != nil {
+			if err := strategy.ValidateCSVColumns(options.CSV.Columns, exportType); err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid options: " + err.Error()})
+				return false
+			}
+		}
+
+		if options.Encryption != nil {
+			if _, err := strategy.ParseRecipients(options.Encryption.Recipients); err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid options: " + err.Error()})
+				return false
+			}
+		}
+	}
+
+	return true
 }
 
 // CreateExport handles the request to create an asynchronous export
 //
 //	@Summary		Create Asynchronous Export Job
-//	@Description	Initiates an asynchronous job to export chat data (chats, messages, or all) in JSON or CSV format for the user's organization.
+//	@Description	Initiates an asynchronous job to export chat data (chats, messages, or all) in JSON, CSV, NDJSON, fine-tuning JSONL, Markdown, HTML, XLSX or Parquet format for the user's organization, optionally restricted by filters, compressed with gzip or bundled as a zip archive with a manifest, encrypted with age to the requested recipients or the organization's default recipients, and delivered to the export destinations named in the options once completed.
 //	@Tags			Exports
 //	@Accept			json
 //	@Produce		json
-//	@Param			request	body		ExportRequest			true	"Export parameters (format, type)"
+//	@Param			request	body		ExportRequest			true	"Export parameters (format, type, compression, filters, options)"
 //	@Success		202		{object}	map[string]interface{}	"export_id: uint64, status: domain.ExportStatus, message: string"
-//	@Failure		400		{object}	map[string]string		"Invalid request data (format/type)"
+//	@Failure		400		{object}	map[string]string		"Invalid request data (format/type/filters/options/destinations)"
 //	@Failure		401		{object}	map[string]string		"Unauthorized (JWT invalid/missing, Org or User ID not found)"
 //	@Failure		500		{object}	map[string]string		"Failed to create export job"
 //	@Security		BearerAuth
@@ -68,6 +126,10 @@ func (h *ExportHandler) CreateExport(c *gin.Context) {
 		return
 	}
 
+	if !validateExportSettings(c, domain.ExportType(req.Type), req.Filters, req.Options) {
+		return
+	}
+
 	// Get organization ID from context
 	orgID, exists := c.Get(middleware.OrganizationIDKey)
 	if !exists {
@@ -89,6 +151,20 @@ func (h *ExportHandler) CreateExport(c *gin.Context) {
 		format = domain.ExportFormatJSON
 	case "csv":
 		format = domain.ExportFormatCSV
+	case "ndjson":
+		format = domain.ExportFormatNDJSON
+	case "ndjson_messages":
+		format = domain.ExportFormatNDJSONMessages
+	case "finetune":
+		format = domain.ExportFormatFineTune
+	case "markdown":
+		format = domain.ExportFormatMarkdown
+	case "html":
+		format = domain.ExportFormatHTML
+	case "xlsx":
+		format = domain.ExportFormatXLSX
+	case "parquet":
+		format = domain.ExportFormatParquet
 	default:
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Unsupported export format"})
 		return
@@ -108,14 +184,29 @@ func (h *ExportHandler) CreateExport(c *gin.Context) {
 	}
 
 	// Create the export job
-	export, err := h.exportService.CreateExport(
-		orgID.(uint64),
-		userID.(uint64),
-		format,
-		exportType,
-	)
+	export := &domain.Export{
+		OrganizationID: orgID.(uint64),
+		UserID:         userID.(uint64),
+		Format:         format,
+		Type:           exportType,
+		Compression:    req.compression(),
+	}
 
-	if err != nil {
+	if err := export.SetFilters(req.Filters); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to process filters: " + err.Error()})
+		return
+	}
+
+	if err := export.SetOptions(req.Options); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to process options: " + err.Error()})
+		return
+	}
+
+	if err := h.exportService.CreateExport(export); err != nil {
+		if errors.Is(err, domain.ErrExportDestinationNotFound) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid options: " + err.Error()})
+			return
+		}
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create export: " + err.Error()})
 		return
 	}
@@ -130,7 +221,7 @@ func (h *ExportHandler) CreateExport(c *gin.Context) {
 // GetExport handles the request to get export status
 //
 //	@Summary		Get Export Job Status
-//	@Description	Retrieves the status and details of a specific asynchronous export job.
+//	@Description	Retrieves the status and details of a specific asynchronous export job, including the number of processed and total chats while it runs and the status of its deliveries to export destinations.
 //	@Tags			Exports
 //	@Produce		json
 //	@Param			id	path		uint64				true	"Export Job ID"
@@ -163,18 +254,71 @@ func (h *ExportHandler) GetExport(c *gin.Context) {
 	c.JSON(http.StatusOK, export)
 }
 
+// CancelExport handles the request to cancel an export
+//
+//	@Summary		Cancel Export Job
+//	@Description	Cancels a pending or processing asynchronous export job. Queued jobs are removed from the queue; running jobs are stopped at the next chat.
+//	@Tags			Exports
+//	@Produce		json
+//	@Param			id	path		uint64				true	"Export Job ID"
+//	@Success		200	{object}	domain.Export		"Cancelled export job"
+//	@Failure		400	{object}	map[string]string	"Invalid export ID"
+//	@Failure		401	{object}	map[string]string	"Unauthorized (JWT invalid/missing or Org ID not found)"
+//	@Failure		404	{object}	map[string]string	"Export not found or doesn't belong to user's org"
+//	@Failure		409	{object}	map[string]string	"Export already completed, failed, expired or cancelled"
+//	@Failure		500	{object}	map[string]string	"Failed to cancel export"
+//	@Security		BearerAuth
+//	@Router			/v1/exports/{id}/cancel [post]
+func (h *ExportHandler) CancelExport(c *gin.Context) {
+	exportID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid export ID"})
+		return
+	}
+
+	// Get organization ID from context
+	orgID, exists := c.Get(middleware.OrganizationIDKey)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Organization not found"})
+		return
+	}
+
+	// Make sure the export exists before trying to cancel it
+	if _, err := h.exportService.GetExport(exportID, orgID.(uint64)); err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Export not found"})
+		return
+	}
+
+	export, err := h.exportService.CancelExport(exportID, orgID.(uint64))
+	if errors.Is(err, domain.ErrExportNotCancellable) {
+		c.JSON(http.StatusConflict, gin.H{
+			"error":  "Export can no longer be cancelled",
+			"status": export.Status,
+		})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to cancel export: " + err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, export)
+}
+
 // DownloadExport handles the request to download a completed export
 //
 //	@Summary		Download Export File
-//	@Description	Downloads the file generated by a completed asynchronous export job.
+//	@Description	Downloads the file generated by a completed asynchronous export job, either directly or by redirecting to a time-limited presigned URL of the storage backend.
 //	@Tags			Exports
 //	@Produce		octet-stream
 //	@Param			id	path		uint64				true	"Export Job ID"
-//	@Success		200	{file}		file				"Export file (JSON or CSV)"
+//	@Success		200	{file}		file				"Export file in the requested format"
+//	@Success		302	{string}	string				"Redirect to a presigned download URL"
 //	@Failure		400	{object}	map[string]string	"Invalid export ID or export not ready"
 //	@Failure		401	{object}	map[string]string	"Unauthorized (JWT invalid/missing or Org ID not found)"
 //	@Failure		404	{object}	map[string]string	"Export not found or doesn't belong to user's org"
-//	@Failure		500	{object}	map[string]string	"Export file path not found or file missing on disk"
+//	@Failure		410	{object}	map[string]string	"Export has expired and its file was deleted"
+//	@Failure		500	{object}	map[string]string	"Export file path not found or file missing in storage"
 //	@Security		BearerAuth
 //	@Router			/v1/exports/{id}/download [get]
 func (h *ExportHandler) DownloadExport(c *gin.Context) {
@@ -197,6 +341,15 @@ func (h *ExportHandler) DownloadExport(c *gin.Context) {
 		return
 	}
 
+	// Expired exports are gone for good, even before the cleanup job has run
+	if export.IsExpired(time.Now()) {
+		c.JSON(http.StatusGone, gin.H{
+			"error":      "Export has expired",
+			"expires_at": export.ExpiresAt,
+		})
+		return
+	}
+
 	// Check if export is completed
 	if export.Status != domain.ExportStatusCompleted {
 		c.JSON(http.StatusBadRequest, gin.H{
@@ -206,62 +359,70 @@ func (h *ExportHandler) DownloadExport(c *gin.Context) {
 		return
 	}
 
-	// Check if file exists
+	// Check if the export has a stored file
 	if export.FilePath == "" {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Export file path not found"})
 		return
 	}
 
-	// Check if file exists on disk
-	if _, err := os.Stat(export.FilePath); os.IsNotExist(err) {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Export file not found on disk"})
-		return
-	}
+	// Set appropriate content type, taking compression and encryption into account
+	contentType := strategy.ContentType(export)
 
-	// Set appropriate content type
-	contentType := "application/json"
-	if export.Format == domain.ExportFormatCSV {
-		contentType = "text/csv"
+	filename := filepath.Base(export.FilePath)
+
+	// Let the client fetch the file from the storage backend directly when possible
+	if h.presignTTL > 0 {
+		url, err := h.store.PresignGet(c.Request.Context(), export.FilePath, filename, h.presignTTL)
+		if err == nil {
+			c.Redirect(http.StatusFound, url)
+			return
+		}
+		if !errors.Is(err, storage.ErrPresignNotSupported) {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create download URL"})
+			return
+		}
 	}
 
-	filename := filepath.Base(export.FilePath)
+	object, err := h.store.Open(c.Request.Context(), export.FilePath)
+	if errors.Is(err, storage.ErrNotFound) {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Export file not found in storage"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to open export file"})
+		return
+	}
+	defer object.Close()
 
 	// Set headers for file download
-	c.Header("Content-Description", "File Transfer")
-	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%s", filename))
-	c.Header("Content-Type", contentType)
-
-	c.File(export.FilePath)
+	c.DataFromReader(http.StatusOK, object.Size, contentType, object, map[string]string{
+		"Content-Description": "File Transfer",
+		"Content-Disposition": fmt.Sprintf("attachment; filename=%q", filename),
+	})
 }
 
 // ListExports handles the request to list exports for an organization
 //
 //	@Summary		List Export Jobs
-//	@Description	Retrieves a paginated list of asynchronous export jobs for the user's organization.
+//	@Description	Retrieves a cursor-paginated list of asynchronous export jobs for the user's organization, newest first.
 //	@Tags			Exports
 //	@Produce		json
-//	@Param			limit	query		int					false	"Number of exports per page"	default(10)
-//	@Param			offset	query		int					false	"Offset for pagination"			default(0)
-//	@Success		200		{array}		domain.Export		"List of export jobs"
-//	@Failure		401		{object}	map[string]string	"Unauthorized (JWT invalid/missing or Org ID not found)"
-//	@Failure		500		{object}	map[string]string	"Failed to fetch exports"
+//	@Param			limit			query		int								false	"Number of exports per page (max 100)"	default(20)
+//	@Param			cursor			query		string							false	"Cursor of the page to fetch, from next_cursor or prev_cursor"
+//	@Param			include_total	query		bool							false	"Include the total number of exports"	default(false)
+//	@Success		200				{object}	PageResponse[domain.Export]	"Page of export jobs"
+//	@Failure		400				{object}	map[string]string				"Invalid pagination parameters"
+//	@Failure		401				{object}	map[string]string				"Unauthorized (JWT invalid/missing or Org ID not found)"
+//	@Failure		500				{object}	map[string]string				"Failed to fetch exports"
 //	@Security		BearerAuth
 //	@Router			/v1/exports [get]
 func (h *ExportHandler) ListExports(c *gin.Context) {
 	// Get pagination parameters
-	limit := 10
-	offset := 0
-
-	if limitParam := c.Query("limit"); limitParam != "" {
-		if val, err := strconv.Atoi(limitParam); err == nil && val > 0 {
-			limit = val
-		}
-	}
-
-	if offsetParam := c.Query("offset"); offsetParam != "" {
-		if val, err := strconv.Atoi(offsetParam); err == nil && val >= 0 {
-			offset = val
-		}
+	params := newQueryParser(c)
+	page := params.pageRequest()
+	if params.err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request: " + params.err.Error()})
+		return
 	}
 
 	// Get organization ID from context
@@ -271,25 +432,29 @@ func (h *ExportHandler) ListExports(c *gin.Context) {
 		return
 	}
 
-	exports, err := h.exportService.ListExports(orgID.(uint64), limit, offset)
+	exports, err := h.exportService.ListExports(orgID.(uint64), page)
 	if err != nil {
+		if errors.Is(err, domain.ErrInvalidCursor) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid cursor"})
+			return
+		}
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch exports"})
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"exports": exports})
+	c.JSON(http.StatusOK, newPageResponse(exports, page.Limit))
 }
 
 // SyncExport is the original synchronous export method, kept for backward compatibility
 //
 //	@Summary		Create Synchronous Export (Legacy)
-//	@Description	Immediately generates and returns an export file (JSON or CSV) containing chat data. Use async export for large datasets.
+//	@Description	Immediately generates and returns an export file (JSON, CSV, NDJSON, fine-tuning JSONL, Markdown, HTML, XLSX or Parquet) containing chat data. Use async export for large datasets.
 //	@Tags			Exports (Legacy)
 //	@Accept			json
 //	@Produce		octet-stream
-//	@Param			request	body		ExportRequest		true	"Export parameters (format, type)"
-//	@Success		200		{file}		file				"Export file (JSON or CSV)"
-//	@Failure		400		{object}	map[string]string	"Invalid request data (format/type)"
+//	@Param			request	body		ExportRequest		true	"Export parameters (format, type, compression, filters, options)"
+//	@Success		200		{file}		file				"Export file in the requested format"
+//	@Failure		400		{object}	map[string]string	"Invalid request data (format/type/filters)"
 //	@Failure		401		{object}	map[string]string	"Unauthorized (JWT invalid/missing or Org ID not found)"
 //	@Failure		500		{object}	map[string]string	"Failed to retrieve data or generate export"
 //	@Security		BearerAuth
@@ -301,6 +466,10 @@ func (h *ExportHandler) SyncExport(c *gin.Context) {
 		return
 	}
 
+	if !validateExportSettings(c, domain.ExportType(req.Type), req.Filters, req.Options) {
+		return
+	}
+
 	// Get organization ID from context
 	orgID, exists := c.Get(middleware.OrganizationIDKey)
 	if !exists {
@@ -308,67 +477,73 @@ func (h *ExportHandler) SyncExport(c *gin.Context) {
 		return
 	}
 
-	// Get chats for the organization
-	// Using a reasonable limit for direct export
-	chats, err := h.chatService.GetByOrganizationID(orgID.(uint64), 1000, 0)
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve chats"})
+	// Synchronous exports go to the client only
+	if req.Options != nil && len(req.Options.DestinationIDs) > 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Destinations are only supported for asynchronous exports"})
 		return
 	}
 
-	// For each chat, get its messages if needed
-	if req.Type == "all" || req.Type == "messages" {
-		for i := range chats {
-			messages, err := h.messageService.GetByChatID(chats[i].ID)
-			if err != nil {
-				c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve messages"})
-				return
-			}
-			chats[i].Messages = messages
-		}
-	}
-
-	// Prepare data for export
-	data := gin.H{
-		"organization_id": orgID,
-		"export_date":     time.Now().Format(time.RFC3339),
-		"export_type":     req.Type,
-		"chats":           chats,
+	// Apply organization defaults such as mandatory encryption
+	options, err := h.exportService.ResolveOptions(orgID.(uint64), req.Options)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to resolve export options: " + err.Error()})
+		return
 	}
 
 	// Select the appropriate exporter based on format
-	var exporter strategy.Exporter
-	switch req.Format {
-	case "json":
-		exporter = &strategy.JSONExporter{}
-	case "csv":
-		exporter = &strategy.CSVExporter{}
-	default:
+	exporter, err := strategy.NewExporter(domain.ExportFormat(req.Format), options)
+	if err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Unsupported export format"})
 		return
 	}
 
-	// Export the data
-	exportData, err := exporter.Export(data)
+	exporter, err = strategy.Compress(exporter, req.compression())
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to export data: " + err.Error()})
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Unsupported export compression"})
 		return
 	}
 
-	// Set the appropriate content type and filename
-	var contentType, extension string
-	switch req.Format {
-	case "json":
-		contentType = "application/json"
-		extension = "json"
-	case "csv":
-		contentType = "text/csv"
-		extension = "csv"
+	var encryption *domain.EncryptionOptions
+	if options != nil {
+		encryption = options.Encryption
+	}
+	exporter, err = strategy.Encrypt(exporter, encryption)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid encryption recipients: " + err.Error()})
+		return
+	}
+
+	exportType := domain.ExportType(req.Type)
+	source := strategy.NewChatSource(
+		h.chatService,
+		h.messageService,
+		orgID.(uint64),
+		req.Filters,
+		strategy.IncludesMessages(exporter, exportType),
+	)
+	info := strategy.ExportInfo{
+		OrganizationID: orgID.(uint64),
+		ExportType:     exportType,
+		ExportDate:     time.Now(),
+		Filter:         req.Filters,
 	}
 
 	filename := "chatlogger_export_" + strconv.FormatUint(orgID.(uint64), 10) + "_" +
-		time.Now().Format("20060102150405") + "." + extension
+		time.Now().Format("20060102150405") + exporter.Extension()
 
 	c.Header("Content-Disposition", "attachment; filename="+filename)
-	c.Data(http.StatusOK, contentType, exportData)
+	c.Header("Content-Type", exporter.ContentType())
+	c.Status(http.StatusOK)
+
+	// Stream the export straight into the response
+	if err := exporter.Export(c.Writer, info, source); err != nil {
+		if !c.Writer.Written() {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to export data: " + err.Error()})
+			return
+		}
+
+		// The response is already partially sent, so all we can do is cut it short
+		log.Printf("Sync export for organization %d aborted: %v", orgID.(uint64), err)
+		c.Abort()
+	}
 }
@@ -0,0 +1,332 @@
+// Package handler provides HTTP request handlers for the ChatLogger API.
+// This file implements handlers for managing recurring export schedules.
+package handler
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/kjanat/chatlogger-api-go/internal/domain"
+	"github.com/kjanat/chatlogger-api-go/internal/middleware"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ExportScheduleHandler handles export schedule requests.
+type ExportScheduleHandler struct {
+	scheduleService domain.ExportScheduleService
+}
+
+// NewExportScheduleHandler creates a new export schedule handler.
+func NewExportScheduleHandler(scheduleService domain.ExportScheduleService) *ExportScheduleHandler {
+	return &ExportScheduleHandler{
+		scheduleService: scheduleService,
+	}
+}
+
+// CreateExportScheduleRequest represents the request to create an export schedule.
+type CreateExportScheduleRequest struct {
+	Name          string                `binding:"required,max=100" json:"name"`
+	CronSpec      string                `binding:"required" json:"cron_spec"` // e.g. "0 6 * * 1" for Mondays at 06:00
+	Timezone      string                `json:"timezone,omitempty"`           // IANA time zone, UTC when empty
+	Format        string                `binding:"required,oneof=json csv ndjson ndjson_messages finetune markdown html xlsx parquet" json:"format"`
+	Type          string                `binding:"required,oneof=chats messages all" json:"type"`
+	Compression   string                `binding:"omitempty,oneof=none gzip zip" json:"compression,omitempty"`
+	Filters       *domain.ExportFilter  `json:"filters,omitempty"`
+	Options       *domain.ExportOptions `json:"options,omitempty"`
+	LookbackHours int                   `binding:"min=0" json:"lookback_hours,omitempty"` // Only export chats created in the last N hours of each run
+	Enabled       *bool                 `json:"enabled,omitempty"`                        // Defaults to true
+}
+
+// UpdateExportScheduleRequest represents the request to update an export schedule.
+// Fields missing from the request keep their current value.
+type UpdateExportScheduleRequest struct {
+	Name          *string               `binding:"omitempty,max=100" json:"name,omitempty"`
+	CronSpec      *string               `json:"cron_spec,omitempty"`
+	Timezone      *string               `json:"timezone,omitempty"`
+	Format        *string               `binding:"omitempty,oneof=json csv ndjson ndjson_messages finetune markdown html xlsx parquet" json:"format,omitempty"`
+	Type          *string               `binding:"omitempty,oneof=chats messages all" json:"type,omitempty"`
+	Compression   *string               `binding:"omitempty,oneof=none gzip zip" json:"compression,omitempty"`
+	Filters       *domain.ExportFilter  `json:"filters,omitempty"`
+	Options       *domain.ExportOptions `json:"options,omitempty"`
+	LookbackHours *int                  `binding:"omitempty,min=0" json:"lookback_hours,omitempty"`
+	Enabled       *bool                 `json:"enabled,omitempty"`
+}
+
+// CreateSchedule handles the request to create an export schedule
+//
+//	@Summary		Create Export Schedule
+//	@Description	Creates a recurring export. Every time the cron expression fires, the worker creates and processes a regular export job with these settings.
+//	@Tags			Export Schedules (Admin)
+//	@Accept			json
+//	@Produce		json
+//	@Param			request	body		CreateExportScheduleRequest	true	"Schedule parameters"
+//	@Success		201		{object}	domain.ExportSchedule		"Created export schedule"
+//	@Failure		400		{object}	map[string]string			"Invalid request data or cron expression"
+//	@Failure		401		{object}	map[string]string			"Unauthorized (JWT invalid/missing, Org or User ID not found)"
+//	@Failure		500		{object}	map[string]string			"Failed to create export schedule"
+//	@Security		BearerAuth
+//	@Router			/v1/exports/schedules [post]
+func (h *ExportScheduleHandler) CreateSchedule(c *gin.Context) {
+	var req CreateExportScheduleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request data: " + err.Error()})
+		return
+	}
+
+	if !validateExportSettings(c, domain.ExportType(req.Type), req.Filters, req.Options) {
+		return
+	}
+
+	// Get organization ID from context
+	orgID, exists := c.Get(middleware.OrganizationIDKey)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Organization not found"})
+		return
+	}
+
+	// Get user ID from context
+	userID, exists := c.Get(middleware.UserIDKey)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not found"})
+		return
+	}
+
+	schedule := &domain.ExportSchedule{
+		OrganizationID: orgID.(uint64),
+		UserID:         userID.(uint64),
+		Name:           req.Name,
+		CronSpec:       req.CronSpec,
+		Timezone:       req.Timezone,
+		Format:         domain.ExportFormat(req.Format),
+		Type:           domain.ExportType(req.Type),
+		Compression:    domain.ExportCompressionNone,
+		LookbackHours:  req.LookbackHours,
+		Enabled:        req.Enabled == nil || *req.Enabled,
+	}
+	if req.Compression != "" {
+		schedule.Compression = domain.ExportCompression(req.Compression)
+	}
+
+	if err := schedule.SetFilters(req.Filters); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to process filters: " + err.Error()})
+		return
+	}
+
+	if err := schedule.SetOptions(req.Options); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to process options: " + err.Error()})
+		return
+	}
+
+	if err := h.scheduleService.CreateSchedule(schedule); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Failed to create export schedule: " + err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, schedule)
+}
+
+// ListSchedules handles the request to list the export schedules of an organization
+//
+//	@Summary		List Export Schedules
+//	@Description	Retrieves all export schedules of the user's organization.
+//	@Tags			Export Schedules (Admin)
+//	@Produce		json
+//	@Success		200	{array}		domain.ExportSchedule	"List of export schedules"
+//	@Failure		401	{object}	map[string]string		"Unauthorized (JWT invalid/missing or Org ID not found)"
+//	@Failure		500	{object}	map[string]string		"Failed to fetch export schedules"
+//	@Security		BearerAuth
+//	@Router			/v1/exports/schedules [get]
+func (h *ExportScheduleHandler) ListSchedules(c *gin.Context) {
+	// Get organization ID from context
+	orgID, exists := c.Get(middleware.OrganizationIDKey)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Organization not found"})
+		return
+	}
+
+	schedules, err := h.scheduleService.ListSchedules(orgID.(uint64))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch export schedules"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"schedules": schedules})
+}
+
+// GetSchedule handles the request to get an export schedule
+//
+//	@Summary		Get Export Schedule
+//	@Description	Retrieves a single export schedule of the user's organization.
+//	@Tags			Export Schedules (Admin)
+//	@Produce		json
+//	@Param			id	path		uint64					true	"Export Schedule ID"
+//	@Success		200	{object}	domain.ExportSchedule	"Export schedule details"
+//	@Failure		400	{object}	map[string]string		"Invalid export schedule ID"
+//	@Failure		401	{object}	map[string]string		"Unauthorized (JWT invalid/missing or Org ID not found)"
+//	@Failure		404	{object}	map[string]string		"Export schedule not found"
+//	@Security		BearerAuth
+//	@Router			/v1/exports/schedules/{id} [get]
+func (h *ExportScheduleHandler) GetSchedule(c *gin.Context) {
+	schedule, ok := h.loadSchedule(c)
+	if !ok {
+		return
+	}
+
+	c.JSON(http.StatusOK, schedule)
+}
+
+// UpdateSchedule handles the request to update an export schedule
+//
+//	@Summary		Update Export Schedule
+//	@Description	Updates an export schedule of the user's organization. Fields missing from the request keep their current value. Changes are picked up by the worker within a minute.
+//	@Tags			Export Schedules (Admin)
+//	@Accept			json
+//	@Produce		json
+//	@Param			id		path		uint64						true	"Export Schedule ID"
+//	@Param			request	body		UpdateExportScheduleRequest	true	"Fields to update"
+//	@Success		200		{object}	domain.ExportSchedule		"Updated export schedule"
+//	@Failure		400		{object}	map[string]string			"Invalid export schedule ID, request data or cron expression"
+//	@Failure		401		{object}	map[string]string			"Unauthorized (JWT invalid/missing or Org ID not found)"
+//	@Failure		404		{object}	map[string]string			"Export schedule not found"
+//	@Failure		500		{object}	map[string]string			"Failed to update export schedule"
+//	@Security		BearerAuth
+//	@Router			/v1/exports/schedules/{id} [patch]
+func (h *ExportScheduleHandler) UpdateSchedule(c *gin.Context) {
+	var req UpdateExportScheduleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request data: " + err.Error()})
+		return
+	}
+
+	schedule, ok := h.loadSchedule(c)
+	if !ok {
+		return
+	}
+
+	// Validate against the updated type, which the stored options must suit too
+	exportType := schedule.Type
+	if req.Type != nil {
+		exportType = domain.ExportType(*req.Type)
+	}
+	options := req.Options
+	if options == nil {
+		stored, err := schedule.GetOptions()
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to process options: " + err.Error()})
+			return
+		}
+		options = stored
+	}
+
+	if !validateExportSettings(c, exportType, req.Filters, options) {
+		return
+	}
+
+	// Update schedule fields if provided
+	if req.Name != nil {
+		schedule.Name = *req.Name
+	}
+	if req.CronSpec != nil {
+		schedule.CronSpec = *req.CronSpec
+	}
+	if req.Timezone != nil {
+		schedule.Timezone = *req.Timezone
+	}
+	if req.Format != nil {
+		schedule.Format = domain.ExportFormat(*req.Format)
+	}
+	if req.Type != nil {
+		schedule.Type = domain.ExportType(*req.Type)
+	}
+	if req.Compression != nil {
+		schedule.Compression = domain.ExportCompression(*req.Compression)
+	}
+	if req.LookbackHours != nil {
+		schedule.LookbackHours = *req.LookbackHours
+	}
+	if req.Enabled != nil {
+		schedule.Enabled = *req.Enabled
+	}
+
+	if req.Filters != nil {
+		if err := schedule.SetFilters(req.Filters); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to process filters: " + err.Error()})
+			return
+		}
+	}
+
+	if req.Options != nil {
+		if err := schedule.SetOptions(req.Options); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to process options: " + err.Error()})
+			return
+		}
+	}
+
+	if err := h.scheduleService.UpdateSchedule(schedule); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Failed to update export schedule: " + err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, schedule)
+}
+
+// DeleteSchedule handles the request to delete an export schedule
+//
+//	@Summary		Delete Export Schedule
+//	@Description	Deletes an export schedule of the user's organization. Exports it already created are kept.
+//	@Tags			Export Schedules (Admin)
+//	@Produce		json
+//	@Param			id	path		uint64				true	"Export Schedule ID"
+//	@Success		204	"Export schedule deleted"
+//	@Failure		400	{object}	map[string]string	"Invalid export schedule ID"
+//	@Failure		401	{object}	map[string]string	"Unauthorized (JWT invalid/missing or Org ID not found)"
+//	@Failure		404	{object}	map[string]string	"Export schedule not found"
+//	@Security		BearerAuth
+//	@Router			/v1/exports/schedules/{id} [delete]
+func (h *ExportScheduleHandler) DeleteSchedule(c *gin.Context) {
+	scheduleID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid export schedule ID"})
+		return
+	}
+
+	// Get organization ID from context
+	orgID, exists := c.Get(middleware.OrganizationIDKey)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Organization not found"})
+		return
+	}
+
+	if err := h.scheduleService.DeleteSchedule(scheduleID, orgID.(uint64)); err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Export schedule not found"})
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// loadSchedule gets the export schedule of the request's ID parameter,
+// writing an error response when it is not available.
+func (h *ExportScheduleHandler) loadSchedule(c *gin.Context) (*domain.ExportSchedule, bool) {
+	scheduleID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid export schedule ID"})
+		return nil, false
+	}
+
+	// Get organization ID from context
+	orgID, exists := c.Get(middleware.OrganizationIDKey)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Organization not found"})
+		return nil, false
+	}
+
+	schedule, err := h.scheduleService.GetSchedule(scheduleID, orgID.(uint64))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Export schedule not found"})
+		return nil, false
+	}
+
+	return schedule, true
+}
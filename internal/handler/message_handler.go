@@ -1,8 +1,10 @@
 package handler
 
 import (
+	"errors"
 	"net/http"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/kjanat/chatlogger-api-go/internal/domain"
@@ -116,6 +118,14 @@ func (h *MessageHandler) CreateMessage(c *gin.Context) {
 		CreatedAt: time.Now(),
 	}
 
+	if req.Metadata != nil {
+		if err := req.Metadata.Validate(); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid message metadata: " + err.Error()})
+
+			return
+		}
+	}
+
 	// Set metadata
 	if err := message.SetMetadata(req.Metadata); err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to process message metadata: " + err.Error()})
@@ -152,12 +162,15 @@ type GetMessageResponse struct {
 // GetMessages handles the request to get all messages for a chat.
 //
 //	@Summary		Get Chat Messages
-//	@Description	Retrieves all messages associated with a specific chat session.
+//	@Description	Retrieves the messages of a specific chat session in chronological order, cursor-paginated.
 //	@Tags			Messages
 //	@Produce		json
-//	@Param			chatID	path		uint64				true	"Chat ID"
-//	@Success		200		{array}		GetMessageResponse	"List of messages with parsed metadata"
-//	@Failure		400		{object}	map[string]string	"Invalid chat ID"
+//	@Param			chatID			path		uint64								true	"Chat ID"
+//	@Param			limit			query		int									false	"Number of messages per page (max 100)"	default(20)
+//	@Param			cursor			query		string								false	"Cursor of the page to fetch, from next_cursor or prev_cursor"
+//	@Param			include_total	query		bool								false	"Include the total number of messages in the chat"	default(false)
+//	@Success		200				{object}	PageResponse[GetMessageResponse]	"Page of messages with parsed metadata"
+//	@Failure		400				{object}	map[string]string					"Invalid chat ID or pagination parameters"
 //	@Failure		401		{object}	map[string]string	"Unauthorized (JWT invalid/missing or Org ID not found)"
 //	@Failure		403		{object}	map[string]string	"Permission denied (Chat doesn't belong to user's org)"
 //	@Failure		404		{object}	map[string]string	"Chat not found"
@@ -180,6 +193,15 @@ func (h *MessageHandler) GetMessages(c *gin.Context) {
 		return
 	}
 
+	// Parse pagination parameters
+	params := newQueryParser(c)
+	page := params.pageRequest()
+	if params.err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request: " + params.err.Error()})
+
+		return
+	}
+
 	// Get the chat to validate ownership
 	chat, err := h.chatService.GetByID(id)
 	if err != nil {
@@ -213,17 +235,22 @@ func (h *MessageHandler) GetMessages(c *gin.Context) {
 		return
 	}
 
-	// Get messages for the chat
-	messages, err := h.messageService.GetByChatID(chat.ID)
+	// Get a page of messages for the chat
+	messages, err := h.messageService.GetPageByChatID(chat.ID, page)
 	if err != nil {
+		if errors.Is(err, domain.ErrInvalidCursor) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid cursor"})
+
+			return
+		}
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get messages: " + err.Error()})
 
 		return
 	}
 
 	// Prepare response with parsed metadata
-	responseMessages := make([]GetMessageResponse, len(messages))
-	for i, msg := range messages {
+	responseMessages := make([]GetMessageResponse, len(messages.Items))
+	for i, msg := range messages.Items {
 		respMsg := GetMessageResponse{
 			Message: &msg,
 		}
@@ -236,7 +263,10 @@ func (h *MessageHandler) GetMessages(c *gin.Context) {
 		responseMessages[i] = respMsg
 	}
 
-	c.JSON(http.StatusOK, responseMessages)
+	c.JSON(http.StatusOK, PageResponse[GetMessageResponse]{
+		Data:       responseMessages,
+		Pagination: newPageInfo(messages, page.Limit),
+	})
 }
 
 // GetMessageStats handles the request to get message statistics for an organization.
@@ -304,3 +334,78 @@ func (h *MessageHandler) GetMessageStats(c *gin.Context) {
 
 	c.JSON(http.StatusOK, stats)
 }
+
+// SearchMessages handles the request to search message content across the organization's chats.
+//
+//	@Summary		Search Messages
+//	@Description	Full-text search across the message content of the user's organization. Hits are grouped by chat, best match first, with HTML-escaped snippets highlighting the matches in <mark> tags. The query uses web search syntax: "quoted text" matches a phrase, "or" combines alternatives and -word excludes a word.
+//	@Tags			Messages
+//	@Produce		json
+//	@Param			q				query		string						true	"Search query"
+//	@Param			language		query		string						false	"ISO-639 language code whose stemming is applied to the query (e.g., en). Without it, words are matched exactly."
+//	@Param			roles			query		[]string					false	"Only search messages with these roles"	collectionFormat(csv)
+//	@Param			tags			query		[]string					false	"Only search chats carrying at least one of these tags"	collectionFormat(csv)
+//	@Param			created_from	query		string						false	"Only search messages created at or after this time (RFC3339)"
+//	@Param			created_to		query		string						false	"Only search messages created at or before this time (RFC3339)"
+//	@Param			hits_per_chat	query		int							false	"Number of hits returned per chat (max 20)"	default(3)
+//	@Param			limit			query		int							false	"Number of chats per page (max 100)"		default(20)
+//	@Param			offset			query		int							false	"Offset for pagination"						default(0)
+//	@Success		200				{object}	domain.MessageSearchResult	"Matching chats with their hits"
+//	@Failure		400				{object}	map[string]string			"Invalid search query or filters"
+//	@Failure		401				{object}	map[string]string			"Unauthorized (JWT invalid/missing or Org ID not found)"
+//	@Failure		500				{object}	map[string]string			"Failed to search messages"
+//	@Security		BearerAuth
+//	@Router			/v1/search [get]
+func (h *MessageHandler) SearchMessages(c *gin.Context) {
+	// Get organization ID from context
+	orgID, exists := c.Get("orgID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Organization ID not found in context"})
+
+		return
+	}
+
+	params := newQueryParser(c)
+	query := &domain.MessageSearchQuery{
+		Query:       strings.TrimSpace(c.Query("q")),
+		Language:    c.Query("language"),
+		Tags:        params.list("tags"),
+		CreatedFrom: params.timeValue("created_from"),
+		CreatedTo:   params.timeValue("created_to"),
+	}
+
+	for _, role := range params.list("roles") {
+		query.Roles = append(query.Roles, domain.MessageRole(role))
+	}
+
+	if n := params.intValue("hits_per_chat"); n != nil {
+		query.HitsPerChat = *n
+	}
+	if n := params.intValue("limit"); n != nil {
+		query.Limit = *n
+	}
+	if n := params.intValue("offset"); n != nil {
+		query.Offset = *n
+	}
+
+	if params.err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid search: " + params.err.Error()})
+
+		return
+	}
+
+	if err := query.Validate(); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid search: " + err.Error()})
+
+		return
+	}
+
+	result, err := h.messageService.SearchMessages(orgID.(uint64), query)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to search messages"})
+
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
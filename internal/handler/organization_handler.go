@@ -0,0 +1,167 @@
+// Package handler implements HTTP request handlers for the ChatLogger API.
+// This file contains handlers for organization-wide settings, such as the
+// retention period of export files, and for the usage quotas of organizations.
+package handler
+
+import (
+	"net/http"
+
+	"github.com/kjanat/chatlogger-api-go/internal/domain"
+	"github.com/kjanat/chatlogger-api-go/internal/middleware"
+	"github.com/kjanat/chatlogger-api-go/internal/strategy"
+
+	"github.com/gin-gonic/gin"
+)
+
+// OrganizationHandler handles organization-related requests.
+type OrganizationHandler struct {
+	orgService domain.OrganizationService
+}
+
+// NewOrganizationHandler creates a new organization handler.
+func NewOrganizationHandler(orgService domain.OrganizationService) *OrganizationHandler {
+	return &OrganizationHandler{
+		orgService: orgService,
+	}
+}
+
+// GetSettings handles the request to get the organization settings.
+//
+//	@Summary		Get Organization Settings
+//	@Description	Retrieves the settings of the organization associated with the authenticated user.
+//	@Tags			Organizations (Admin)
+//	@Produce		json
+//	@Success		200	{object}	domain.OrganizationSettings	"Organization settings"
+//	@Failure		401	{object}	map[string]string			"Unauthorized or Org ID not found"
+//	@Failure		500	{object}	map[string]string			"Failed to get settings"
+//	@Security		BearerAuth
+//	@Router			/v1/orgs/me/settings [get]
+func (h *OrganizationHandler) GetSettings(c *gin.Context) {
+	// Get organization ID from context
+	orgID, exists := c.Get(middleware.OrganizationIDKey)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Organization not found"})
+		return
+	}
+
+	settings, err := h.orgService.GetSettings(orgID.(uint64))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get settings"})
+		return
+	}
+
+	c.JSON(http.StatusOK, settings)
+}
+
+// UpdateSettings handles the request to update the organization settings.
+// Fields missing from the request body keep their current value.
+//
+//	@Summary		Update Organization Settings
+//	@Description	Updates the settings of the organization associated with the authenticated user. Fields missing from the request keep their current value. Quotas are set by the plan of the organization: changing them requires superadmin role.
+//	@Tags			Organizations (Admin)
+//	@Accept			json
+//	@Produce		json
+//	@Param			request	body		domain.OrganizationSettings	true	"Settings to update"
+//	@Success		200		{object}	domain.OrganizationSettings	"Updated organization settings"
+//	@Failure		400		{object}	map[string]string			"Invalid settings"
+//	@Failure		401		{object}	map[string]string			"Unauthorized or Org ID not found"
+//	@Failure		403		{object}	map[string]string			"Quotas changed without superadmin role"
+//	@Failure		500		{object}	map[string]string			"Failed to update settings"
+//	@Security		BearerAuth
+//	@Router			/v1/orgs/me/settings [patch]
+func (h *OrganizationHandler) UpdateSettings(c *gin.Context) {
+	// Get organization ID from context
+	orgID, exists := c.Get(middleware.OrganizationIDKey)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Organization not found"})
+		return
+	}
+
+	settings, err := h.orgService.GetSettings(orgID.(uint64))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get settings"})
+		return
+	}
+
+	// Keep a copy of the quotas, as decoding updates them in place
+	var quotas *domain.UsageQuotas
+	if settings.Quotas != nil {
+		current := *settings.Quotas
+		quotas = &current
+	}
+
+	// Decode the request on top of the current settings
+	if err := c.ShouldBindJSON(settings); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request data"})
+		return
+	}
+
+	// Quotas are set by the plan, so admins cannot lift their own limits
+	role, _ := c.Get(middleware.RoleKey)
+	if userRole, _ := role.(domain.Role); userRole != domain.RoleSuperAdmin && !settings.Quotas.Equal(quotas) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Quotas can only be changed by a superadmin"})
+		return
+	}
+
+	if err := settings.Validate(); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid settings: " + err.Error()})
+		return
+	}
+
+	if _, err := strategy.ParseRecipients(settings.ExportRecipients); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid settings: " + err.Error()})
+		return
+	}
+
+	if err := h.orgService.UpdateSettings(orgID.(uint64), settings); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update settings"})
+		return
+	}
+
+	c.JSON(http.StatusOK, settings)
+}
+
+// UpdateQuotas handles the request to set the usage quotas of an organization.
+//
+//	@Summary		Set Organization Quotas
+//	@Description	Replaces the usage quotas of an organization, as set by its plan. Zero-valued quotas are unlimited; an empty object removes every limit. Requires superadmin role.
+//	@Tags			Organizations (Superadmin)
+//	@Accept			json
+//	@Produce		json
+//	@Param			slug	path		string						true	"Organization slug"
+//	@Param			request	body		domain.UsageQuotas			true	"Usage quotas"
+//	@Success		200		{object}	domain.OrganizationSettings	"Updated organization settings"
+//	@Failure		400		{object}	map[string]string			"Invalid quotas"
+//	@Failure		401		{object}	map[string]string			"Unauthorized"
+//	@Failure		403		{object}	map[string]string			"Insufficient permissions"
+//	@Failure		404		{object}	map[string]string			"Organization not found"
+//	@Failure		500		{object}	map[string]string			"Failed to update quotas"
+//	@Security		BearerAuth
+//	@Router			/v1/orgs/{slug}/quotas [put]
+func (h *OrganizationHandler) UpdateQuotas(c *gin.Context) {
+	// Get the organization resolved from the slug
+	orgID, exists := c.Get(middleware.RequestedOrgIDKey)
+	if !exists {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Organization not found"})
+		return
+	}
+
+	var quotas domain.UsageQuotas
+	if err := c.ShouldBindJSON(&quotas); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request data"})
+		return
+	}
+
+	if err := quotas.Validate(); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid quotas: " + err.Error()})
+		return
+	}
+
+	settings, err := h.orgService.UpdateQuotas(orgID.(uint64), &quotas)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update quotas"})
+		return
+	}
+
+	c.JSON(http.StatusOK, settings)
+}
@@ -0,0 +1,170 @@
+package handler
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/kjanat/chatlogger-api-go/internal/domain"
+
+	"github.com/gin-gonic/gin"
+)
+
+// PageResponse is the envelope of paginated listings.
+type PageResponse[T any] struct {
+	Data       []T      `json:"data"`
+	Pagination PageInfo `json:"pagination"`
+}
+
+// PageInfo describes the position of a page in a listing. Cursors are opaque
+// and passed back in the cursor query parameter to fetch the page they point to.
+type PageInfo struct {
+	Limit      int     `json:"limit"`
+	NextCursor *string `json:"next_cursor"`           // Null on the last page
+	PrevCursor *string `json:"prev_cursor"`           // Null on the first page
+	TotalCount *int64  `json:"total_count,omitempty"` // Only with include_total=true
+}
+
+// newPageResponse wraps a page in the listing envelope.
+func newPageResponse[T any](page *domain.Page[T], limit int) PageResponse[T] {
+	items := page.Items
+	if items == nil {
+		items = []T{}
+	}
+
+	return PageResponse[T]{Data: items, Pagination: newPageInfo(page, limit)}
+}
+
+// newPageInfo describes the position of a page.
+func newPageInfo[T any](page *domain.Page[T], limit int) PageInfo {
+	info := PageInfo{Limit: limit, TotalCount: page.TotalCount}
+	if page.NextCursor != nil {
+		next := page.NextCursor.Encode()
+		info.NextCursor = &next
+	}
+	if page.PrevCursor != nil {
+		prev := page.PrevCursor.Encode()
+		info.PrevCursor = &prev
+	}
+
+	return info
+}
+
+// queryParser reads optional query parameters of a request. Missing parameters
+// are returned as nil; the first malformed parameter is kept in err, so
+// handlers check once after reading every parameter.
+type queryParser struct {
+	c   *gin.Context
+	err error
+}
+
+// newQueryParser creates a query parser for the request of c.
+func newQueryParser(c *gin.Context) *queryParser {
+	return &queryParser{c: c}
+}
+
+// fail records the error of a malformed parameter unless one was already recorded.
+func (p *queryParser) fail(format string, args ...any) {
+	if p.err == nil {
+		p.err = fmt.Errorf(format, args...)
+	}
+}
+
+// timeValue reads an RFC3339 timestamp.
+func (p *queryParser) timeValue(name string) *time.Time {
+	value := p.c.Query(name)
+	if value == "" {
+		return nil
+	}
+
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		p.fail("invalid %s date format, must be RFC3339", name)
+		return nil
+	}
+
+	return &t
+}
+
+// intValue reads an integer.
+func (p *queryParser) intValue(name string) *int {
+	value := p.c.Query(name)
+	if value == "" {
+		return nil
+	}
+
+	n, err := strconv.Atoi(value)
+	if err != nil {
+		p.fail("invalid %s, must be an integer", name)
+		return nil
+	}
+
+	return &n
+}
+
+// uintValue reads an unsigned 64-bit integer, such as an ID.
+func (p *queryParser) uintValue(name string) *uint64 {
+	value := p.c.Query(name)
+	if value == "" {
+		return nil
+	}
+
+	n, err := strconv.ParseUint(value, 10, 64)
+	if err != nil {
+		p.fail("invalid %s, must be a positive integer", name)
+		return nil
+	}
+
+	return &n
+}
+
+// boolValue reads a boolean.
+func (p *queryParser) boolValue(name string) *bool {
+	value := p.c.Query(name)
+	if value == "" {
+		return nil
+	}
+
+	b, err := strconv.ParseBool(value)
+	if err != nil {
+		p.fail("invalid %s, must be true or false", name)
+		return nil
+	}
+
+	return &b
+}
+
+// list reads a comma-separated list, dropping empty items.
+func (p *queryParser) list(name string) []string {
+	var items []string
+	for _, item := range strings.Split(p.c.Query(name), ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+
+	return items
+}
+
+// pageRequest reads the limit, cursor and include_total parameters of a paginated listing.
+func (p *queryParser) pageRequest() *domain.PageRequest {
+	page := &domain.PageRequest{}
+	if limit := p.intValue("limit"); limit != nil {
+		page.Limit = *limit
+	}
+
+	if value := p.c.Query("cursor"); value != "" {
+		cursor, err := domain.DecodeCursor(value)
+		if err != nil {
+			p.fail("invalid cursor")
+		}
+		page.Cursor = cursor
+	}
+
+	if includeTotal := p.boolValue("include_total"); includeTotal != nil {
+		page.IncludeTotal = *includeTotal
+	}
+
+	return page
+}
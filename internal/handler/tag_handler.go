@@ -0,0 +1,199 @@
+// Package handler provides HTTP request handlers for the ChatLogger API.
+// This file implements handlers for managing the tags of an organization's chats.
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/kjanat/chatlogger-api-go/internal/domain"
+	"github.com/kjanat/chatlogger-api-go/internal/middleware"
+
+	"github.com/gin-gonic/gin"
+)
+
+// TagHandler handles tag management requests.
+type TagHandler struct {
+	chatService domain.ChatService
+}
+
+// NewTagHandler creates a new tag handler.
+func NewTagHandler(chatService domain.ChatService) *TagHandler {
+	return &TagHandler{
+		chatService: chatService,
+	}
+}
+
+// RenameTagRequest represents the request to rename a tag.
+type RenameTagRequest struct {
+	From string `binding:"required" json:"from"`
+	To   string `binding:"required" json:"to"`
+}
+
+// MergeTagsRequest represents the request to merge tags into one.
+type MergeTagsRequest struct {
+	Sources []string `binding:"required,min=1" json:"sources"`
+	Target  string   `binding:"required"       json:"target"`
+}
+
+// TagListResponse lists the tags of an organization.
+type TagListResponse struct {
+	Tags []domain.TagCount `json:"tags"`
+}
+
+// TagChangeResponse reports the number of chats changed by a tag operation.
+type TagChangeResponse struct {
+	UpdatedChats int64 `json:"updated_chats"`
+}
+
+// ListTags handles the request to list the tags of an organization
+//
+//	@Summary		List Tags
+//	@Description	Lists the distinct tags of the user's organization's chats with the number of chats carrying each, most used first.
+//	@Tags			Tags
+//	@Produce		json
+//	@Success		200	{object}	TagListResponse		"Tags with their chat counts"
+//	@Failure		401	{object}	map[string]string	"Unauthorized (JWT invalid/missing or Org ID not found)"
+//	@Failure		500	{object}	map[string]string	"Failed to list tags"
+//	@Security		BearerAuth
+//	@Router			/v1/tags [get]
+func (h *TagHandler) ListTags(c *gin.Context) {
+	// Get organization ID from context
+	orgID, exists := c.Get(middleware.OrganizationIDKey)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Organization not found"})
+		return
+	}
+
+	tags, err := h.chatService.ListTags(orgID.(uint64))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to list tags"})
+		return
+	}
+
+	c.JSON(http.StatusOK, TagListResponse{Tags: tags})
+}
+
+// RenameTag handles the request to rename a tag across all chats
+//
+//	@Summary		Rename Tag
+//	@Description	Renames a tag on every chat of the user's organization. Chats already carrying the new name keep a single copy. Requires admin role.
+//	@Tags			Tags
+//	@Accept			json
+//	@Produce		json
+//	@Param			request	body		RenameTagRequest	true	"Current and new tag name"
+//	@Success		200		{object}	TagChangeResponse	"Number of chats changed"
+//	@Failure		400		{object}	map[string]string	"Invalid request data or tag"
+//	@Failure		401		{object}	map[string]string	"Unauthorized (JWT invalid/missing or Org ID not found)"
+//	@Failure		403		{object}	map[string]string	"Forbidden (User does not have admin role)"
+//	@Failure		500		{object}	map[string]string	"Failed to rename tag"
+//	@Security		BearerAuth
+//	@Router			/v1/tags/rename [post]
+func (h *TagHandler) RenameTag(c *gin.Context) {
+	var req RenameTagRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request data: " + err.Error()})
+		return
+	}
+
+	// Get organization ID from context
+	orgID, exists := c.Get(middleware.OrganizationIDKey)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Organization not found"})
+		return
+	}
+
+	updated, err := h.chatService.RenameTag(orgID.(uint64), req.From, req.To)
+	if err != nil {
+		respondTagChangeError(c, "Failed to rename tag", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, TagChangeResponse{UpdatedChats: updated})
+}
+
+// MergeTags handles the request to merge tags into one
+//
+//	@Summary		Merge Tags
+//	@Description	Replaces the source tags with the target tag on every chat of the user's organization. Requires admin role.
+//	@Tags			Tags
+//	@Accept			json
+//	@Produce		json
+//	@Param			request	body		MergeTagsRequest	true	"Tags to merge and the tag they become"
+//	@Success		200		{object}	TagChangeResponse	"Number of chats changed"
+//	@Failure		400		{object}	map[string]string	"Invalid request data or tags"
+//	@Failure		401		{object}	map[string]string	"Unauthorized (JWT invalid/missing or Org ID not found)"
+//	@Failure		403		{object}	map[string]string	"Forbidden (User does not have admin role)"
+//	@Failure		500		{object}	map[string]string	"Failed to merge tags"
+//	@Security		BearerAuth
+//	@Router			/v1/tags/merge [post]
+func (h *TagHandler) MergeTags(c *gin.Context) {
+	var req MergeTagsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request data: " + err.Error()})
+		return
+	}
+
+	// Get organization ID from context
+	orgID, exists := c.Get(middleware.OrganizationIDKey)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Organization not found"})
+		return
+	}
+
+	updated, err := h.chatService.MergeTags(orgID.(uint64), req.Sources, req.Target)
+	if err != nil {
+		respondTagChangeError(c, "Failed to merge tags", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, TagChangeResponse{UpdatedChats: updated})
+}
+
+// BulkUpdateTags handles the request to add and remove tags on a selection of chats
+//
+//	@Summary		Bulk Update Tags
+//	@Description	Adds and removes tags on the chats selected by ID, by filter, or by both. Only chats whose tags change are counted.
+//	@Tags			Tags
+//	@Accept			json
+//	@Produce		json
+//	@Param			request	body		domain.TagUpdate	true	"Chat selection and tags to add or remove"
+//	@Success		200		{object}	TagChangeResponse	"Number of chats changed"
+//	@Failure		400		{object}	map[string]string	"Invalid request data, selection or tags"
+//	@Failure		401		{object}	map[string]string	"Unauthorized (JWT invalid/missing or Org ID not found)"
+//	@Failure		500		{object}	map[string]string	"Failed to update tags"
+//	@Security		BearerAuth
+//	@Router			/v1/tags/bulk [post]
+func (h *TagHandler) BulkUpdateTags(c *gin.Context) {
+	var req domain.TagUpdate
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request data: " + err.Error()})
+		return
+	}
+
+	// Get organization ID from context
+	orgID, exists := c.Get(middleware.OrganizationIDKey)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Organization not found"})
+		return
+	}
+
+	updated, err := h.chatService.UpdateTags(orgID.(uint64), &req)
+	if err != nil {
+		respondTagChangeError(c, "Failed to update tags", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, TagChangeResponse{UpdatedChats: updated})
+}
+
+// respondTagChangeError writes the error response of a failed tag operation:
+// invalid changes are the client's fault, anything else is not.
+func respondTagChangeError(c *gin.Context, message string, err error) {
+	if errors.Is(err, domain.ErrInvalidTagChange) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusInternalServerError, gin.H{"error": message})
+}
@@ -0,0 +1,67 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/kjanat/chatlogger-api-go/internal/domain"
+	"github.com/kjanat/chatlogger-api-go/internal/middleware"
+
+	"github.com/gin-gonic/gin"
+)
+
+// UsageHandler handles usage-related requests.
+type UsageHandler struct {
+	usageService domain.UsageService
+}
+
+// NewUsageHandler creates a new usage handler.
+func NewUsageHandler(usageService domain.UsageService) *UsageHandler {
+	return &UsageHandler{
+		usageService: usageService,
+	}
+}
+
+// GetUsage handles the request to get the ingestion usage of the organization.
+//
+//	@Summary		Get Organization Usage
+//	@Description	Lists the chats, messages and request bytes the organization ingested per UTC day and per API key (0 for the dashboard) over the days overlapping a date range, with the usage of today and this month and the quotas of the organization settings. Requests creating chats or messages are answered with 429 once a quota is reached.
+//	@Tags			Organizations (Admin)
+//	@Produce		json
+//	@Param			start	query		string						false	"Start date (RFC3339 format, e.g., 2023-01-01T00:00:00Z). Defaults to 30 days before end."
+//	@Param			end		query		string						false	"End date (RFC3339 format, e.g., 2023-01-31T23:59:59Z). Defaults to now."
+//	@Success		200		{object}	domain.OrganizationUsage	"Organization usage"
+//	@Failure		400		{object}	map[string]string			"Invalid date format or range"
+//	@Failure		401		{object}	map[string]string			"Unauthorized or Org ID not found"
+//	@Failure		500		{object}	map[string]string			"Failed to get usage"
+//	@Security		BearerAuth
+//	@Router			/v1/orgs/me/usage [get]
+func (h *UsageHandler) GetUsage(c *gin.Context) {
+	// Get organization ID from context
+	orgID, exists := c.Get(middleware.OrganizationIDKey)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Organization not found"})
+		return
+	}
+
+	params := newQueryParser(c)
+	query := &domain.UsageViewQuery{}
+	if start := params.timeValue("start"); start != nil {
+		query.Start = *start
+	}
+	if end := params.timeValue("end"); end != nil {
+		query.End = *end
+	}
+
+	if params.err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": params.err.Error()})
+		return
+	}
+
+	usage, err := h.usageService.GetUsage(orgID.(uint64), query)
+	if err != nil {
+		respondAnalyticsError(c, "Failed to get usage", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, usage)
+}
@@ -1,6 +1,7 @@
 package handler
 
 import (
+	"errors"
 	"net/http"
 
 	"github.com/kjanat/chatlogger-api-go/internal/domain"
@@ -172,17 +173,19 @@ func (h *UserHandler) ChangePassword(c *gin.Context) {
 // ListOrgUsers handles the request to list all users in the current organization.
 //
 //	@Summary		List Organization Users (Admin)
-//	@Description	Retrieves a paginated list of all users belonging to the authenticated user's organization. Requires admin role.
+//	@Description	Retrieves a cursor-paginated list of all users belonging to the authenticated user's organization. Requires admin role.
 //	@Tags			Users (Admin)
 //	@Produce		json
-//	@Param			limit	query		int					false	"Number of users per page"	default(20)
-//	@Param			offset	query		int					false	"Offset for pagination"		default(0)
-//	@Success		200		{array}		domain.User			"List of users in the organization"
-//	@Failure		401		{object}	map[string]string	"Unauthorized (JWT invalid/missing or Org ID not found)"
-//	@Failure		403		{object}	map[string]string	"Forbidden (User does not have admin role)"	//	Assuming	RoleRequired	middleware	handles	this
-//	@Failure		500		{object}	map[string]string	"Failed to get users"
+//	@Param			limit			query		int							false	"Number of users per page (max 100)"	default(20)
+//	@Param			cursor			query		string						false	"Cursor of the page to fetch, from next_cursor or prev_cursor"
+//	@Param			include_total	query		bool						false	"Include the total number of users"	default(false)
+//	@Success		200				{object}	PageResponse[domain.User]	"Page of users in the organization"
+//	@Failure		400				{object}	map[string]string			"Invalid pagination parameters"
+//	@Failure		401				{object}	map[string]string			"Unauthorized (JWT invalid/missing or Org ID not found)"
+//	@Failure		403				{object}	map[string]string			"Forbidden (User does not have admin role)"
+//	@Failure		500				{object}	map[string]string			"Failed to get users"
 //	@Security		BearerAuth
-//	@Router			/v1/orgs/me/users [get] // Assuming this route exists and is admin-protected
+//	@Router			/v1/orgs/me/users [get]
 func (h *UserHandler) ListOrgUsers(c *gin.Context) {
 	// Get organization ID from context
 	orgID, exists := c.Get("orgID")
@@ -193,16 +196,26 @@ func (h *UserHandler) ListOrgUsers(c *gin.Context) {
 	}
 
 	// Parse pagination parameters
-	limit := 20 // Default limit
-	offset := 0 // Default offset
+	params := newQueryParser(c)
+	page := params.pageRequest()
+	if params.err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request: " + params.err.Error()})
+
+		return
+	}
 
 	// Get users
-	users, err := h.userService.GetByOrganizationID(orgID.(uint64), limit, offset)
+	users, err := h.userService.GetByOrganizationID(orgID.(uint64), page)
 	if err != nil {
+		if errors.Is(err, domain.ErrInvalidCursor) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid cursor"})
+
+			return
+		}
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get users"})
 
 		return
 	}
 
-	c.JSON(http.StatusOK, users)
+	c.JSON(http.StatusOK, newPageResponse(users, page.Limit))
 }
@@ -0,0 +1,80 @@
+// Package jobs provides asynchronous job processing capabilities for the ChatLogger API.
+// This file contains the export cleanup processor, which deletes the files of
+// exports whose retention period has passed.
+package jobs
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/hibiken/asynq"
+
+	"github.com/kjanat/chatlogger-api-go/internal/domain"
+	"github.com/kjanat/chatlogger-api-go/internal/storage"
+)
+
+// cleanupBatchSize is the number of expired exports handled per database round trip.
+const cleanupBatchSize = 100
+
+// ExportCleanupProcessor handles the periodic cleanup of expired exports
+type ExportCleanupProcessor struct {
+	exportRepo domain.ExportRepository
+	store      storage.Storage
+}
+
+// NewExportCleanupProcessor creates a new export cleanup processor
+func NewExportCleanupProcessor(exportRepo domain.ExportRepository, store storage.Storage) *ExportCleanupProcessor {
+	return &ExportCleanupProcessor{
+		exportRepo: exportRepo,
+		store:      store,
+	}
+}
+
+// ProcessCleanup deletes the files of all expired exports and marks them expired.
+// An export whose file cannot be deleted is left completed, so the next run
+// retries it.
+func (p *ExportCleanupProcessor) ProcessCleanup(ctx context.Context, task *asynq.Task) error {
+	now := time.Now()
+	expired := 0
+	failed := 0
+
+	for {
+		exports, err := p.exportRepo.FindExpired(now, cleanupBatchSize)
+		if err != nil {
+			return fmt.Errorf("failed to find expired exports: %w", err)
+		}
+
+		progressed := false
+		for _, export := range exports {
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+
+			if export.FilePath != "" {
+				if err := p.store.Delete(ctx, export.FilePath); err != nil {
+					log.Printf("Failed to delete file of expired export %d: %v", export.ID, err)
+					failed++
+					continue
+				}
+			}
+
+			if err := p.exportRepo.MarkExpired(export.ID); err != nil {
+				return fmt.Errorf("failed to mark export %d expired: %w", export.ID, err)
+			}
+			expired++
+			progressed = true
+		}
+
+		// Stop when everything is handled, or when only undeletable files are left
+		if len(exports) < cleanupBatchSize || !progressed {
+			break
+		}
+	}
+
+	if expired > 0 || failed > 0 {
+		log.Printf("Export cleanup: %d exports expired, %d files could not be deleted", expired, failed)
+	}
+	return nil
+}
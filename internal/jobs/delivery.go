@@ -0,0 +1,102 @@
+// Package jobs provides asynchronous job processing capabilities for the ChatLogger API.
+// This file contains the delivery processor, which sends completed exports to
+// their export destinations and records every attempt.
+package jobs
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+
+	"github.com/hibiken/asynq"
+
+	"github.com/kjanat/chatlogger-api-go/internal/delivery"
+	"github.com/kjanat/chatlogger-api-go/internal/domain"
+)
+
+// DeliveryProcessor handles export delivery tasks
+type DeliveryProcessor struct {
+	exportRepo      domain.ExportRepository
+	deliveryRepo    domain.ExportDeliveryRepository
+	destinationRepo domain.ExportDestinationRepository
+	deliverer       *delivery.Deliverer
+}
+
+// NewDeliveryProcessor creates a new delivery processor
+func NewDeliveryProcessor(
+	exportRepo domain.ExportRepository,
+	deliveryRepo domain.ExportDeliveryRepository,
+	destinationRepo domain.ExportDestinationRepository,
+	deliverer *delivery.Deliverer,
+) *DeliveryProcessor {
+	return &DeliveryProcessor{
+		exportRepo:      exportRepo,
+		deliveryRepo:    deliveryRepo,
+		destinationRepo: destinationRepo,
+		deliverer:       deliverer,
+	}
+}
+
+// ProcessDelivery delivers an export to one destination. Failed attempts are
+// recorded and retried by asynq; the delivery is marked failed once the last
+// retry fails.
+func (p *DeliveryProcessor) ProcessDelivery(ctx context.Context, task *asynq.Task) error {
+	var payload DeliveryPayload
+	if err := json.Unmarshal(task.Payload(), &payload); err != nil {
+		return fmt.Errorf("failed to unmarshal payload: %w", err)
+	}
+
+	exportDelivery, err := p.deliveryRepo.GetByID(payload.DeliveryID)
+	if err != nil {
+		return fmt.Errorf("failed to get delivery: %w", err)
+	}
+	if exportDelivery.Status == domain.ExportDeliveryDelivered {
+		return nil
+	}
+
+	export, err := p.exportRepo.GetByID(exportDelivery.ExportID)
+	if err != nil {
+		return fmt.Errorf("failed to get export: %w", err)
+	}
+	if export.Status != domain.ExportStatusCompleted {
+		return p.failDelivery(exportDelivery.ID, fmt.Sprintf("export is %s", export.Status))
+	}
+
+	destination, err := p.destinationRepo.GetByID(exportDelivery.DestinationID)
+	if err != nil {
+		return p.failDelivery(exportDelivery.ID, "destination no longer exists")
+	}
+	if !destination.Enabled {
+		return p.failDelivery(exportDelivery.ID, "destination is disabled")
+	}
+
+	if err := p.deliverer.Deliver(ctx, destination, export); err != nil {
+		status := domain.ExportDeliveryPending
+		retried, _ := asynq.GetRetryCount(ctx)
+		maxRetry, _ := asynq.GetMaxRetry(ctx)
+		if retried >= maxRetry {
+			status = domain.ExportDeliveryFailed
+		}
+
+		if recordErr := p.deliveryRepo.RecordAttempt(exportDelivery.ID, status, err.Error()); recordErr != nil {
+			log.Printf("Failed to record attempt of delivery %d: %v", exportDelivery.ID, recordErr)
+		}
+		return fmt.Errorf("failed to deliver export %d to destination %d: %w", export.ID, destination.ID, err)
+	}
+
+	if err := p.deliveryRepo.RecordAttempt(exportDelivery.ID, domain.ExportDeliveryDelivered, ""); err != nil {
+		// Retrying would deliver the export twice
+		log.Printf("Delivered export %d, but failed to record delivery %d: %v", export.ID, exportDelivery.ID, err)
+	}
+
+	return nil
+}
+
+// failDelivery marks a delivery as failed for good and stops its retries.
+func (p *DeliveryProcessor) failDelivery(deliveryID uint64, errorMsg string) error {
+	if err := p.deliveryRepo.RecordAttempt(deliveryID, domain.ExportDeliveryFailed, errorMsg); err != nil {
+		return fmt.Errorf("failed to update delivery status after error %q: %w", errorMsg, err)
+	}
+	return fmt.Errorf("delivery %d failed: %s: %w", deliveryID, errorMsg, asynq.SkipRetry)
+}
@@ -6,39 +6,48 @@
 package jobs
 
 import (
+	"bufio"
 	"context"
 	"encoding/json"
 	"fmt"
-	"os"
-	"path/filepath"
+	"io"
+	"log"
 	"time"
 
 	"github.com/hibiken/asynq"
 
 	"github.com/kjanat/chatlogger-api-go/internal/domain"
+	"github.com/kjanat/chatlogger-api-go/internal/storage"
 	"github.com/kjanat/chatlogger-api-go/internal/strategy"
 )
 
 // ExportProcessor handles processing of export jobs
 type ExportProcessor struct {
 	exportRepo     domain.ExportRepository
+	orgRepo        domain.OrganizationRepository
 	chatService    domain.ChatService
 	messageService domain.MessageService
-	exportDir      string
+	store          storage.Storage
+	queue          *Queue
 }
 
-// NewExportProcessor creates a new export processor
+// NewExportProcessor creates a new export processor. Completed exports are
+// handed to their destinations through delivery jobs on queue.
 func NewExportProcessor(
 	exportRepo domain.ExportRepository,
+	orgRepo domain.OrganizationRepository,
 	chatService domain.ChatService,
 	messageService domain.MessageService,
-	exportDir string,
+	store storage.Storage,
+	queue *Queue,
 ) *ExportProcessor {
 	return &ExportProcessor{
 		exportRepo:     exportRepo,
+		orgRepo:        orgRepo,
 		chatService:    chatService,
 		messageService: messageService,
-		exportDir:      exportDir,
+		store:          store,
+		queue:          queue,
 	}
 }
 
@@ -54,105 +63,193 @@ func (p *ExportProcessor) ProcessExport(ctx context.Context, task *asynq.Task) e
 		return fmt.Errorf("failed to get export: %w", err)
 	}
 
+	// The export may have been cancelled while it was queued or between retries
+	if export.Status == domain.ExportStatusCancelled {
+		return nil
+	}
+
 	// Update status to processing
 	if err := p.exportRepo.UpdateStatus(export.ID, domain.ExportStatusProcessing, ""); err != nil {
 		return fmt.Errorf("failed to update export status: %w", err)
 	}
 
-	// Get chats for the organization
-	chats, err := p.chatService.GetByOrganizationID(export.OrganizationID, 1000, 0)
+	options, err := export.GetOptions()
 	if err != nil {
-		errorMsg := fmt.Sprintf("failed to get chats: %v", err)
-		if err := p.exportRepo.UpdateStatus(export.ID, domain.ExportStatusFailed, errorMsg); err != nil {
-			return fmt.Errorf("failed to update export status after chat error: %w", err)
-		}
-		return fmt.Errorf("%s", errorMsg)
+		return p.failExport(export.ID, fmt.Sprintf("failed to parse export options: %v", err))
 	}
 
-	// Load messages for each chat if needed
-	if export.Type == domain.ExportTypeAll || export.Type == domain.ExportTypeMessages {
-		for i := range chats {
-			messages, err := p.messageService.GetByChatID(chats[i].ID)
-			if err != nil {
-				errorMsg := fmt.Sprintf("failed to get messages for chat %d: %v", chats[i].ID, err)
-				if err := p.exportRepo.UpdateStatus(export.ID, domain.ExportStatusFailed, errorMsg); err != nil {
-					return fmt.Errorf("failed to update export status after message error: %w", err)
-				}
-				return fmt.Errorf("%s", errorMsg)
-			}
-			chats[i].Messages = messages
-		}
+	// Select appropriate exporter
+	exporter, err := strategy.NewExporter(export.Format, options)
+	if err != nil {
+		return p.failExport(export.ID, err.Error())
 	}
 
-	// Prepare data for export
-	data := map[string]interface{}{
-		"organization_id": export.OrganizationID,
-		"export_date":     time.Now().Format(time.RFC3339),
-		"export_type":     export.Type,
-		"chats":           chats,
+	exporter, err = strategy.Compress(exporter, export.Compression)
+	if err != nil {
+		return p.failExport(export.ID, err.Error())
 	}
 
-	// Select appropriate exporter
-	var exporter strategy.Exporter
-	switch export.Format {
-	case domain.ExportFormatJSON:
-		exporter = &strategy.JSONExporter{}
-	case domain.ExportFormatCSV:
-		exporter = &strategy.CSVExporter{}
-	default:
-		errorMsg := "unsupported export format"
-		if err := p.exportRepo.UpdateStatus(export.ID, domain.ExportStatusFailed, errorMsg); err != nil {
-			return fmt.Errorf("failed to update export status for unsupported format: %w", err)
-		}
-		return fmt.Errorf("%s", errorMsg)
+	// Encrypt while writing, so the export is never stored in plaintext
+	exporter, err = strategy.Encrypt(exporter, options.Encryption)
+	if err != nil {
+		return p.failExport(export.ID, fmt.Sprintf("failed to set up encryption: %v", err))
 	}
 
-	// Export the data
-	exportData, err := exporter.Export(data)
+	// Generate the storage key, which doubles as the download filename. The
+	// export ID keeps exports finishing in the same second apart.
+	key := fmt.Sprintf("export_%d_%d_%s%s",
+		export.OrganizationID,
+		export.ID,
+		time.Now().Format("20060102_150405"),
+		exporter.Extension())
+
+	filter, err := export.GetFilters()
 	if err != nil {
-		errorMsg := fmt.Sprintf("failed to export data: %v", err)
-		if err := p.exportRepo.UpdateStatus(export.ID, domain.ExportStatusFailed, errorMsg); err != nil {
-			return fmt.Errorf("failed to update export status after export error: %w", err)
-		}
-		return fmt.Errorf("%s", errorMsg)
+		return p.failExport(export.ID, fmt.Sprintf("failed to parse export filters: %v", err))
 	}
 
-	// Create export directory if it doesn't exist
-	if err := os.MkdirAll(p.exportDir, 0755); err != nil {
-		errorMsg := fmt.Sprintf("failed to create export directory: %v", err)
-		if err := p.exportRepo.UpdateStatus(export.ID, domain.ExportStatusFailed, errorMsg); err != nil {
-			return fmt.Errorf("failed to update export status after directory error: %w", err)
-		}
-		return fmt.Errorf("%s", errorMsg)
+	var chatFilter *domain.ChatFilter
+	if filter != nil {
+		chatFilter = &filter.ChatFilter
 	}
 
-	// Generate filename
-	extension := ".json"
-	if export.Format == domain.ExportFormatCSV {
-		extension = ".csv"
+	total, err := p.chatService.CountByOrganizationID(export.OrganizationID, chatFilter)
+	if err != nil {
+		return p.failExport(export.ID, fmt.Sprintf("failed to count chats: %v", err))
+	}
+	if err := p.exportRepo.UpdateProgress(export.ID, 0, int(total)); err != nil {
+		return fmt.Errorf("failed to update export progress: %w", err)
 	}
 
-	filename := fmt.Sprintf("export_%d_%s%s",
+	// Stream every matching chat of the organization straight into export storage
+	progress := &exportProgress{exportID: export.ID, total: int(total)}
+	source := p.trackProgress(ctx, progress, strategy.NewChatSource(
+		p.chatService,
+		p.messageService,
 		export.OrganizationID,
-		time.Now().Format("20060102_150405"),
-		extension)
+		filter,
+		strategy.IncludesMessages(exporter, export.Type),
+	))
+	info := strategy.ExportInfo{
+		OrganizationID: export.OrganizationID,
+		ExportType:     export.Type,
+		ExportDate:     time.Now(),
+		Filter:         filter,
+	}
 
-	filePath := filepath.Join(p.exportDir, filename)
+	err = p.store.Put(ctx, key, func(w io.Writer) error {
+		bw := bufio.NewWriter(w)
+		if err := exporter.Export(bw, info, source); err != nil {
+			return err
+		}
+		return bw.Flush()
+	})
+	if err != nil {
+		return p.failExport(export.ID, fmt.Sprintf("failed to export data: %v", err))
+	}
+
+	// Cancelled right as the file was finished: drop the file instead of completing
+	if ctx.Err() != nil {
+		_ = p.store.Delete(context.Background(), key)
+		return fmt.Errorf("export %d stopped: %v: %w", export.ID, ctx.Err(), asynq.SkipRetry)
+	}
+
+	if err := p.exportRepo.UpdateProgress(export.ID, progress.processed, progress.total); err != nil {
+		return fmt.Errorf("failed to update export progress: %w", err)
+	}
 
-	// Write file
-	if err := os.WriteFile(filePath, exportData, 0644); err != nil {
-		errorMsg := fmt.Sprintf("failed to write export file: %v", err)
-		if err := p.exportRepo.UpdateStatus(export.ID, domain.ExportStatusFailed, errorMsg); err != nil {
-			return fmt.Errorf("failed to update export status after file write error: %w", err)
+	// Schedule the file for deletion according to the organization's retention
+	ttl, err := p.exportTTL(export.OrganizationID)
+	if err != nil {
+		_ = p.store.Delete(context.Background(), key)
+		return fmt.Errorf("failed to get export TTL: %w", err)
+	}
+
+	// Record the file and complete the export, unless it was cancelled meanwhile
+	completed, err := p.exportRepo.Complete(export.ID, key, time.Now().Add(ttl))
+	if err != nil {
+		_ = p.store.Delete(context.Background(), key)
+		return fmt.Errorf("failed to complete export: %w", err)
+	}
+	if !completed {
+		// Nothing refers to the file of a cancelled export, so nothing would clean it up
+		if err := p.store.Delete(context.Background(), key); err != nil {
+			log.Printf("Failed to delete the file of cancelled export %d: %v", export.ID, err)
 		}
-		return fmt.Errorf("%s", errorMsg)
+		return nil
 	}
 
-	// Update export record with file path
-	if err := p.exportRepo.UpdateFilePath(export.ID, filePath); err != nil {
-		return fmt.Errorf("failed to update file path: %w", err)
+	// Hand the file to the export's destinations. A delivery that cannot be
+	// queued stays pending; the export itself is done, so it is not retried.
+	for _, delivery := range export.Deliveries {
+		if err := p.queue.EnqueueDelivery(delivery.ID); err != nil {
+			log.Printf("Failed to queue delivery %d of export %d: %v", delivery.ID, export.ID, err)
+		}
 	}
 
-	// Update status to completed
-	return p.exportRepo.UpdateStatus(export.ID, domain.ExportStatusCompleted, "")
+	return nil
+}
+
+// exportProgress tracks the chats written by a running export.
+type exportProgress struct {
+	exportID  uint64
+	processed int
+	total     int
+}
+
+// trackProgress wraps source so that the progress of the export is recorded
+// every strategy.ExportBatchSize chats and the export stops as soon as ctx is
+// cancelled. Bundles read their source once per file, so progress restarts
+// with every file of a bundle.
+func (p *ExportProcessor) trackProgress(
+	ctx context.Context,
+	progress *exportProgress,
+	source strategy.ChatSource,
+) strategy.ChatSource {
+	return func(fn func(chat *domain.Chat) error) error {
+		progress.processed = 0
+
+		return source(func(chat *domain.Chat) error {
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+			if err := fn(chat); err != nil {
+				return err
+			}
+
+			progress.processed++
+			if progress.processed%strategy.ExportBatchSize == 0 {
+				// Progress is informational, so a failed update does not fail the export
+				if err := p.exportRepo.UpdateProgress(progress.exportID, progress.processed, progress.total); err != nil {
+					log.Printf("Failed to update progress of export %d: %v", progress.exportID, err)
+				}
+			}
+			return nil
+		})
+	}
+}
+
+// exportTTL returns how long the export files of an organization are kept.
+func (p *ExportProcessor) exportTTL(orgID uint64) (time.Duration, error) {
+	org, err := p.orgRepo.FindByID(orgID)
+	if err != nil {
+		return 0, err
+	}
+	if org == nil {
+		return domain.DefaultExportTTL, nil
+	}
+
+	settings, err := org.GetSettings()
+	if err != nil {
+		return 0, fmt.Errorf("failed to parse organization settings: %w", err)
+	}
+	return settings.ExportTTL(), nil
+}
+
+// failExport marks an export as failed and returns the failure as an error.
+func (p *ExportProcessor) failExport(exportID uint64, errorMsg string) error {
+	if err := p.exportRepo.UpdateStatus(exportID, domain.ExportStatusFailed, errorMsg); err != nil {
+		return fmt.Errorf("failed to update export status after error %q: %w", errorMsg, err)
+	}
+	return fmt.Errorf("%s", errorMsg)
 }
@@ -5,30 +5,58 @@ package jobs
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
+	"time"
 
 	"github.com/hibiken/asynq"
 )
 
 // Task types
 const (
-	TypeExportProcess = "export:process"
+	TypeExportProcess   = "export:process"
+	TypeExportCleanup   = "export:cleanup"
+	TypeExportScheduled = "export:scheduled"
+	TypeExportDeliver   = "export:deliver"
+	TypeAnalyticsRollup = "analytics:rollup"
 )
 
+// ExportDeliveryMaxRetry is how often a failed export delivery is retried.
+const ExportDeliveryMaxRetry = 5
+
+// ExportCleanupSchedule is the cron spec of the periodic export cleanup job.
+const ExportCleanupSchedule = "@hourly"
+
+// AnalyticsRollupSchedule is the cron spec of the periodic analytics rollup job.
+const AnalyticsRollupSchedule = "*/5 * * * *"
+
 // ExportPayload contains the data needed for export processing
 type ExportPayload struct {
 	ExportID uint64 `json:"export_id"`
 }
 
+// exportQueue is the asynq queue export tasks are processed from
+const exportQueue = "exports"
+
 // Queue handles job queueing operations
 type Queue struct {
-	client *asynq.Client
+	client    *asynq.Client
+	inspector *asynq.Inspector
 }
 
 // NewQueue creates a new job queue
 func NewQueue(redisAddr string) *Queue {
-	client := asynq.NewClient(asynq.RedisClientOpt{Addr: redisAddr})
-	return &Queue{client: client}
+	redisOpt := asynq.RedisClientOpt{Addr: redisAddr}
+	return &Queue{
+		client:    asynq.NewClient(redisOpt),
+		inspector: asynq.NewInspector(redisOpt),
+	}
+}
+
+// exportTaskID returns the task ID of the processing task of an export, which
+// allows the task to be found again to cancel it.
+func exportTaskID(exportID uint64) string {
+	return fmt.Sprintf("export:%d", exportID)
 }
 
 // EnqueueExport adds an export job to the queue
@@ -43,15 +71,88 @@ func (q *Queue) EnqueueExport(exportID uint64) error {
 	// Set some processing options
 	opts := []asynq.Option{
 		asynq.MaxRetry(3),
-		asynq.Queue("exports"),
-		asynq.Timeout(20 * 60), // 20 minutes timeout for long exports
+		asynq.Queue(exportQueue),
+		asynq.TaskID(exportTaskID(exportID)),
+		asynq.Timeout(20 * time.Minute), // 20 minutes timeout for long exports
 	}
 
 	_, err = q.client.Enqueue(task, opts...)
 	return err
 }
 
-// Close closes the queue client connection
+// DeliveryPayload contains the data needed to deliver an export to a destination
+type DeliveryPayload struct {
+	DeliveryID uint64 `json:"delivery_id"`
+}
+
+// EnqueueDelivery adds an export delivery job to the queue
+func (q *Queue) EnqueueDelivery(deliveryID uint64) error {
+	payload, err := json.Marshal(DeliveryPayload{DeliveryID: deliveryID})
+	if err != nil {
+		return fmt.Errorf("failed to marshal delivery payload: %w", err)
+	}
+
+	task := asynq.NewTask(TypeExportDeliver, payload)
+
+	opts := []asynq.Option{
+		asynq.MaxRetry(ExportDeliveryMaxRetry),
+		asynq.Queue(exportQueue),
+		asynq.TaskID(fmt.Sprintf("delivery:%d", deliveryID)),
+		asynq.Timeout(10 * time.Minute),
+	}
+
+	_, err = q.client.Enqueue(task, opts...)
+	return err
+}
+
+// CancelExport removes the processing task of an export from the queue or, when
+// a worker is already running it, signals the worker to stop.
+func (q *Queue) CancelExport(exportID uint64) error {
+	taskID := exportTaskID(exportID)
+
+	// A task that is not found has already finished
+	err := q.inspector.DeleteTask(exportQueue, taskID)
+	if err == nil || errors.Is(err, asynq.ErrTaskNotFound) || errors.Is(err, asynq.ErrQueueNotFound) {
+		return nil
+	}
+
+	// Active tasks cannot be deleted, only cancelled
+	return q.inspector.CancelProcessing(taskID)
+}
+
+// NewExportCleanupTask creates the task that deletes expired export files.
+// Only one cleanup task can be queued at a time, so several schedulers may
+// register it without piling up duplicate work.
+func NewExportCleanupTask() (*asynq.Task, []asynq.Option) {
+	task := asynq.NewTask(TypeExportCleanup, nil)
+	opts := []asynq.Option{
+		asynq.Queue(exportQueue),
+		asynq.Unique(time.Hour),
+		asynq.Timeout(30 * time.Minute),
+	}
+	return task, opts
+}
+
+// NewAnalyticsRollupTask creates the task that refreshes the analytics rollups.
+// Like the cleanup task, only one can be queued or running at a time: the
+// uniqueness lock lasts as long as the task may run. Failed runs are not
+// retried, as the next scheduled run covers the same hours.
+func NewAnalyticsRollupTask() (*asynq.Task, []asynq.Option) {
+	const timeout = 30 * time.Minute
+
+	task := asynq.NewTask(TypeAnalyticsRollup, nil)
+	opts := []asynq.Option{
+		asynq.MaxRetry(0),
+		asynq.Unique(timeout),
+		asynq.Timeout(timeout),
+	}
+	return task, opts
+}
+
+// Close closes the queue client and inspector connections
 func (q *Queue) Close() error {
+	if err := q.inspector.Close(); err != nil {
+		return err
+	}
 	return q.client.Close()
 }
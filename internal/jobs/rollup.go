@@ -0,0 +1,31 @@
+// Package jobs provides asynchronous job processing capabilities for the ChatLogger API.
+// This file contains the analytics rollup processor, which keeps the hourly and
+// daily analytics rollups up to date.
+package jobs
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/hibiken/asynq"
+
+	"github.com/kjanat/chatlogger-api-go/internal/domain"
+)
+
+// RollupProcessor handles the periodic refresh of the analytics rollups
+type RollupProcessor struct {
+	rollupService domain.RollupService
+}
+
+// NewRollupProcessor creates a new rollup processor
+func NewRollupProcessor(rollupService domain.RollupService) *RollupProcessor {
+	return &RollupProcessor{rollupService: rollupService}
+}
+
+// ProcessRollup rolls up the chats and messages created since the previous run.
+func (p *RollupProcessor) ProcessRollup(ctx context.Context, task *asynq.Task) error {
+	if err := p.rollupService.Refresh(); err != nil {
+		return fmt.Errorf("failed to refresh analytics rollups: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,109 @@
+// Package jobs provides asynchronous job processing capabilities for the ChatLogger API.
+// This file contains the periodic task configuration of the worker and the
+// processor that turns export schedules into export jobs.
+package jobs
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/hibiken/asynq"
+
+	"github.com/kjanat/chatlogger-api-go/internal/domain"
+)
+
+// ScheduleSyncInterval is how often the worker reloads export schedules from the database.
+const ScheduleSyncInterval = time.Minute
+
+// ScheduledExportPayload contains the data needed to run an export schedule
+type ScheduledExportPayload struct {
+	ScheduleID uint64 `json:"schedule_id"`
+}
+
+// PeriodicTaskProvider implements asynq.PeriodicTaskConfigProvider. It provides
+// the export cleanup and analytics rollup tasks and one task per enabled export
+// schedule, so schedules created, changed or deleted through the API are picked
+// up without a restart.
+type PeriodicTaskProvider struct {
+	scheduleService domain.ExportScheduleService
+}
+
+// NewPeriodicTaskProvider creates a new periodic task provider
+func NewPeriodicTaskProvider(scheduleService domain.ExportScheduleService) *PeriodicTaskProvider {
+	return &PeriodicTaskProvider{scheduleService: scheduleService}
+}
+
+// GetConfigs returns the periodic tasks the worker should currently schedule.
+func (p *PeriodicTaskProvider) GetConfigs() ([]*asynq.PeriodicTaskConfig, error) {
+	cleanupTask, cleanupOpts := NewExportCleanupTask()
+	rollupTask, rollupOpts := NewAnalyticsRollupTask()
+	configs := []*asynq.PeriodicTaskConfig{
+		{Cronspec: ExportCleanupSchedule, Task: cleanupTask, Opts: cleanupOpts},
+		{Cronspec: AnalyticsRollupSchedule, Task: rollupTask, Opts: rollupOpts},
+	}
+
+	schedules, err := p.scheduleService.ListEnabledSchedules()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list export schedules: %w", err)
+	}
+
+	for _, schedule := range schedules {
+		payload, err := json.Marshal(ScheduledExportPayload{ScheduleID: schedule.ID})
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal scheduled export payload: %w", err)
+		}
+
+		configs = append(configs, &asynq.PeriodicTaskConfig{
+			Cronspec: schedule.CronExpression(),
+			Task:     asynq.NewTask(TypeExportScheduled, payload),
+			Opts: []asynq.Option{
+				asynq.Queue(exportQueue),
+				asynq.MaxRetry(3),
+				// Several workers may tick at the same time; run each occurrence once
+				asynq.Unique(time.Minute),
+			},
+		})
+	}
+
+	return configs, nil
+}
+
+// ScheduleProcessor handles scheduled export tasks
+type ScheduleProcessor struct {
+	scheduleService domain.ExportScheduleService
+}
+
+// NewScheduleProcessor creates a new schedule processor
+func NewScheduleProcessor(scheduleService domain.ExportScheduleService) *ScheduleProcessor {
+	return &ScheduleProcessor{scheduleService: scheduleService}
+}
+
+// ProcessScheduledExport creates a regular export from an export schedule.
+// The export itself is processed by its own export:process task.
+func (p *ScheduleProcessor) ProcessScheduledExport(ctx context.Context, task *asynq.Task) error {
+	var payload ScheduledExportPayload
+	if err := json.Unmarshal(task.Payload(), &payload); err != nil {
+		return fmt.Errorf("failed to unmarshal payload: %w", err)
+	}
+
+	export, err := p.scheduleService.RunSchedule(payload.ScheduleID)
+	if errors.Is(err, domain.ErrExportScheduleDisabled) {
+		// Disabled since the scheduler last synced
+		return nil
+	}
+	if err != nil {
+		if export != nil {
+			// The export is queued; retrying would create a duplicate
+			log.Printf("Scheduled export %d created, but: %v", export.ID, err)
+			return nil
+		}
+		return fmt.Errorf("failed to run export schedule %d: %w", payload.ScheduleID, err)
+	}
+
+	log.Printf("Export schedule %d queued export %d", payload.ScheduleID, export.ID)
+	return nil
+}
@@ -5,6 +5,7 @@ package middleware
 
 import (
 	"fmt"
+	"log"
 	"net/http"
 
 	"github.com/gin-gonic/gin"
@@ -24,6 +25,8 @@ const (
 	RoleKey = "role"
 	// RequestedOrgIDKey is the key used to store requested organization ID in the context
 	RequestedOrgIDKey = "requestedOrgID"
+	// APIKeyIDKey is the key used to store the ID of the authenticating API key in the context
+	APIKeyIDKey = "apiKeyID"
 )
 
 // JWTAuth middleware for user authentication using JWT.
@@ -104,8 +107,14 @@ func APIKeyAuth(apiKeyService domain.APIKeyService) gin.HandlerFunc {
 			return
 		}
 
-		// Set organization ID in context
+		// Failing to record the use must not fail the request
+		if err := apiKeyService.RecordUse(key); err != nil {
+			log.Printf("Failed to record use of API key %d: %v", key.ID, err)
+		}
+
+		// Set organization ID and API key ID in context
 		c.Set(OrganizationIDKey, key.OrganizationID)
+		c.Set(APIKeyIDKey, key.ID)
 
 		c.Next()
 	}
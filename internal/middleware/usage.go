@@ -0,0 +1,89 @@
+package middleware
+
+import (
+	"errors"
+	"io"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/kjanat/chatlogger-api-go/internal/domain"
+)
+
+// countingReader counts the bytes read from a request body.
+type countingReader struct {
+	io.ReadCloser
+	n int64
+}
+
+// Read reads from the body, counting the bytes read.
+func (r *countingReader) Read(p []byte) (int, error) {
+	n, err := r.ReadCloser.Read(p)
+	r.n += int64(n)
+
+	return n, err
+}
+
+// UsageQuota middleware enforces the usage quotas of the organization on
+// requests creating a resource, responding 429 once a quota is reached, and
+// counts the resource and the bytes of the request body towards the
+// authenticating API key when the request succeeds.
+func UsageQuota(usageService domain.UsageService, resource domain.UsageResource) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		orgID, exists := c.Get(OrganizationIDKey)
+		if !exists {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "Organization ID not found in context"})
+			c.Abort()
+
+			return
+		}
+
+		if err := usageService.CheckQuota(orgID.(uint64), resource); err != nil {
+			var quotaErr *domain.QuotaError
+			if errors.As(err, &quotaErr) {
+				retryAfter := int(time.Until(quotaErr.ResetAt).Seconds()) + 1
+				c.Header("Retry-After", strconv.Itoa(retryAfter))
+				c.JSON(http.StatusTooManyRequests, gin.H{"error": quotaErr.Error(), "quota": quotaErr})
+				c.Abort()
+
+				return
+			}
+
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to check usage quota"})
+			c.Abort()
+
+			return
+		}
+
+		body := &countingReader{ReadCloser: c.Request.Body}
+		c.Request.Body = body
+
+		c.Next()
+
+		if c.Writer.Status() != http.StatusCreated {
+			return
+		}
+
+		// Requests authenticated with a JWT come from the dashboard
+		var apiKeyID uint64
+		if keyID, exists := c.Get(APIKeyIDKey); exists {
+			apiKeyID = keyID.(uint64)
+		}
+
+		counts := domain.UsageCounts{Bytes: body.n}
+		switch resource {
+		case domain.UsageChats:
+			counts.Chats = 1
+		case domain.UsageMessages:
+			counts.Messages = 1
+		}
+
+		// The resource is created; failing to count it must not fail the request
+		if err := usageService.Record(orgID.(uint64), apiKeyID, counts); err != nil {
+			log.Printf("Failed to record usage of organization %d: %v", orgID, err)
+		}
+	}
+}
@@ -0,0 +1,652 @@
+package repository
+
+import (
+	"cmp"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strconv"
+	"time"
+
+	"github.com/kjanat/chatlogger-api-go/internal/domain"
+	"gorm.io/gorm"
+)
+
+// AnalyticsRepo implements the domain.AnalyticsRepository interface.
+type AnalyticsRepo struct {
+	db *Database
+}
+
+// NewAnalyticsRepository creates a new analytics repository.
+func NewAnalyticsRepository(db *Database) domain.AnalyticsRepository {
+	return &AnalyticsRepo{db: db}
+}
+
+// timeSeriesSQL buckets the rollups of an organization. Buckets are generated
+// as local timestamps of the timezone, so they follow its calendar across
+// daylight saving time changes, and converted back to instants. %s is the
+// rollup table read.
+const timeSeriesSQL = `
+WITH buckets AS (
+	SELECT local_start
+	FROM generate_series(
+		date_trunc(CAST(@bucket AS text), CAST(@start AS timestamptz) AT TIME ZONE CAST(@tz AS text)),
+		CAST(@end AS timestamptz) AT TIME ZONE CAST(@tz AS text),
+		CAST(@interval AS interval)
+	) AS local_start
+),
+rollup_stats AS (
+	SELECT date_trunc(CAST(@bucket AS text), rollups.bucket_start AT TIME ZONE CAST(@tz AS text)) AS local_start,
+		SUM(rollups.chats)::bigint AS chats,
+		SUM(rollups.user_messages)::bigint AS user_messages,
+		SUM(rollups.assistant_messages)::bigint AS assistant_messages,
+		SUM(rollups.system_messages)::bigint AS system_messages,
+		SUM(rollups.tokens)::bigint AS tokens,
+		SUM(rollups.response_time_count)::bigint AS response_count,
+		SUM(rollups.response_time_sum) AS response_sum
+	FROM %s AS rollups
+	WHERE rollups.organization_id = @org AND rollups.bucket_start >= @from AND rollups.bucket_start <= @end
+	GROUP BY 1
+)
+SELECT buckets.local_start AT TIME ZONE CAST(@tz AS text) AS start,
+	COALESCE(rollup_stats.chats, 0) AS chats,
+	COALESCE(rollup_stats.user_messages, 0) AS user_messages,
+	COALESCE(rollup_stats.assistant_messages, 0) AS assistant_messages,
+	COALESCE(rollup_stats.system_messages, 0) AS system_messages,
+	COALESCE(rollup_stats.tokens, 0) AS tokens,
+	COALESCE(rollup_stats.response_count, 0) AS response_count,
+	COALESCE(rollup_stats.response_sum, 0) AS response_sum
+FROM buckets
+LEFT JOIN rollup_stats ON rollup_stats.local_start = buckets.local_start
+ORDER BY buckets.local_start`
+
+// timeSeriesRow is a row of timeSeriesSQL.
+type timeSeriesRow struct {
+	Start             time.Time
+	Chats             int64
+	UserMessages      int64
+	AssistantMessages int64
+	SystemMessages    int64
+	Tokens            int64
+	ResponseCount     int64
+	ResponseSum       float64
+}
+
+// histogramRow is the response time histogram of a time series bucket.
+type histogramRow struct {
+	Start     time.Time
+	Histogram string // JSON array of counts
+}
+
+// GetTimeSeries buckets the chats, messages, tokens and response times of an
+// organization. Every bucket of the date range is returned, including empty
+// ones. The activity is read from the analytics rollups, so the date range is
+// widened to whole hours, or whole UTC days when the daily rollups suffice,
+// and response time percentiles are estimated from histograms. Activity in
+// timezones with a partial-hour offset is attributed by the UTC hour.
+func (r *AnalyticsRepo) GetTimeSeries(orgID uint64, query *domain.TimeSeriesQuery) ([]domain.TimeSeriesPoint, error) {
+	loc, err := query.Location()
+	if err != nil {
+		return nil, err
+	}
+
+	// Daily rollups are aligned to UTC days, so they only serve UTC calendars
+	table, grain := "analytics_hourly_rollups", time.Hour
+	if query.Bucket != domain.TimeBucketHour && loc == time.UTC {
+		table, grain = "analytics_daily_rollups", 24*time.Hour
+	}
+
+	params := map[string]any{
+		"org":      orgID,
+		"start":    query.Start,
+		"from":     query.Start.UTC().Truncate(grain),
+		"end":      query.End,
+		"bucket":   string(query.Bucket),
+		"interval": query.Bucket.Interval(),
+		"tz":       query.Timezone,
+	}
+
+	var rows []timeSeriesRow
+	if err := r.db.Raw(fmt.Sprintf(timeSeriesSQL, table), params).Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+
+	var histogramRows []histogramRow
+	err = r.db.Raw("SELECT histograms.bucket_start AT TIME ZONE CAST(@tz AS text) AS start, histograms.histogram "+
+		"FROM ("+sumHistogramsSQL(
+		"date_trunc(CAST(@bucket AS text), rollups.bucket_start AT TIME ZONE CAST(@tz AS text))",
+		table,
+		"rollups.organization_id = @org AND rollups.bucket_start >= @from AND rollups.bucket_start <= @end",
+	)+") AS histograms", params).Scan(&histogramRows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	histograms := make(map[int64]domain.ResponseTimeHistogram, len(histogramRows))
+	for _, row := range histogramRows {
+		var histogram domain.ResponseTimeHistogram
+		if err := json.Unmarshal([]byte(row.Histogram), &histogram); err != nil {
+			return nil, fmt.Errorf("invalid response time histogram: %w", err)
+		}
+		histograms[row.Start.Unix()] = histogram
+	}
+
+	points := make([]domain.TimeSeriesPoint, len(rows))
+	for i, row := range rows {
+		histogram := histograms[row.Start.Unix()]
+
+		responseTime := domain.ResponseTimeStats{
+			Count: row.ResponseCount,
+			P50:   histogram.Percentile(0.5),
+			P90:   histogram.Percentile(0.9),
+			P95:   histogram.Percentile(0.95),
+			P99:   histogram.Percentile(0.99),
+		}
+		if row.ResponseCount > 0 {
+			avg := row.ResponseSum / float64(row.ResponseCount)
+			responseTime.Avg = &avg
+		}
+
+		points[i] = domain.TimeSeriesPoint{
+			Start:    row.Start.In(loc),
+			Chats:    row.Chats,
+			Messages: row.UserMessages + row.AssistantMessages + row.SystemMessages,
+			MessagesByRole: map[domain.MessageRole]int64{
+				domain.MessageRoleUser:      row.UserMessages,
+				domain.MessageRoleAssistant: row.AssistantMessages,
+				domain.MessageRoleSystem:    row.SystemMessages,
+			},
+			Tokens:       row.Tokens,
+			ResponseTime: responseTime,
+		}
+	}
+
+	return points, nil
+}
+
+// chatRating is the user rating of a chat, NULL when missing or not a number.
+const chatRating = "CASE WHEN jsonb_typeof(chats.metadata->'user_rating') = 'number' " +
+	"THEN (chats.metadata->>'user_rating')::numeric::int END"
+
+// qualityKeys are the SQL expressions of the quality dimensions.
+var qualityKeys = map[domain.QualityDimension]string{
+	domain.QualityByCountry:  "COALESCE(chats.metadata->>'country_code', '')",
+	domain.QualityByLanguage: "COALESCE(chats.metadata->>'language_code', '')",
+	domain.QualityByTag:      "COALESCE(chat_tag, '')",
+}
+
+// qualityRow is a row of the counts of a quality report.
+type qualityRow struct {
+	Key            string
+	Chats          int64
+	RatedChats     int64
+	SatisfiedChats int64
+	EscalatedChats int64
+	ForwardedChats int64
+	AvgRating      *float64
+}
+
+// qualityBreakdownRow counts the chats with a value of a rating, sentiment or category.
+type qualityBreakdownRow struct {
+	Key   string
+	Kind  string
+	Value string
+	Chats int64
+}
+
+// GetQualityReport summarizes the ratings, sentiment, escalations and question
+// categories of an organization's chats, in total and per value of the
+// breakdown dimension.
+func (r *AnalyticsRepo) GetQualityReport(orgID uint64, query *domain.QualityQuery) (*domain.QualityReport, error) {
+	totals, err := r.qualityStats(orgID, query, "")
+	if err != nil {
+		return nil, err
+	}
+
+	report := &domain.QualityReport{Start: query.Start, End: query.End, GroupBy: query.GroupBy}
+	if stats, ok := totals[""]; ok {
+		report.Totals = *stats
+	} else {
+		report.Totals = *newQualityStats()
+	}
+
+	if query.GroupBy == "" {
+		return report, nil
+	}
+
+	groups, err := r.qualityStats(orgID, query, query.GroupBy)
+	if err != nil {
+		return nil, err
+	}
+
+	report.Groups = make([]domain.QualityGroup, 0, len(groups))
+	for key, stats := range groups {
+		report.Groups = append(report.Groups, domain.QualityGroup{Key: key, QualityStats: *stats})
+	}
+	slices.SortFunc(report.Groups, func(a, b domain.QualityGroup) int {
+		return cmp.Or(cmp.Compare(b.Chats, a.Chats), cmp.Compare(a.Key, b.Key))
+	})
+
+	return report, nil
+}
+
+// qualityStats computes the quality of the chats selected by query per value
+// of the dimension, or of all of them under the empty key without a dimension.
+func (r *AnalyticsRepo) qualityStats(
+	orgID uint64,
+	query *domain.QualityQuery,
+	dimension domain.QualityDimension,
+) (map[string]*domain.QualityStats, error) {
+	key := "''"
+	if dimension != "" {
+		key = qualityKeys[dimension]
+	}
+
+	var rows []qualityRow
+	err := r.qualityChats(orgID, query, dimension).
+		Select(key+" AS key, "+
+			"COUNT(*) AS chats, "+
+			"COUNT("+chatRating+") AS rated_chats, "+
+			"COUNT(*) FILTER (WHERE "+chatRating+" >= ?) AS satisfied_chats, "+
+			"COUNT(*) FILTER (WHERE chats.metadata->'is_escalated' = 'true'::jsonb) AS escalated_chats, "+
+			"COUNT(*) FILTER (WHERE chats.metadata->'is_forwarded_to_hr' = 'true'::jsonb) AS forwarded_chats, "+
+			"AVG("+chatRating+")::float8 AS avg_rating", domain.SatisfiedRating).
+		Group("key").
+		Scan(&rows).
+		Error
+	if err != nil {
+		return nil, err
+	}
+
+	var breakdown []qualityBreakdownRow
+	err = r.qualityChats(orgID, query, dimension).
+		Select(key + " AS key, breakdown.kind, breakdown.value, COUNT(*) AS chats").
+		Joins("CROSS JOIN LATERAL (VALUES " +
+			"('rating', (" + chatRating + ")::text), " +
+			"('sentiment', COALESCE(NULLIF(chats.metadata->>'sentiment', ''), 'unknown')), " +
+			"('category', NULLIF(chats.metadata->>'question_category', ''))" +
+			") AS breakdown(kind, value)").
+		Where("breakdown.value IS NOT NULL").
+		Group("key, breakdown.kind, breakdown.value").
+		Scan(&breakdown).
+		Error
+	if err != nil {
+		return nil, err
+	}
+
+	result := make(map[string]*domain.QualityStats, len(rows))
+	for _, row := range rows {
+		stats := newQualityStats()
+		stats.Chats = row.Chats
+		stats.RatedChats = row.RatedChats
+		stats.AvgRating = row.AvgRating
+		stats.EscalatedChats = row.EscalatedChats
+		stats.ForwardedToHRChats = row.ForwardedChats
+		if row.RatedChats > 0 {
+			stats.CSAT = ratio(row.SatisfiedChats, row.RatedChats)
+		}
+		if row.Chats > 0 {
+			stats.EscalationRate = ratio(row.EscalatedChats, row.Chats)
+			stats.HRForwardRate = ratio(row.ForwardedChats, row.Chats)
+		}
+		result[row.Key] = stats
+	}
+
+	for _, row := range breakdown {
+		stats, ok := result[row.Key]
+		if !ok {
+			continue
+		}
+
+		switch row.Kind {
+		case "rating":
+			if rating, err := strconv.Atoi(row.Value); err == nil {
+				stats.RatingDistribution[rating] = row.Chats
+			}
+		case "sentiment":
+			stats.Sentiment[row.Value] = row.Chats
+		case "category":
+			stats.TopCategories = append(stats.TopCategories, domain.CategoryCount{Category: row.Value, Chats: row.Chats})
+		}
+	}
+
+	for _, stats := range result {
+		slices.SortFunc(stats.TopCategories, func(a, b domain.CategoryCount) int {
+			return cmp.Or(cmp.Compare(b.Chats, a.Chats), cmp.Compare(a.Category, b.Category))
+		})
+		if len(stats.TopCategories) > query.TopCategories {
+			stats.TopCategories = stats.TopCategories[:query.TopCategories]
+		}
+	}
+
+	return result, nil
+}
+
+// qualityChats selects the chats of a quality report. Breaking down by tag
+// joins the tags of the chats, keeping untagged chats with a NULL tag.
+func (r *AnalyticsRepo) qualityChats(orgID uint64, query *domain.QualityQuery, dimension domain.QualityDimension) *gorm.DB {
+	chats := applyChatFilter(r.db.Model(&domain.Chat{}).Where("chats.organization_id = ?", orgID), &domain.ChatFilter{
+		CreatedFrom:  &query.Start,
+		CreatedTo:    &query.End,
+		CountryCode:  query.CountryCode,
+		LanguageCode: query.LanguageCode,
+		Tags:         query.Tags,
+	})
+
+	if dimension == domain.QualityByTag {
+		chats = chats.Joins("LEFT JOIN LATERAL jsonb_array_elements_text(" + chatTagsArray + ") AS chat_tag ON true")
+	}
+
+	return chats
+}
+
+// newQualityStats creates quality stats with empty distributions.
+func newQualityStats() *domain.QualityStats {
+	return &domain.QualityStats{
+		RatingDistribution: map[int]int64{},
+		Sentiment:          map[string]int64{},
+		TopCategories:      []domain.CategoryCount{},
+	}
+}
+
+// ratio divides two counts.
+func ratio(part, total int64) *float64 {
+	r := float64(part) / float64(total)
+	return &r
+}
+
+// latencyKeys are the SQL expressions of the latency dimensions.
+var latencyKeys = map[domain.LatencyDimension]string{
+	domain.LatencyByTag:    "COALESCE(chat_tag, '')",
+	domain.LatencyByAPIKey: "COALESCE(chats.api_key_id::text, '')",
+}
+
+// latencyStatsSQL aggregates the response times selected by latencyResponses.
+// Its argument is the SLA.
+const latencyStatsSQL = "COUNT(*) AS responses, AVG(response_time) AS avg, " +
+	"percentile_cont(0.5) WITHIN GROUP (ORDER BY response_time) AS p50, " +
+	"percentile_cont(0.9) WITHIN GROUP (ORDER BY response_time) AS p90, " +
+	"percentile_cont(0.99) WITHIN GROUP (ORDER BY response_time) AS p99, " +
+	"COUNT(*) FILTER (WHERE response_time > ?) AS breaches"
+
+// latencyPointsSQL joins the latency stats of the buckets with data onto every
+// bucket of the date range, generated as in timeSeriesSQL.
+const latencyPointsSQL = `
+WITH buckets AS (
+	SELECT local_start
+	FROM generate_series(
+		date_trunc(CAST(@bucket AS text), CAST(@start AS timestamptz) AT TIME ZONE CAST(@tz AS text)),
+		CAST(@end AS timestamptz) AT TIME ZONE CAST(@tz AS text),
+		CAST(@interval AS interval)
+	) AS local_start
+)
+SELECT buckets.local_start AT TIME ZONE CAST(@tz AS text) AS start,
+	COALESCE(stats.responses, 0) AS responses,
+	stats.avg, stats.p50, stats.p90, stats.p99,
+	COALESCE(stats.breaches, 0) AS breaches
+FROM buckets
+LEFT JOIN (@stats) AS stats ON stats.local_start = buckets.local_start
+ORDER BY buckets.local_start`
+
+// latencyRow is a row of the latency stats of a report.
+type latencyRow struct {
+	Key       string
+	Label     string
+	Start     time.Time
+	Responses int64
+	Avg       *float64
+	P50       *float64
+	P90       *float64
+	P99       *float64
+	Breaches  int64
+}
+
+// GetLatencyReport computes the percentiles of the assistant response times
+// of an organization, in total, per bucket and per value of the breakdown
+// dimension. Unlike time series, latency reports read the messages, as the
+// rollups carry neither tags nor API keys.
+func (r *AnalyticsRepo) GetLatencyReport(
+	orgID uint64,
+	query *domain.LatencyQuery,
+	sla float64,
+) (*domain.LatencyReport, error) {
+	loc, err := query.Location()
+	if err != nil {
+		return nil, err
+	}
+
+	var totals latencyRow
+	if err := r.latencyResponses(orgID, query, "").Select(latencyStatsSQL, sla).Scan(&totals).Error; err != nil {
+		return nil, err
+	}
+
+	var points []latencyRow
+	err = r.db.Raw(latencyPointsSQL, map[string]any{
+		"start":    query.Start,
+		"end":      query.End,
+		"bucket":   string(query.Bucket),
+		"interval": query.Bucket.Interval(),
+		"tz":       query.Timezone,
+		"stats":    r.latencyResponses(orgID, query, "").Select("local_start, "+latencyStatsSQL, sla).Group("local_start"),
+	}).Scan(&points).Error
+	if err != nil {
+		return nil, err
+	}
+
+	report := &domain.LatencyReport{
+		Bucket:   query.Bucket,
+		Timezone: query.Timezone,
+		Start:    query.Start.In(loc),
+		End:      query.End.In(loc),
+		GroupBy:  query.GroupBy,
+		Totals:   newLatencyStats(totals, sla),
+		Points:   make([]domain.LatencyPoint, len(points)),
+	}
+	if sla > 0 {
+		report.SLA = &sla
+	}
+
+	for i, row := range points {
+		report.Points[i] = domain.LatencyPoint{Start: row.Start.In(loc), LatencyStats: newLatencyStats(row, sla)}
+	}
+
+	if query.GroupBy == "" {
+		return report, nil
+	}
+
+	var groups []latencyRow
+	err = r.latencyResponses(orgID, query, query.GroupBy).
+		Select("key, label, "+latencyStatsSQL, sla).
+		Group("key, label").
+		Scan(&groups).
+		Error
+	if err != nil {
+		return nil, err
+	}
+
+	report.Groups = make([]domain.LatencyGroup, len(groups))
+	for i, row := range groups {
+		report.Groups[i] = domain.LatencyGroup{Key: row.Key, Label: row.Label, LatencyStats: newLatencyStats(row, sla)}
+	}
+	slices.SortFunc(report.Groups, func(a, b domain.LatencyGroup) int {
+		return cmp.Or(cmp.Compare(b.Responses, a.Responses), cmp.Compare(a.Key, b.Key))
+	})
+
+	return report, nil
+}
+
+// latencyResponses selects the response times of the assistant messages of a
+// latency report with their local bucket start and the key of the dimension.
+// Breaking down by tag joins the tags of the chats, keeping untagged chats
+// with a NULL tag.
+func (r *AnalyticsRepo) latencyResponses(
+	orgID uint64,
+	query *domain.LatencyQuery,
+	dimension domain.LatencyDimension,
+) *gorm.DB {
+	key, label := "''", "''"
+	if dimension != "" {
+		key = latencyKeys[dimension]
+	}
+	if dimension == domain.LatencyByAPIKey {
+		label = "COALESCE(api_keys.label, '')"
+	}
+
+	responses := r.db.Model(&domain.Message{}).
+		Select(messageNumber("response_time")+" AS response_time, "+key+" AS key, "+label+" AS label, "+
+			"date_trunc(CAST(? AS text), messages.created_at AT TIME ZONE CAST(? AS text)) AS local_start",
+			string(query.Bucket), query.Timezone).
+		Joins("JOIN chats ON chats.id = messages.chat_id").
+		Where("chats.organization_id = ? AND messages.role = ?", orgID, domain.MessageRoleAssistant).
+		Where("messages.created_at >= ? AND messages.created_at <= ?", query.Start, query.End)
+	responses = applyChatFilter(responses, &domain.ChatFilter{Tags: query.Tags})
+
+	switch dimension {
+	case domain.LatencyByTag:
+		responses = responses.Joins("LEFT JOIN LATERAL jsonb_array_elements_text(" + chatTagsArray + ") AS chat_tag ON true")
+	case domain.LatencyByAPIKey:
+		responses = responses.Joins("LEFT JOIN api_keys ON api_keys.id = chats.api_key_id")
+	}
+
+	return r.db.Table("(?) AS responses", responses).Where("responses.response_time IS NOT NULL")
+}
+
+// newLatencyStats converts a latency row, leaving out the SLA fields without an SLA.
+func newLatencyStats(row latencyRow, sla float64) domain.LatencyStats {
+	stats := domain.LatencyStats{
+		Responses: row.Responses,
+		Avg:       row.Avg,
+		P50:       row.P50,
+		P90:       row.P90,
+		P99:       row.P99,
+	}
+
+	if sla > 0 {
+		stats.SLABreaches = &row.Breaches
+		if row.Responses > 0 {
+			stats.SLABreachRate = ratio(row.Breaches, row.Responses)
+		}
+	}
+
+	return stats
+}
+
+// chatAvgResponseTime is the average response time of the chat metadata in
+// milliseconds, NULL when missing or not a number.
+const chatAvgResponseTime = "CASE WHEN jsonb_typeof(chats.metadata->'avg_response_time') = 'number' " +
+	"THEN (chats.metadata->>'avg_response_time')::float8 * 1000 END"
+
+// slowChatRow is a row of the slowest chats.
+type slowChatRow struct {
+	ChatID          uint64
+	Title           string
+	APIKeyID        *uint64
+	CreatedAt       time.Time
+	Responses       int64
+	AvgResponseTime float64
+	MaxResponseTime *float64
+	Breaches        int64
+}
+
+// GetSlowestChats lists the chats of an organization with the slowest average
+// assistant response time, falling back to the average response time of the
+// chat metadata for chats whose messages report none.
+func (r *AnalyticsRepo) GetSlowestChats(orgID uint64, query *domain.SlowChatQuery, sla float64) ([]domain.SlowChat, error) {
+	responseTime := messageNumber("response_time")
+	avgResponseTime := "COALESCE(AVG(" + responseTime + "), " + chatAvgResponseTime + ")"
+
+	var rows []slowChatRow
+	err := applyChatFilter(r.db.Model(&domain.Chat{}).Where("chats.organization_id = ?", orgID), &domain.ChatFilter{
+		CreatedFrom: &query.Start,
+		CreatedTo:   &query.End,
+		Tags:        query.Tags,
+	}).
+		Select("chats.id AS chat_id, chats.title, chats.api_key_id, chats.created_at, "+
+			"COUNT("+responseTime+") AS responses, "+
+			avgResponseTime+" AS avg_response_time, "+
+			"MAX("+responseTime+") AS max_response_time, "+
+			"COUNT(*) FILTER (WHERE "+responseTime+" > ?) AS breaches", sla).
+		Joins("LEFT JOIN messages ON messages.chat_id = chats.id AND messages.role = ?", domain.MessageRoleAssistant).
+		Group("chats.id").
+		Having(avgResponseTime + " IS NOT NULL").
+		Order("avg_response_time DESC, chats.id").
+		Limit(query.Limit).
+		Scan(&rows).
+		Error
+	if err != nil {
+		return nil, err
+	}
+
+	chats := make([]domain.SlowChat, len(rows))
+	for i, row := range rows {
+		chats[i] = domain.SlowChat{
+			ChatID:          row.ChatID,
+			Title:           row.Title,
+			APIKeyID:        row.APIKeyID,
+			CreatedAt:       row.CreatedAt,
+			Responses:       row.Responses,
+			AvgResponseTime: row.AvgResponseTime,
+			MaxResponseTime: row.MaxResponseTime,
+		}
+		if sla > 0 {
+			chats[i].SLABreaches = &rows[i].Breaches
+		}
+	}
+
+	return chats, nil
+}
+
+// GetUsage counts the messages and tokens of an organization per value of the
+// breakdown dimension, model and provider. Messages reporting no tokens are
+// left out.
+func (r *AnalyticsRepo) GetUsage(orgID uint64, query *domain.UsageQuery) ([]domain.UsageCount, error) {
+	promptTokens := "COALESCE(" + messageNumber("prompt_tokens") + ", 0)"
+	completionTokens := "COALESCE(" + messageNumber("completion_tokens") + ", 0)"
+
+	messages := r.db.Model(&domain.Message{}).
+		Joins("JOIN chats ON chats.id = messages.chat_id").
+		Where("chats.organization_id = ?", orgID).
+		Where("messages.created_at >= ? AND messages.created_at <= ?", query.Start, query.End)
+
+	var key, label string
+	var keyArgs []any
+	switch query.GroupBy {
+	case domain.UsageByDay:
+		key, label = "to_char(messages.created_at AT TIME ZONE CAST(? AS text), 'YYYY-MM-DD')", "''"
+		keyArgs = append(keyArgs, query.Timezone)
+	case domain.UsageByModel:
+		key, label = "COALESCE(messages.metadata->>'model', '')", "COALESCE(messages.metadata->>'provider', '')"
+	case domain.UsageByUser:
+		key, label = "COALESCE(chats.user_id::text, '')", "COALESCE(users.email, '')"
+		messages = messages.Joins("LEFT JOIN users ON users.id = chats.user_id")
+	case domain.UsageByAPIKey:
+		key, label = "COALESCE(chats.api_key_id::text, '')", "COALESCE(api_keys.label, '')"
+		messages = messages.Joins("LEFT JOIN api_keys ON api_keys.id = chats.api_key_id")
+	}
+
+	messages = messages.Select(key+" AS key, "+label+" AS label, "+
+		"COALESCE(messages.metadata->>'provider', '') AS provider, "+
+		"COALESCE(messages.metadata->>'model', '') AS model, "+
+		promptTokens+" AS prompt_tokens, "+
+		completionTokens+" AS completion_tokens, "+
+		"COALESCE("+messageNumber("cached_tokens")+", 0) AS cached_tokens, "+
+		messageTokens+" AS total_tokens",
+		keyArgs...)
+
+	var counts []domain.UsageCount
+	err := r.db.Table("(?) AS token_usage", messages).
+		Select("key, label, provider, model, COUNT(*) AS messages, " +
+			"SUM(prompt_tokens)::bigint AS prompt_tokens, " +
+			"SUM(completion_tokens)::bigint AS completion_tokens, " +
+			"SUM(cached_tokens)::bigint AS cached_tokens, " +
+			"SUM(total_tokens)::bigint AS total_tokens").
+		Where("token_usage.total_tokens > 0").
+		Group("key, label, provider, model").
+		Scan(&counts).
+		Error
+	if err != nil {
+		return nil, err
+	}
+
+	return counts, nil
+}
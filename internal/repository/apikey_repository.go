@@ -82,3 +82,8 @@ func (r *APIKeyRepo) Revoke(id uint64) error {
 func (r *APIKeyRepo) Delete(id uint64) error {
 	return r.db.Delete(&domain.APIKey{}, id).Error
 }
+
+// TouchLastUsed records the use of an API key at usedAt.
+func (r *APIKeyRepo) TouchLastUsed(id uint64, usedAt time.Time) error {
+	return r.db.Model(&domain.APIKey{}).Where("id = ?", id).Update("last_used_at", usedAt).Error
+}
@@ -1,7 +1,10 @@
 package repository
 
 import (
+	"encoding/json"
 	"errors"
+	"fmt"
+	"strings"
 	"time"
 
 	"github.com/kjanat/chatlogger-api-go/internal/domain"
@@ -39,19 +42,110 @@ func (r *ChatRepo) FindByID(id uint64) (*domain.Chat, error) {
 	return &chat, nil
 }
 
-// FindByOrganizationID finds chats by organization ID with pagination.
-func (r *ChatRepo) FindByOrganizationID(orgID uint64, limit, offset int) ([]domain.Chat, error) {
+// chatSortKeys maps the chat sort fields to their SQL expressions and types.
+var chatSortKeys = map[domain.ChatSortField]struct{ column, sqlType string }{
+	domain.ChatSortCreatedAt:    {"chats.created_at", "timestamptz"},
+	domain.ChatSortUpdatedAt:    {"chats.updated_at", "timestamptz"},
+	domain.ChatSortTitle:        {"LOWER(chats.title)", "text"},
+	domain.ChatSortUserRating:   {"(chats.metadata->>'user_rating')::int", "integer"},
+	domain.ChatSortTokenCount:   {"COALESCE((chats.metadata->>'token_count')::bigint, 0)", "bigint"},
+	domain.ChatSortMessageCount: {"(SELECT COUNT(*) FROM messages WHERE messages.chat_id = chats.id)", "bigint"},
+}
+
+// chatRow is a chat with the text of its sort key, which its cursor carries.
+type chatRow struct {
+	domain.Chat
+	SortKey *string
+}
+
+// FindByOrganizationID finds a page of the chats of an organization matching
+// filter, ordered by sort. Chats without a value for the sort key come last.
+func (r *ChatRepo) FindByOrganizationID(
+	orgID uint64,
+	filter *domain.ChatFilter,
+	sort *domain.ChatSort,
+	pageRequest *domain.PageRequest,
+) (*domain.Page[domain.Chat], error) {
+	if sort == nil {
+		sort = &domain.DefaultChatSort
+	}
+
+	sortKey, ok := chatSortKeys[sort.Field]
+	if !ok {
+		return nil, fmt.Errorf("unknown sort field %q", sort.Field)
+	}
+
+	direction := "asc"
+	if sort.Descending {
+		direction = "desc"
+	}
+
+	k := keyset{
+		name:       string(sort.Field) + ":" + direction,
+		idColumn:   "chats.id",
+		column:     sortKey.column,
+		sqlType:    sortKey.sqlType,
+		descending: sort.Descending,
+	}
+
+	query, err := k.paginate(
+		applyChatFilter(r.db.Model(&domain.Chat{}).Where("chats.organization_id = ?", orgID), filter),
+		pageRequest.Cursor,
+		pageRequest.Limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	var rows []chatRow
+	if err := query.Select("chats.*, (" + k.column + ")::text AS sort_key").Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+
+	result := page(k, rows, pageRequest.Cursor, pageRequest.Limit, func(row chatRow) (*string, uint64) {
+		return row.SortKey, row.ID
+	})
+
+	chats := make([]domain.Chat, len(result.Items))
+	for i, row := range result.Items {
+		chats[i] = row.Chat
+	}
+
+	return &domain.Page[domain.Chat]{
+		Items:      chats,
+		NextCursor: result.NextCursor,
+		PrevCursor: result.PrevCursor,
+	}, nil
+}
+
+// FindByOrganizationIDAfter finds up to limit chats of an organization matching
+// filter with an ID greater than afterID, ordered by ID. It is used for keyset
+// pagination where offsets would skip or repeat rows as chats are inserted.
+func (r *ChatRepo) FindByOrganizationIDAfter(
+	orgID, afterID uint64,
+	filter *domain.ChatFilter,
+	limit int,
+) ([]domain.Chat, error) {
 	var chats []domain.Chat
-	err := r.db.Where("organization_id = ?", orgID).
+	err := applyChatFilter(r.db.Where("chats.organization_id = ? AND chats.id > ?", orgID, afterID), filter).
+		Order("chats.id ASC").
 		Limit(limit).
-		Offset(offset).
-		Order("created_at DESC").
 		Find(&chats).
 		Error
 
 	return chats, err
 }
 
+// CountByOrganizationID counts the chats of an organization matching filter.
+func (r *ChatRepo) CountByOrganizationID(orgID uint64, filter *domain.ChatFilter) (int64, error) {
+	var count int64
+	err := applyChatFilter(r.db.Model(&domain.Chat{}).Where("chats.organization_id = ?", orgID), filter).
+		Count(&count).
+		Error
+
+	return count, err
+}
+
 // FindByUserID finds chats by user ID with pagination.
 func (r *ChatRepo) FindByUserID(userID uint64, limit, offset int) ([]domain.Chat, error) {
 	var chats []domain.Chat
@@ -85,21 +179,213 @@ func (r *ChatRepo) CountByOrgIDAndDateRange(orgID uint64, start, end time.Time)
 	return count, err
 }
 
-// GetTagStats gets statistics for tags in an organization.
-func (r *ChatRepo) GetTagStats(orgID uint64) (map[string]int64, error) {
-	// This is a simpler version. For a production implementation,
-	// we'd use jsonb functions in PostgreSQL to extract and count tags.
-	var chats []domain.Chat
+// chatTagsArray is the tags of a chat as a JSON array, empty when the tags are
+// missing or malformed, so they can be expanded with jsonb_array_elements_text.
+const chatTagsArray = "CASE WHEN jsonb_typeof(chats.tags) = 'array' THEN chats.tags ELSE '[]'::jsonb END"
+
+// mergedTagsExpr replaces the source tags, its first argument, with the target
+// tag, its second argument, in the tags of a chat. The position of the first
+// replaced tag is kept and duplicates are dropped.
+const mergedTagsExpr = `(SELECT COALESCE(jsonb_agg(merged.tag ORDER BY merged.position), '[]'::jsonb)
+	FROM (
+		SELECT CASE WHEN t.tag IN ? THEN ? ELSE t.tag END AS tag, MIN(t.position) AS position
+		FROM jsonb_array_elements_text(` + chatTagsArray + `) WITH ORDINALITY AS t(tag, position)
+		GROUP BY 1
+	) AS merged)`
 
-	err := r.db.Where("organization_id = ?", orgID).Find(&chats).Error
+// updatedTagsExpr appends the tags of its first argument and drops the tags of
+// its second argument, both JSON arrays, in the tags of a chat. Existing tags
+// keep their position and duplicates are dropped.
+const updatedTagsExpr = `(SELECT COALESCE(jsonb_agg(combined.tag ORDER BY combined.position), '[]'::jsonb)
+	FROM (
+		SELECT tags.tag, MIN(tags.position) AS position
+		FROM (
+			SELECT t.tag, t.position
+			FROM jsonb_array_elements_text(` + chatTagsArray + `) WITH ORDINALITY AS t(tag, position)
+			UNION ALL
+			SELECT a.tag, a.position + 2147483647
+			FROM jsonb_array_elements_text(?::jsonb) WITH ORDINALITY AS a(tag, position)
+		) AS tags
+		WHERE tags.tag NOT IN (SELECT jsonb_array_elements_text(?::jsonb))
+		GROUP BY tags.tag
+	) AS combined)`
+
+// GetTagStats counts the chats of an organization created in a date range per tag.
+func (r *ChatRepo) GetTagStats(orgID uint64, start, end time.Time) (map[string]int64, error) {
+	counts, err := r.countTags(r.db.Model(&domain.Chat{}).
+		Where("chats.organization_id = ? AND chats.created_at BETWEEN ? AND ?", orgID, start, end))
 	if err != nil {
 		return nil, err
 	}
 
-	// Count tags manually for now - in production, this would be done with SQL
-	tagStats := make(map[string]int64)
+	tagStats := make(map[string]int64, len(counts))
+	for _, count := range counts {
+		tagStats[count.Tag] = count.ChatCount
+	}
 
-	// Note: In a real implementation, we'd use a proper JSON library to parse the tags
-	// This is simplified for demo purposes
 	return tagStats, nil
 }
+
+// ListTags lists the distinct tags of an organization's chats with the number
+// of chats carrying them, most used first.
+func (r *ChatRepo) ListTags(orgID uint64) ([]domain.TagCount, error) {
+	return r.countTags(r.db.Model(&domain.Chat{}).Where("chats.organization_id = ?", orgID))
+}
+
+// countTags counts the chats selected by query per tag.
+func (r *ChatRepo) countTags(query *gorm.DB) ([]domain.TagCount, error) {
+	counts := []domain.TagCount{}
+	err := query.
+		Select("tag, COUNT(DISTINCT chats.id) AS chat_count").
+		Joins("CROSS JOIN LATERAL jsonb_array_elements_text(" + chatTagsArray + ") AS tag").
+		Group("tag").
+		Order("chat_count DESC, tag ASC").
+		Scan(&counts).
+		Error
+
+	return counts, err
+}
+
+// MergeTags replaces the source tags with the target tag on every chat of an
+// organization carrying one of them and returns the number of chats changed.
+func (r *ChatRepo) MergeTags(orgID uint64, sources []string, target string) (int64, error) {
+	result := r.db.Model(&domain.Chat{}).
+		Where("chats.organization_id = ?", orgID).
+		Where("EXISTS (SELECT 1 FROM jsonb_array_elements_text("+chatTagsArray+") AS tag WHERE tag IN ?)", sources).
+		Updates(map[string]any{
+			"tags":       gorm.Expr(mergedTagsExpr, sources, target),
+			"updated_at": time.Now(),
+		})
+
+	return result.RowsAffected, result.Error
+}
+
+// UpdateTags adds and removes tags on the selected chats of an organization.
+// Only chats missing a tag to add or carrying a tag to remove are changed; the
+// number of chats changed is returned.
+func (r *ChatRepo) UpdateTags(orgID uint64, update *domain.TagUpdate) (int64, error) {
+	query := applyChatFilter(r.db.Model(&domain.Chat{}).Where("chats.organization_id = ?", orgID), update.Selection.Filter)
+	if len(update.Selection.ChatIDs) > 0 {
+		query = query.Where("chats.id IN ?", update.Selection.ChatIDs)
+	}
+
+	add, remove := tagsJSON(update.Add), tagsJSON(update.Remove)
+
+	var changes []string
+	var args []any
+	if len(update.Add) > 0 {
+		changes = append(changes, "NOT ("+chatTagsArray+" @> ?::jsonb)")
+		args = append(args, add)
+	}
+	if len(update.Remove) > 0 {
+		changes = append(changes, "EXISTS (SELECT 1 FROM jsonb_array_elements_text("+chatTagsArray+") AS tag WHERE tag IN ?)")
+		args = append(args, update.Remove)
+	}
+
+	result := query.Where("("+strings.Join(changes, " OR ")+")", args...).
+		Updates(map[string]any{
+			"tags":       gorm.Expr(updatedTagsExpr, add, remove),
+			"updated_at": time.Now(),
+		})
+
+	return result.RowsAffected, result.Error
+}
+
+// tagsJSON encodes tags as a JSON array, empty for no tags.
+func tagsJSON(tags []string) string {
+	if len(tags) == 0 {
+		return "[]"
+	}
+
+	// Marshalling a string slice cannot fail
+	data, _ := json.Marshal(tags)
+
+	return string(data)
+}
+
+// applyChatFilter adds the conditions of a chat filter to a query on the chats table.
+func applyChatFilter(query *gorm.DB, filter *domain.ChatFilter) *gorm.DB {
+	if filter == nil {
+		return query
+	}
+
+	if filter.CreatedFrom != nil {
+		query = query.Where("chats.created_at >= ?", *filter.CreatedFrom)
+	}
+
+	if filter.CreatedTo != nil {
+		query = query.Where("chats.created_at <= ?", *filter.CreatedTo)
+	}
+
+	if filter.UpdatedFrom != nil {
+		query = query.Where("chats.updated_at >= ?", *filter.UpdatedFrom)
+	}
+
+	if filter.UpdatedTo != nil {
+		query = query.Where("chats.updated_at <= ?", *filter.UpdatedTo)
+	}
+
+	if len(filter.Tags) > 0 {
+		if filter.TagMatch == domain.TagMatchAll {
+			query = query.Where("chats.tags @> ?::jsonb", tagsJSON(filter.Tags))
+		} else {
+			query = query.Where(
+				"EXISTS (SELECT 1 FROM jsonb_array_elements_text("+chatTagsArray+") AS tag WHERE tag IN ?)",
+				filter.Tags,
+			)
+		}
+	}
+
+	if len(filter.ExcludeTags) > 0 {
+		query = query.Where(
+			"NOT EXISTS (SELECT 1 FROM jsonb_array_elements_text("+chatTagsArray+") AS tag WHERE tag IN ?)",
+			filter.ExcludeTags,
+		)
+	}
+
+	if filter.UserID != nil {
+		query = query.Where("chats.user_id = ?", *filter.UserID)
+	}
+
+	if filter.TitleContains != "" {
+		query = query.Where("chats.title ILIKE ?", "%"+escapeLike(filter.TitleContains)+"%")
+	}
+
+	if filter.IsEscalated != nil {
+		query = query.Where("COALESCE((chats.metadata->>'is_escalated')::boolean, false) = ?", *filter.IsEscalated)
+	}
+
+	if filter.CountryCode != "" {
+		query = query.Where("chats.metadata->>'country_code' = ?", filter.CountryCode)
+	}
+
+	if filter.LanguageCode != "" {
+		query = query.Where("chats.metadata->>'language_code' = ?", filter.LanguageCode)
+	}
+
+	if filter.Sentiment != "" {
+		query = query.Where("chats.metadata->>'sentiment' = ?", filter.Sentiment)
+	}
+
+	if filter.QuestionCategory != "" {
+		query = query.Where("chats.metadata->>'question_category' = ?", filter.QuestionCategory)
+	}
+
+	if filter.MinRating != nil {
+		query = query.Where("(chats.metadata->>'user_rating')::int >= ?", *filter.MinRating)
+	}
+
+	if filter.MaxRating != nil {
+		query = query.Where("(chats.metadata->>'user_rating')::int <= ?", *filter.MaxRating)
+	}
+
+	return query
+}
+
+// likeEscaper escapes the wildcards of LIKE patterns.
+var likeEscaper = strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`)
+
+// escapeLike escapes s for use as a literal in a LIKE pattern.
+func escapeLike(s string) string {
+	return likeEscaper.Replace(s)
+}
@@ -6,6 +6,7 @@ import (
 	"time"
 
 	"github.com/kjanat/chatlogger-api-go/internal/domain"
+	"github.com/kjanat/chatlogger-api-go/migrations"
 
 	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
@@ -82,10 +83,23 @@ func runMigrations(db *gorm.DB) error {
 			&domain.Chat{},
 			&domain.Message{},
 			&domain.Export{},
+			&domain.ExportSchedule{},
+			&domain.ExportDestination{},
+			&domain.ExportDelivery{},
+			&domain.UsageCounter{},
 		); err != nil {
 			return fmt.Errorf("failed to migrate database: %w", err)
 		}
 
+		// Full-text search relies on triggers, which GORM cannot migrate
+		if err := tx.Exec(migrations.MessageSearch).Error; err != nil {
+			return fmt.Errorf("failed to migrate message search: %w", err)
+		}
+
+		if err := tx.Exec(migrations.AnalyticsRollups).Error; err != nil {
+			return fmt.Errorf("failed to migrate analytics rollups: %w", err)
+		}
+
 		log.Println("Database migrations completed successfully")
 		return nil
 	})
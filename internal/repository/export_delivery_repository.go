@@ -0,0 +1,59 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/kjanat/chatlogger-api-go/internal/domain"
+
+	"gorm.io/gorm"
+)
+
+// ExportDeliveryRepository implements domain.ExportDeliveryRepository for database operations
+type ExportDeliveryRepository struct {
+	db *gorm.DB
+}
+
+// NewExportDeliveryRepository creates a new export delivery repository
+func NewExportDeliveryRepository(db *gorm.DB) *ExportDeliveryRepository {
+	return &ExportDeliveryRepository{db: db}
+}
+
+// Create adds a new export delivery to the database
+func (r *ExportDeliveryRepository) Create(delivery *domain.ExportDelivery) error {
+	return r.db.Create(delivery).Error
+}
+
+// GetByID retrieves an export delivery by its ID
+func (r *ExportDeliveryRepository) GetByID(id uint64) (*domain.ExportDelivery, error) {
+	var delivery domain.ExportDelivery
+	if err := r.db.First(&delivery, id).Error; err != nil {
+		return nil, err
+	}
+	return &delivery, nil
+}
+
+// GetByExportID retrieves the deliveries of an export
+func (r *ExportDeliveryRepository) GetByExportID(exportID uint64) ([]*domain.ExportDelivery, error) {
+	var deliveries []*domain.ExportDelivery
+	err := r.db.Where("export_id = ?", exportID).
+		Order("id ASC").
+		Find(&deliveries).Error
+	return deliveries, err
+}
+
+// RecordAttempt counts a delivery attempt and stores its outcome
+func (r *ExportDeliveryRepository) RecordAttempt(id uint64, status domain.ExportDeliveryStatus, errorMsg string) error {
+	now := time.Now()
+	updates := map[string]interface{}{
+		"status":     status,
+		"attempts":   gorm.Expr("attempts + 1"),
+		"last_error": errorMsg,
+		"updated_at": now,
+	}
+
+	if status == domain.ExportDeliveryDelivered {
+		updates["delivered_at"] = now
+	}
+
+	return r.db.Model(&domain.ExportDelivery{}).Where("id = ?", id).Updates(updates).Error
+}
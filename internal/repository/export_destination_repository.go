@@ -0,0 +1,50 @@
+package repository
+
+import (
+	"github.com/kjanat/chatlogger-api-go/internal/domain"
+
+	"gorm.io/gorm"
+)
+
+// ExportDestinationRepository implements domain.ExportDestinationRepository for database operations
+type ExportDestinationRepository struct {
+	db *gorm.DB
+}
+
+// NewExportDestinationRepository creates a new export destination repository
+func NewExportDestinationRepository(db *gorm.DB) *ExportDestinationRepository {
+	return &ExportDestinationRepository{db: db}
+}
+
+// Create adds a new export destination to the database
+func (r *ExportDestinationRepository) Create(destination *domain.ExportDestination) error {
+	return r.db.Create(destination).Error
+}
+
+// GetByID retrieves an export destination by its ID
+func (r *ExportDestinationRepository) GetByID(id uint64) (*domain.ExportDestination, error) {
+	var destination domain.ExportDestination
+	if err := r.db.First(&destination, id).Error; err != nil {
+		return nil, err
+	}
+	return &destination, nil
+}
+
+// GetByOrganizationID retrieves the export destinations of an organization
+func (r *ExportDestinationRepository) GetByOrganizationID(organizationID uint64) ([]*domain.ExportDestination, error) {
+	var destinations []*domain.ExportDestination
+	err := r.db.Where("organization_id = ?", organizationID).
+		Order("id ASC").
+		Find(&destinations).Error
+	return destinations, err
+}
+
+// Update saves all fields of an export destination
+func (r *ExportDestinationRepository) Update(destination *domain.ExportDestination) error {
+	return r.db.Save(destination).Error
+}
+
+// Delete removes an export destination
+func (r *ExportDestinationRepository) Delete(id uint64) error {
+	return r.db.Delete(&domain.ExportDestination{}, id).Error
+}
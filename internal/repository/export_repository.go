@@ -23,24 +23,46 @@ func (r *ExportRepository) Create(export *domain.Export) error {
 	return r.db.Create(export).Error
 }
 
-// GetByID retrieves an export by its ID
+// GetByID retrieves an export by its ID, including its deliveries
 func (r *ExportRepository) GetByID(id uint64) (*domain.Export, error) {
 	var export domain.Export
-	if err := r.db.First(&export, id).Error; err != nil {
+	if err := r.db.Preload("Deliveries").First(&export, id).Error; err != nil {
 		return nil, err
 	}
 	return &export, nil
 }
 
-// GetByOrganizationID retrieves exports for an organization
-func (r *ExportRepository) GetByOrganizationID(organizationID uint64, limit, offset int) ([]*domain.Export, error) {
+// exportKeyset orders exports newest first.
+var exportKeyset = keyset{name: "id:desc", idColumn: "exports.id", descending: true}
+
+// GetByOrganizationID retrieves a page of the exports of an organization, newest first
+func (r *ExportRepository) GetByOrganizationID(
+	organizationID uint64,
+	pageRequest *domain.PageRequest,
+) (*domain.Page[*domain.Export], error) {
+	query, err := exportKeyset.paginate(
+		r.db.Where("organization_id = ?", organizationID),
+		pageRequest.Cursor,
+		pageRequest.Limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+
 	var exports []*domain.Export
-	err := r.db.Where("organization_id = ?", organizationID).
-		Order("created_at DESC").
-		Limit(limit).
-		Offset(offset).
-		Find(&exports).Error
-	return exports, err
+	if err := query.Find(&exports).Error; err != nil {
+		return nil, err
+	}
+
+	return page(exportKeyset, exports, pageRequest.Cursor, pageRequest.Limit,
+		func(export *domain.Export) (*string, uint64) { return nil, export.ID }), nil
+}
+
+// CountByOrganizationID counts the exports of an organization
+func (r *ExportRepository) CountByOrganizationID(organizationID uint64) (int64, error) {
+	var count int64
+	err := r.db.Model(&domain.Export{}).Where("organization_id = ?", organizationID).Count(&count).Error
+	return count, err
 }
 
 // UpdateStatus updates the status of an export
@@ -59,10 +81,79 @@ func (r *ExportRepository) UpdateStatus(id uint64, status domain.ExportStatus, e
 		updates["error"] = errorMsg
 	}
 
-	return r.db.Model(&domain.Export{}).Where("id = ?", id).Updates(updates).Error
+	// Cancelled is final, so a worker finishing late cannot revive a cancelled export
+	return r.db.Model(&domain.Export{}).
+		Where("id = ? AND status <> ?", id, domain.ExportStatusCancelled).
+		Updates(updates).Error
+}
+
+// Cancel marks an export cancelled unless it already finished, reporting whether
+// it was cancelled. The status is checked in the update itself, so an export
+// completing concurrently is never turned into a cancelled one.
+func (r *ExportRepository) Cancel(id uint64) (bool, error) {
+	result := r.db.Model(&domain.Export{}).
+		Where("id = ? AND status IN ?", id, []domain.ExportStatus{domain.ExportStatusPending, domain.ExportStatusProcessing}).
+		Updates(map[string]interface{}{
+			"status":     domain.ExportStatusCancelled,
+			"updated_at": time.Now(),
+		})
+	if result.Error != nil {
+		return false, result.Error
+	}
+
+	return result.RowsAffected > 0, nil
 }
 
 // UpdateFilePath updates the file path of a completed export
 func (r *ExportRepository) UpdateFilePath(id uint64, filePath string) error {
 	return r.db.Model(&domain.Export{}).Where("id = ?", id).Update("file_path", filePath).Error
 }
+
+// UpdateProgress records how many chats of an export have been processed
+func (r *ExportRepository) UpdateProgress(id uint64, processedChats, totalChats int) error {
+	return r.db.Model(&domain.Export{}).Where("id = ?", id).Updates(map[string]interface{}{
+		"processed_chats": processedChats,
+		"total_chats":     totalChats,
+		"updated_at":      time.Now(),
+	}).Error
+}
+
+// Complete records the file of an export and the time after which it is
+// cleaned up, and marks the export completed, all in one update that skips
+// cancelled exports. It reports whether the export was completed.
+func (r *ExportRepository) Complete(id uint64, filePath string, expiresAt time.Time) (bool, error) {
+	now := time.Now()
+	result := r.db.Model(&domain.Export{}).
+		Where("id = ? AND status <> ?", id, domain.ExportStatusCancelled).
+		Updates(map[string]interface{}{
+			"status":       domain.ExportStatusCompleted,
+			"file_path":    filePath,
+			"expires_at":   expiresAt,
+			"completed_at": now,
+			"updated_at":   now,
+		})
+	if result.Error != nil {
+		return false, result.Error
+	}
+
+	return result.RowsAffected > 0, nil
+}
+
+// FindExpired retrieves completed exports whose expiry time has passed, oldest first
+func (r *ExportRepository) FindExpired(now time.Time, limit int) ([]*domain.Export, error) {
+	var exports []*domain.Export
+	err := r.db.Where("status = ? AND expires_at <= ?", domain.ExportStatusCompleted, now).
+		Order("expires_at ASC").
+		Limit(limit).
+		Find(&exports).Error
+	return exports, err
+}
+
+// MarkExpired marks an export as expired and clears its file path
+func (r *ExportRepository) MarkExpired(id uint64) error {
+	return r.db.Model(&domain.Export{}).Where("id = ?", id).Updates(map[string]interface{}{
+		"status":     domain.ExportStatusExpired,
+		"file_path":  "",
+		"updated_at": time.Now(),
+	}).Error
+}
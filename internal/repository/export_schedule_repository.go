@@ -0,0 +1,69 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/kjanat/chatlogger-api-go/internal/domain"
+
+	"gorm.io/gorm"
+)
+
+// ExportScheduleRepository implements domain.ExportScheduleRepository for database operations
+type ExportScheduleRepository struct {
+	db *gorm.DB
+}
+
+// NewExportScheduleRepository creates a new export schedule repository
+func NewExportScheduleRepository(db *gorm.DB) *ExportScheduleRepository {
+	return &ExportScheduleRepository{db: db}
+}
+
+// Create adds a new export schedule to the database
+func (r *ExportScheduleRepository) Create(schedule *domain.ExportSchedule) error {
+	return r.db.Create(schedule).Error
+}
+
+// GetByID retrieves an export schedule by its ID
+func (r *ExportScheduleRepository) GetByID(id uint64) (*domain.ExportSchedule, error) {
+	var schedule domain.ExportSchedule
+	if err := r.db.First(&schedule, id).Error; err != nil {
+		return nil, err
+	}
+	return &schedule, nil
+}
+
+// GetByOrganizationID retrieves the export schedules of an organization
+func (r *ExportScheduleRepository) GetByOrganizationID(organizationID uint64) ([]*domain.ExportSchedule, error) {
+	var schedules []*domain.ExportSchedule
+	err := r.db.Where("organization_id = ?", organizationID).
+		Order("id ASC").
+		Find(&schedules).Error
+	return schedules, err
+}
+
+// ListEnabled retrieves the enabled export schedules of all organizations
+func (r *ExportScheduleRepository) ListEnabled() ([]*domain.ExportSchedule, error) {
+	var schedules []*domain.ExportSchedule
+	err := r.db.Where("enabled = ?", true).
+		Order("id ASC").
+		Find(&schedules).Error
+	return schedules, err
+}
+
+// Update saves all fields of an export schedule
+func (r *ExportScheduleRepository) Update(schedule *domain.ExportSchedule) error {
+	return r.db.Save(schedule).Error
+}
+
+// Delete removes an export schedule
+func (r *ExportScheduleRepository) Delete(id uint64) error {
+	return r.db.Delete(&domain.ExportSchedule{}, id).Error
+}
+
+// UpdateLastRun records the latest run of an export schedule
+func (r *ExportScheduleRepository) UpdateLastRun(id uint64, runAt time.Time, exportID uint64) error {
+	return r.db.Model(&domain.ExportSchedule{}).Where("id = ?", id).Updates(map[string]interface{}{
+		"last_run_at":    runAt,
+		"last_export_id": exportID,
+	}).Error
+}
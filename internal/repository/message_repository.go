@@ -2,6 +2,8 @@ package repository
 
 import (
 	"errors"
+	"html"
+	"strings"
 	"time"
 
 	"github.com/kjanat/chatlogger-api-go/internal/domain"
@@ -47,6 +49,68 @@ func (r *MessageRepo) FindByChatID(chatID uint64) ([]domain.Message, error) {
 	return messages, err
 }
 
+// messageKeyset orders the messages of a chat chronologically.
+var messageKeyset = keyset{
+	name:     "created_at:asc",
+	idColumn: "messages.id",
+	column:   "messages.created_at",
+	sqlType:  "timestamptz",
+}
+
+// FindPageByChatID finds a page of the messages of a chat in chronological order.
+func (r *MessageRepo) FindPageByChatID(
+	chatID uint64,
+	pageRequest *domain.PageRequest,
+) (*domain.Page[domain.Message], error) {
+	query, err := messageKeyset.paginate(
+		r.db.Where("messages.chat_id = ?", chatID),
+		pageRequest.Cursor,
+		pageRequest.Limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	var messages []domain.Message
+	if err := query.Find(&messages).Error; err != nil {
+		return nil, err
+	}
+
+	return page(messageKeyset, messages, pageRequest.Cursor, pageRequest.Limit,
+		func(message domain.Message) (*string, uint64) {
+			key := message.CreatedAt.Format(time.RFC3339Nano)
+			return &key, message.ID
+		}), nil
+}
+
+// CountByChatID counts the messages of a chat.
+func (r *MessageRepo) CountByChatID(chatID uint64) (int64, error) {
+	var count int64
+	err := r.db.Model(&domain.Message{}).Where("chat_id = ?", chatID).Count(&count).Error
+
+	return count, err
+}
+
+// FindByChatIDs finds the messages of several chats at once, ordered by chat and
+// creation time. When roles is not empty, only messages with those roles are returned.
+func (r *MessageRepo) FindByChatIDs(chatIDs []uint64, roles []domain.MessageRole) ([]domain.Message, error) {
+	var messages []domain.Message
+	if len(chatIDs) == 0 {
+		return messages, nil
+	}
+
+	query := r.db.Where("chat_id IN ?", chatIDs)
+	if len(roles) > 0 {
+		query = query.Where("role IN ?", roles)
+	}
+
+	err := query.Order("chat_id ASC, created_at ASC, id ASC").
+		Find(&messages).
+		Error
+
+	return messages, err
+}
+
 // CountByOrgIDAndDateRange counts messages in a date range for an organization.
 func (r *MessageRepo) CountByOrgIDAndDateRange(orgID uint64, start, end time.Time) (int64, error) {
 	var count int64
@@ -84,3 +148,167 @@ func (r *MessageRepo) GetRoleStats(orgID uint64) (map[domain.MessageRole]int64,
 
 	return stats, nil
 }
+
+// searchTSQuery parses a search query with the text search configuration of
+// its language. It takes the language and the query as arguments.
+const searchTSQuery = "websearch_to_tsquery(chatlogger_search_config(?), ?)"
+
+// Placeholders ts_headline wraps the matching words in. Snippets are HTML-escaped
+// before the placeholders are replaced with <mark> tags, so the content cannot
+// inject markup. They are private-use characters, removed from the content first.
+const (
+	searchMarkStart = "\ue000"
+	searchMarkStop  = "\ue001"
+)
+
+// searchHeadlineOptions configures the snippets of search hits.
+const searchHeadlineOptions = `StartSel="` + searchMarkStart + `", StopSel="` + searchMarkStop +
+	`", MinWords=15, MaxWords=35, MaxFragments=2, FragmentDelimiter=" ... "`
+
+// searchSnippetMarks replaces the placeholders of an HTML-escaped snippet with <mark> tags.
+var searchSnippetMarks = strings.NewReplacer(searchMarkStart, "<mark>", searchMarkStop, "</mark>")
+
+// searchSnippet converts the ts_headline output of a hit into an HTML snippet.
+func searchSnippet(headline string) string {
+	return searchSnippetMarks.Replace(html.EscapeString(headline))
+}
+
+// Search finds the messages of an organization matching a full-text query and
+// groups them by chat. Chats are ordered by their best hit and paginated; each
+// chat carries its best hits with highlighted snippets.
+func (r *MessageRepo) Search(orgID uint64, search *domain.MessageSearchQuery) (*domain.MessageSearchResult, error) {
+	result := &domain.MessageSearchResult{Chats: []domain.ChatSearchResult{}}
+
+	if err := r.db.Table("(?) AS chat_hits", r.searchChatHits(orgID, search)).
+		Count(&result.TotalChats).
+		Error; err != nil {
+		return nil, err
+	}
+	if result.TotalChats == 0 {
+		return result, nil
+	}
+
+	var chats []struct {
+		ChatID    uint64
+		Title     string
+		Tags      string
+		Rank      float64
+		HitCount  int64
+		CreatedAt time.Time
+	}
+	err := r.db.Table("(?) AS chat_hits", r.searchChatHits(orgID, search)).
+		Select("chat_hits.chat_id, chat_hits.rank, chat_hits.hit_count, " +
+			"chats.title, COALESCE(chats.tags, '[]'::jsonb) AS tags, chats.created_at").
+		Joins("JOIN chats ON chats.id = chat_hits.chat_id").
+		Order("chat_hits.rank DESC, chat_hits.chat_id DESC").
+		Limit(search.Limit).
+		Offset(search.Offset).
+		Scan(&chats).
+		Error
+	if err != nil {
+		return nil, err
+	}
+	if len(chats) == 0 {
+		return result, nil
+	}
+
+	chatIDs := make([]uint64, len(chats))
+	positions := make(map[uint64]int, len(chats))
+	for i, row := range chats {
+		chat := domain.Chat{Tags: row.Tags}
+		tags, _ := chat.GetTags() // Malformed tags are returned as no tags
+		if tags == nil {
+			tags = []string{}
+		}
+
+		result.Chats = append(result.Chats, domain.ChatSearchResult{
+			ChatID:    row.ChatID,
+			Title:     row.Title,
+			Tags:      tags,
+			Rank:      row.Rank,
+			HitCount:  row.HitCount,
+			Hits:      []domain.MessageSearchHit{},
+			CreatedAt: row.CreatedAt,
+		})
+		chatIDs[i] = row.ChatID
+		positions[row.ChatID] = i
+	}
+
+	// Rank the hits within each chat, highlighting only those returned
+	ranked := r.db.Table("(?) AS hits", r.searchMatches(orgID, search).Where("messages.chat_id IN ?", chatIDs)).
+		Select("hits.*, ROW_NUMBER() OVER (PARTITION BY hits.chat_id ORDER BY hits.rank DESC, hits.id ASC) AS position")
+
+	var hits []struct {
+		ID        uint64
+		ChatID    uint64
+		Role      domain.MessageRole
+		Snippet   string
+		Rank      float64
+		CreatedAt time.Time
+	}
+	err = r.db.Table("(?) AS ranked", ranked).
+		Select("ranked.id, ranked.chat_id, ranked.role, ranked.rank, ranked.created_at, "+
+			"ts_headline(chatlogger_search_config(?), translate(ranked.content, ?, ''), "+searchTSQuery+", ?) AS snippet",
+			search.Language, searchMarkStart+searchMarkStop, search.Language, search.Query, searchHeadlineOptions).
+		Where("ranked.position <= ?", search.HitsPerChat).
+		Order("ranked.chat_id ASC, ranked.position ASC").
+		Scan(&hits).
+		Error
+	if err != nil {
+		return nil, err
+	}
+
+	for _, hit := range hits {
+		chat := &result.Chats[positions[hit.ChatID]]
+		chat.Hits = append(chat.Hits, domain.MessageSearchHit{
+			MessageID: hit.ID,
+			Role:      hit.Role,
+			Snippet:   searchSnippet(hit.Snippet),
+			Rank:      hit.Rank,
+			CreatedAt: hit.CreatedAt,
+		})
+	}
+
+	return result, nil
+}
+
+// searchChatHits returns a query selecting every chat with messages matching
+// search, with the rank of its best hit and its number of hits.
+func (r *MessageRepo) searchChatHits(orgID uint64, search *domain.MessageSearchQuery) *gorm.DB {
+	return r.db.Table("(?) AS hits", r.searchMatches(orgID, search)).
+		Select("hits.chat_id, MAX(hits.rank) AS rank, COUNT(*) AS hit_count").
+		Group("hits.chat_id")
+}
+
+// searchMatches returns a query selecting the messages of an organization
+// matching search with their rank. The GIN index on the search vector is used
+// because the query is parsed from constants.
+func (r *MessageRepo) searchMatches(orgID uint64, search *domain.MessageSearchQuery) *gorm.DB {
+	query := r.db.Model(&domain.Message{}).
+		Select("messages.id, messages.chat_id, messages.role, messages.content, messages.created_at, "+
+			"ts_rank_cd(messages.search_vector, "+searchTSQuery+") AS rank", search.Language, search.Query).
+		Joins("JOIN chats ON chats.id = messages.chat_id").
+		Where("chats.organization_id = ?", orgID).
+		Where("messages.search_vector @@ "+searchTSQuery, search.Language, search.Query)
+
+	if len(search.Roles) > 0 {
+		query = query.Where("messages.role IN ?", search.Roles)
+	}
+
+	if search.CreatedFrom != nil {
+		query = query.Where("messages.created_at >= ?", *search.CreatedFrom)
+	}
+
+	if search.CreatedTo != nil {
+		query = query.Where("messages.created_at <= ?", *search.CreatedTo)
+	}
+
+	if len(search.Tags) > 0 {
+		query = query.Where(
+			"EXISTS (SELECT 1 FROM jsonb_array_elements_text("+chatTagsArray+") AS tag WHERE tag IN ?)",
+			search.Tags,
+		)
+	}
+
+	return query
+}
@@ -0,0 +1,108 @@
+package repository
+
+import (
+	"fmt"
+	"slices"
+
+	"github.com/kjanat/chatlogger-api-go/internal/domain"
+
+	"gorm.io/gorm"
+)
+
+// keyset is the ordering of a keyset-paginated listing: a sort key with the
+// row ID breaking ties. Rows without a sort key come last.
+type keyset struct {
+	name       string // Identifies the ordering in cursors, e.g. "created_at:desc"
+	idColumn   string // Qualified ID column
+	column     string // SQL expression of the sort key; empty orders by ID only
+	sqlType    string // SQL type the key of a cursor is cast back to
+	descending bool
+}
+
+// paginate orders query by the keyset and restricts it to the rows after the
+// cursor, or before it for backward cursors. One row more than limit is
+// fetched, so page can tell whether another page follows.
+func (k keyset) paginate(query *gorm.DB, cursor *domain.Cursor, limit int) (*gorm.DB, error) {
+	backward := cursor != nil && cursor.Backward
+	if cursor != nil && cursor.Order != k.name {
+		return nil, domain.ErrInvalidCursor
+	}
+
+	// Backward pages are read in reverse and flipped by page
+	descending := k.descending != backward
+	direction, cmp := "ASC", ">"
+	if descending {
+		direction, cmp = "DESC", "<"
+	}
+
+	if k.column == "" {
+		if cursor != nil {
+			query = query.Where(fmt.Sprintf("%s %s ?", k.idColumn, cmp), cursor.ID)
+		}
+		return query.Order(fmt.Sprintf("%s %s", k.idColumn, direction)).Limit(limit + 1), nil
+	}
+
+	if cursor != nil {
+		key := fmt.Sprintf("CAST(? AS %s)", k.sqlType)
+		switch {
+		case cursor.Key != nil && !backward:
+			query = query.Where(fmt.Sprintf("(%[1]s %[2]s %[3]s OR (%[1]s = %[3]s AND %[4]s %[2]s ?) OR %[1]s IS NULL)",
+				k.column, cmp, key, k.idColumn), *cursor.Key, *cursor.Key, cursor.ID)
+		case cursor.Key != nil:
+			query = query.Where(fmt.Sprintf("(%[1]s %[2]s %[3]s OR (%[1]s = %[3]s AND %[4]s %[2]s ?))",
+				k.column, cmp, key, k.idColumn), *cursor.Key, *cursor.Key, cursor.ID)
+		case !backward:
+			query = query.Where(fmt.Sprintf("(%s IS NULL AND %s %s ?)", k.column, k.idColumn, cmp), cursor.ID)
+		default:
+			query = query.Where(fmt.Sprintf("(%[1]s IS NOT NULL OR %[2]s %[3]s ?)", k.column, k.idColumn, cmp), cursor.ID)
+		}
+	}
+
+	nulls := "LAST"
+	if backward {
+		nulls = "FIRST"
+	}
+	return query.
+		Order(fmt.Sprintf("%s %s NULLS %s, %s %s", k.column, direction, nulls, k.idColumn, direction)).
+		Limit(limit + 1), nil
+}
+
+// page trims the rows fetched by paginate to a page and creates the cursors of
+// the pages around it. position returns the sort key and ID of a row.
+func page[T any](
+	k keyset,
+	rows []T,
+	cursor *domain.Cursor,
+	limit int,
+	position func(row T) (*string, uint64),
+) *domain.Page[T] {
+	backward := cursor != nil && cursor.Backward
+	more := len(rows) > limit
+	if more {
+		rows = rows[:limit]
+	}
+	if backward {
+		slices.Reverse(rows)
+	}
+
+	result := &domain.Page[T]{Items: rows}
+	if len(rows) == 0 {
+		return result
+	}
+
+	newCursor := func(row T, backward bool) *domain.Cursor {
+		key, id := position(row)
+		return &domain.Cursor{Key: key, ID: id, Backward: backward, Order: k.name}
+	}
+
+	// Reading backward, the page we came from follows; reading forward from a
+	// cursor, the page we came from precedes
+	if more || backward {
+		result.NextCursor = newCursor(rows[len(rows)-1], false)
+	}
+	if (backward && more) || (!backward && cursor != nil) {
+		result.PrevCursor = newCursor(rows[0], true)
+	}
+
+	return result
+}
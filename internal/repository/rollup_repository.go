@@ -0,0 +1,235 @@
+package repository
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/kjanat/chatlogger-api-go/internal/domain"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// rollupStateName identifies the incremental rollup in analytics_rollup_state.
+const rollupStateName = "analytics"
+
+// rollupLockKey is the advisory lock serializing rebuilds, so the scheduled
+// refresh and a backfill never delete and insert the same buckets at once.
+const rollupLockKey = 7236105
+
+// rollupState is the position of the incremental rollup.
+type rollupState struct {
+	Name       string `gorm:"primaryKey"`
+	RolledUpTo time.Time
+	UpdatedAt  time.Time
+}
+
+// TableName overrides the table name used by rollupState.
+func (rollupState) TableName() string {
+	return "analytics_rollup_state"
+}
+
+// RollupRepo implements the domain.RollupRepository interface.
+type RollupRepo struct {
+	db *Database
+}
+
+// NewRollupRepository creates a new rollup repository.
+func NewRollupRepository(db *Database) domain.RollupRepository {
+	return &RollupRepo{db: db}
+}
+
+// messageNumber extracts a numeric field of the message metadata, NULL when
+// the field is missing or not a number.
+func messageNumber(field string) string {
+	return "CASE WHEN jsonb_typeof(messages.metadata->'" + field + "') = 'number' " +
+		"THEN (messages.metadata->>'" + field + "')::float8 END"
+}
+
+// messageTokens is the total tokens of a message: its token count, or the sum
+// of its prompt and completion tokens when it reports only those.
+var messageTokens = "COALESCE(" + messageNumber("token_count") + ", " +
+	"COALESCE(" + messageNumber("prompt_tokens") + ", 0) + COALESCE(" + messageNumber("completion_tokens") + ", 0))"
+
+// utcTrunc truncates a timestamptz to a UTC hour or day, independent of the
+// session timezone.
+func utcTrunc(field, column string) string {
+	return "date_trunc('" + field + "', " + column + " AT TIME ZONE 'UTC') AT TIME ZONE 'UTC'"
+}
+
+// rebuildHourlySQL recomputes the hourly rollups of [@from, @to) from chats and
+// messages. Response times of assistant messages, the ones the latency report
+// covers, are counted per slot of @bounds.
+var rebuildHourlySQL = `
+INSERT INTO analytics_hourly_rollups (organization_id, bucket_start, chats, user_messages, assistant_messages,
+	system_messages, tokens, response_time_count, response_time_sum, response_time_histogram, updated_at)
+WITH chat_counts AS (
+	SELECT chats.organization_id, ` + utcTrunc("hour", "chats.created_at") + ` AS bucket_start, COUNT(*) AS chats
+	FROM chats
+	WHERE chats.created_at >= @from AND chats.created_at < @to
+	GROUP BY 1, 2
+),
+scoped_messages AS (
+	SELECT chats.organization_id, ` + utcTrunc("hour", "messages.created_at") + ` AS bucket_start,
+		messages.role,
+		` + messageTokens + ` AS tokens,
+		CASE WHEN messages.role = 'assistant' THEN ` + messageNumber("response_time") + ` END AS response_time
+	FROM messages
+	JOIN chats ON chats.id = messages.chat_id
+	WHERE messages.created_at >= @from AND messages.created_at < @to
+),
+message_stats AS (
+	SELECT organization_id, bucket_start,
+		COUNT(*) FILTER (WHERE role = 'user') AS user_messages,
+		COUNT(*) FILTER (WHERE role = 'assistant') AS assistant_messages,
+		COUNT(*) FILTER (WHERE role = 'system') AS system_messages,
+		COALESCE(SUM(tokens), 0)::bigint AS tokens,
+		COUNT(response_time) AS response_time_count,
+		COALESCE(SUM(response_time), 0) AS response_time_sum
+	FROM scoped_messages
+	GROUP BY 1, 2
+),
+slot_counts AS (
+	SELECT organization_id, bucket_start, width_bucket(response_time, CAST(@bounds AS float8[])) AS slot, COUNT(*) AS n
+	FROM scoped_messages
+	WHERE response_time IS NOT NULL
+	GROUP BY 1, 2, 3
+),
+histograms AS (
+	SELECT buckets.organization_id, buckets.bucket_start,
+		jsonb_agg(COALESCE(slot_counts.n, 0) ORDER BY slots.slot) AS histogram
+	FROM (SELECT DISTINCT organization_id, bucket_start FROM slot_counts) AS buckets
+	CROSS JOIN generate_series(0, @slots) AS slots(slot)
+	LEFT JOIN slot_counts ON slot_counts.organization_id = buckets.organization_id
+		AND slot_counts.bucket_start = buckets.bucket_start
+		AND slot_counts.slot = slots.slot
+	GROUP BY 1, 2
+)
+SELECT COALESCE(chat_counts.organization_id, message_stats.organization_id),
+	COALESCE(chat_counts.bucket_start, message_stats.bucket_start),
+	COALESCE(chat_counts.chats, 0),
+	COALESCE(message_stats.user_messages, 0),
+	COALESCE(message_stats.assistant_messages, 0),
+	COALESCE(message_stats.system_messages, 0),
+	COALESCE(message_stats.tokens, 0),
+	COALESCE(message_stats.response_time_count, 0),
+	COALESCE(message_stats.response_time_sum, 0),
+	COALESCE(histograms.histogram, '[]'::jsonb),
+	NOW()
+FROM chat_counts
+FULL JOIN message_stats ON message_stats.organization_id = chat_counts.organization_id
+	AND message_stats.bucket_start = chat_counts.bucket_start
+LEFT JOIN histograms ON histograms.organization_id = COALESCE(chat_counts.organization_id, message_stats.organization_id)
+	AND histograms.bucket_start = COALESCE(chat_counts.bucket_start, message_stats.bucket_start)`
+
+// rebuildDailySQL recomputes the daily rollups of [@from, @to) from the hourly rollups.
+var rebuildDailySQL = `
+INSERT INTO analytics_daily_rollups (organization_id, bucket_start, chats, user_messages, assistant_messages,
+	system_messages, tokens, response_time_count, response_time_sum, response_time_histogram, updated_at)
+WITH days AS (
+	SELECT organization_id, ` + utcTrunc("day", "bucket_start") + ` AS bucket_start,
+		SUM(chats)::bigint AS chats,
+		SUM(user_messages)::bigint AS user_messages,
+		SUM(assistant_messages)::bigint AS assistant_messages,
+		SUM(system_messages)::bigint AS system_messages,
+		SUM(tokens)::bigint AS tokens,
+		SUM(response_time_count)::bigint AS response_time_count,
+		SUM(response_time_sum) AS response_time_sum
+	FROM analytics_hourly_rollups
+	WHERE bucket_start >= @from AND bucket_start < @to
+	GROUP BY 1, 2
+),
+histograms AS (` + sumHistogramsSQL(utcTrunc("day", "rollups.bucket_start"), "analytics_hourly_rollups",
+	"rollups.bucket_start >= @from AND rollups.bucket_start < @to") + `)
+SELECT days.organization_id, days.bucket_start, days.chats, days.user_messages, days.assistant_messages,
+	days.system_messages, days.tokens, days.response_time_count, days.response_time_sum,
+	COALESCE(histograms.histogram, '[]'::jsonb), NOW()
+FROM days
+LEFT JOIN histograms ON histograms.organization_id = days.organization_id AND histograms.bucket_start = days.bucket_start`
+
+// sumHistogramsSQL sums the response time histograms of the rollups in table
+// matching where per organization and bucket, the SQL expression of which may
+// refer to the rollups as "rollups".
+func sumHistogramsSQL(bucket, table, where string) string {
+	return `
+	SELECT slots.organization_id, slots.bucket_start, jsonb_agg(slots.n ORDER BY slots.slot) AS histogram
+	FROM (
+		SELECT rollups.organization_id, ` + bucket + ` AS bucket_start, slot.position AS slot, SUM(slot.n::bigint) AS n
+		FROM ` + table + ` AS rollups
+		CROSS JOIN LATERAL jsonb_array_elements_text(rollups.response_time_histogram) WITH ORDINALITY AS slot(n, position)
+		WHERE ` + where + `
+		GROUP BY 1, 2, 3
+	) AS slots
+	GROUP BY 1, 2`
+}
+
+// histogramBounds formats ResponseTimeHistogramBounds as a PostgreSQL array.
+func histogramBounds() string {
+	bounds := make([]string, len(domain.ResponseTimeHistogramBounds))
+	for i, bound := range domain.ResponseTimeHistogramBounds {
+		bounds[i] = strconv.FormatFloat(bound, 'f', -1, 64)
+	}
+
+	return "{" + strings.Join(bounds, ",") + "}"
+}
+
+// Rebuild recomputes the hourly rollups of [from, to) and the daily rollups of
+// the UTC days they fall in, replacing the rollups of those buckets.
+func (r *RollupRepo) Rebuild(from, to time.Time) error {
+	dayFrom := from.UTC().Truncate(24 * time.Hour)
+	dayTo := to.UTC().Add(-time.Nanosecond).Truncate(24 * time.Hour).Add(24 * time.Hour)
+
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		// Held until the transaction ends
+		if err := tx.Exec("SELECT pg_advisory_xact_lock(?)", rollupLockKey).Error; err != nil {
+			return err
+		}
+
+		err := tx.Exec("DELETE FROM analytics_hourly_rollups WHERE bucket_start >= ? AND bucket_start < ?", from, to).Error
+		if err != nil {
+			return err
+		}
+
+		err = tx.Exec(rebuildHourlySQL, map[string]any{
+			"from":   from,
+			"to":     to,
+			"bounds": histogramBounds(),
+			"slots":  len(domain.ResponseTimeHistogramBounds),
+		}).Error
+		if err != nil {
+			return err
+		}
+
+		err = tx.Exec("DELETE FROM analytics_daily_rollups WHERE bucket_start >= ? AND bucket_start < ?", dayFrom, dayTo).Error
+		if err != nil {
+			return err
+		}
+
+		return tx.Exec(rebuildDailySQL, map[string]any{"from": dayFrom, "to": dayTo}).Error
+	})
+}
+
+// GetWatermark returns the time everything before has been rolled up.
+func (r *RollupRepo) GetWatermark() (*time.Time, error) {
+	var state rollupState
+
+	err := r.db.Where("name = ?", rollupStateName).First(&state).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+
+		return nil, err
+	}
+
+	return &state.RolledUpTo, nil
+}
+
+// SetWatermark records the time everything before has been rolled up.
+func (r *RollupRepo) SetWatermark(watermark time.Time) error {
+	return r.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "name"}},
+		DoUpdates: clause.AssignmentColumns([]string{"rolled_up_to", "updated_at"}),
+	}).Create(&rollupState{Name: rollupStateName, RolledUpTo: watermark, UpdatedAt: time.Now()}).Error
+}
@@ -0,0 +1,70 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/kjanat/chatlogger-api-go/internal/domain"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// UsageRepo implements the domain.UsageRepository interface.
+type UsageRepo struct {
+	db *Database
+}
+
+// NewUsageRepository creates a new usage repository.
+func NewUsageRepository(db *Database) domain.UsageRepository {
+	return &UsageRepo{db: db}
+}
+
+// Increment adds counts to the counter of an API key on a day, creating the
+// counter on the first use of the day. Concurrent increments add up.
+func (r *UsageRepo) Increment(orgID, apiKeyID uint64, day time.Time, counts domain.UsageCounts) error {
+	return r.db.Clauses(clause.OnConflict{
+		Columns: []clause.Column{{Name: "organization_id"}, {Name: "api_key_id"}, {Name: "day"}},
+		DoUpdates: clause.Assignments(map[string]any{
+			"chats":      gorm.Expr("usage_counters.chats + EXCLUDED.chats"),
+			"messages":   gorm.Expr("usage_counters.messages + EXCLUDED.messages"),
+			"bytes":      gorm.Expr("usage_counters.bytes + EXCLUDED.bytes"),
+			"updated_at": gorm.Expr("EXCLUDED.updated_at"),
+		}),
+	}).Create(&domain.UsageCounter{
+		OrganizationID: orgID,
+		APIKeyID:       apiKeyID,
+		Day:            day,
+		UsageCounts:    counts,
+		UpdatedAt:      time.Now(),
+	}).Error
+}
+
+// Sum sums the counters of an organization on the days in [from, to).
+func (r *UsageRepo) Sum(orgID uint64, from, to time.Time) (*domain.UsageCounts, error) {
+	var counts domain.UsageCounts
+
+	err := r.db.Model(&domain.UsageCounter{}).
+		Select("COALESCE(SUM(chats), 0) AS chats, "+
+			"COALESCE(SUM(messages), 0) AS messages, "+
+			"COALESCE(SUM(bytes), 0) AS bytes").
+		Where("organization_id = ? AND day >= ? AND day < ?", orgID, from, to).
+		Scan(&counts).
+		Error
+	if err != nil {
+		return nil, err
+	}
+
+	return &counts, nil
+}
+
+// FindByOrganizationID lists the counters of an organization on the days in
+// [from, to), oldest first.
+func (r *UsageRepo) FindByOrganizationID(orgID uint64, from, to time.Time) ([]domain.UsageCounter, error) {
+	var counters []domain.UsageCounter
+
+	err := r.db.Where("organization_id = ? AND day >= ? AND day < ?", orgID, from, to).
+		Order("day, api_key_id").
+		Find(&counters).
+		Error
+
+	return counters, err
+}
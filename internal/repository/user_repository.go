@@ -55,12 +55,34 @@ func (r *UserRepo) FindByEmail(email string) (*domain.User, error) {
 	return &user, nil
 }
 
-// FindByOrganizationID finds users by organization ID with pagination.
-func (r *UserRepo) FindByOrganizationID(orgID uint64, limit, offset int) ([]domain.User, error) {
+// userKeyset orders users by ID.
+var userKeyset = keyset{name: "id:asc", idColumn: "users.id"}
+
+// FindByOrganizationID finds a page of the users of an organization.
+func (r *UserRepo) FindByOrganizationID(
+	orgID uint64,
+	pageRequest *domain.PageRequest,
+) (*domain.Page[domain.User], error) {
+	query, err := userKeyset.paginate(r.db.Where("organization_id = ?", orgID), pageRequest.Cursor, pageRequest.Limit)
+	if err != nil {
+		return nil, err
+	}
+
 	var users []domain.User
-	err := r.db.Where("organization_id = ?", orgID).Limit(limit).Offset(offset).Find(&users).Error
+	if err := query.Find(&users).Error; err != nil {
+		return nil, err
+	}
+
+	return page(userKeyset, users, pageRequest.Cursor, pageRequest.Limit,
+		func(user domain.User) (*string, uint64) { return nil, user.ID }), nil
+}
+
+// CountByOrganizationID counts the users of an organization.
+func (r *UserRepo) CountByOrganizationID(orgID uint64) (int64, error) {
+	var count int64
+	err := r.db.Model(&domain.User{}).Where("organization_id = ?", orgID).Count(&count).Error
 
-	return users, err
+	return count, err
 }
 
 // Update updates a user.
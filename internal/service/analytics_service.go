@@ -0,0 +1,190 @@
+package service
+
+import (
+	"cmp"
+	"errors"
+	"fmt"
+	"slices"
+
+	"github.com/kjanat/chatlogger-api-go/internal/domain"
+)
+
+// AnalyticsService implements the domain.AnalyticsService interface.
+type AnalyticsService struct {
+	analyticsRepo domain.AnalyticsRepository
+	orgRepo       domain.OrganizationRepository
+}
+
+// NewAnalyticsService creates a new analytics service.
+func NewAnalyticsService(
+	analyticsRepo domain.AnalyticsRepository,
+	orgRepo domain.OrganizationRepository,
+) domain.AnalyticsService {
+	return &AnalyticsService{
+		analyticsRepo: analyticsRepo,
+		orgRepo:       orgRepo,
+	}
+}
+
+// GetTimeSeries buckets the activity of an organization over a date range,
+// applying the default range, bucket and timezone.
+func (s *AnalyticsService) GetTimeSeries(orgID uint64, query *domain.TimeSeriesQuery) (*domain.TimeSeries, error) {
+	query.Normalize()
+	if err := query.Validate(); err != nil {
+		return nil, fmt.Errorf("%w: %w", domain.ErrInvalidAnalyticsQuery, err)
+	}
+
+	points, err := s.analyticsRepo.GetTimeSeries(orgID, query)
+	if err != nil {
+		return nil, fmt.Errorf("error getting time series: %w", err)
+	}
+
+	loc, _ := query.Location()
+
+	return &domain.TimeSeries{
+		Bucket:   query.Bucket,
+		Timezone: query.Timezone,
+		Start:    query.Start.In(loc),
+		End:      query.End.In(loc),
+		Points:   points,
+	}, nil
+}
+
+// GetQualityReport summarizes the quality of an organization's chats over a
+// date range, applying the default range and number of categories.
+func (s *AnalyticsService) GetQualityReport(orgID uint64, query *domain.QualityQuery) (*domain.QualityReport, error) {
+	query.Normalize()
+	if err := query.Validate(); err != nil {
+		return nil, fmt.Errorf("%w: %w", domain.ErrInvalidAnalyticsQuery, err)
+	}
+
+	report, err := s.analyticsRepo.GetQualityReport(orgID, query)
+	if err != nil {
+		return nil, fmt.Errorf("error getting quality report: %w", err)
+	}
+
+	return report, nil
+}
+
+// GetLatencyReport reports the assistant response latency of an organization
+// over a date range, applying the default range, bucket and timezone, and
+// counts the breaches of the organization's response time SLA.
+func (s *AnalyticsService) GetLatencyReport(orgID uint64, query *domain.LatencyQuery) (*domain.LatencyReport, error) {
+	query.Normalize()
+	if err := query.Validate(); err != nil {
+		return nil, fmt.Errorf("%w: %w", domain.ErrInvalidAnalyticsQuery, err)
+	}
+
+	settings, err := s.settings(orgID)
+	if err != nil {
+		return nil, err
+	}
+
+	report, err := s.analyticsRepo.GetLatencyReport(orgID, query, settings.ResponseTimeSLA)
+	if err != nil {
+		return nil, fmt.Errorf("error getting latency report: %w", err)
+	}
+
+	return report, nil
+}
+
+// GetSlowestChats lists the chats of an organization with the slowest average
+// response time, applying the default range and limit.
+func (s *AnalyticsService) GetSlowestChats(orgID uint64, query *domain.SlowChatQuery) ([]domain.SlowChat, error) {
+	query.Normalize()
+	if err := query.Validate(); err != nil {
+		return nil, fmt.Errorf("%w: %w", domain.ErrInvalidAnalyticsQuery, err)
+	}
+
+	settings, err := s.settings(orgID)
+	if err != nil {
+		return nil, err
+	}
+
+	chats, err := s.analyticsRepo.GetSlowestChats(orgID, query, settings.ResponseTimeSLA)
+	if err != nil {
+		return nil, fmt.Errorf("error getting slowest chats: %w", err)
+	}
+
+	return chats, nil
+}
+
+// GetUsageReport reports the token usage of an organization over a date range,
+// applying the default range, breakdown and timezone, and estimates its cost
+// from the organization's model prices.
+func (s *AnalyticsService) GetUsageReport(orgID uint64, query *domain.UsageQuery) (*domain.UsageReport, error) {
+	query.Normalize()
+	if err := query.Validate(); err != nil {
+		return nil, fmt.Errorf("%w: %w", domain.ErrInvalidAnalyticsQuery, err)
+	}
+
+	settings, err := s.settings(orgID)
+	if err != nil {
+		return nil, err
+	}
+
+	counts, err := s.analyticsRepo.GetUsage(orgID, query)
+	if err != nil {
+		return nil, fmt.Errorf("error getting usage: %w", err)
+	}
+
+	loc, _ := query.Location()
+	report := &domain.UsageReport{
+		Start:    query.Start.In(loc),
+		End:      query.End.In(loc),
+		Timezone: query.Timezone,
+		GroupBy:  query.GroupBy,
+		Currency: settings.Currency(),
+		Groups:   []domain.UsageGroup{},
+	}
+
+	// Counts are per model within a group, so they are priced before merging
+	groups := make(map[[2]string]int)
+	for i := range counts {
+		count := &counts[i]
+		price := settings.FindModelPrice(count.Provider, count.Model)
+
+		report.Totals.Add(count, price)
+
+		group, ok := groups[[2]string{count.Key, count.Label}]
+		if !ok {
+			group = len(report.Groups)
+			groups[[2]string{count.Key, count.Label}] = group
+			report.Groups = append(report.Groups, domain.UsageGroup{Key: count.Key, Label: count.Label})
+		}
+		report.Groups[group].Add(count, price)
+	}
+
+	slices.SortFunc(report.Groups, func(a, b domain.UsageGroup) int {
+		if query.GroupBy == domain.UsageByDay {
+			return cmp.Compare(a.Key, b.Key)
+		}
+		return cmp.Or(
+			cmp.Compare(b.Cost, a.Cost),
+			cmp.Compare(b.TotalTokens, a.TotalTokens),
+			cmp.Compare(a.Key, b.Key),
+			cmp.Compare(a.Label, b.Label),
+		)
+	})
+
+	return report, nil
+}
+
+// settings loads the settings of an organization.
+func (s *AnalyticsService) settings(orgID uint64) (*domain.OrganizationSettings, error) {
+	org, err := s.orgRepo.FindByID(orgID)
+	if err != nil {
+		return nil, fmt.Errorf("error finding organization: %w", err)
+	}
+
+	if org == nil {
+		return nil, errors.New("organization not found")
+	}
+
+	settings, err := org.GetSettings()
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse organization settings: %w", err)
+	}
+
+	return settings, nil
+}
@@ -93,6 +93,22 @@ func (s *APIKeyService) DeleteKey(id uint64) error {
 	return s.apiKeyRepo.Delete(id)
 }
 
+// RecordUse records the use of an API key, unless its last use was recorded
+// less than domain.APIKeyLastUsedResolution ago, sparing a write per request.
+func (s *APIKeyService) RecordUse(key *domain.APIKey) error {
+	now := time.Now()
+	if key.LastUsedAt != nil && now.Sub(*key.LastUsedAt) < domain.APIKeyLastUsedResolution {
+		return nil
+	}
+
+	if err := s.apiKeyRepo.TouchLastUsed(key.ID, now); err != nil {
+		return fmt.Errorf("error recording API key use: %w", err)
+	}
+	key.LastUsedAt = &now
+
+	return nil
+}
+
 // hashKey hashes an API key for secure storage.
 func hashKey(key string) string {
 	h := sha256.New()
@@ -35,9 +35,79 @@ func (s *ChatService) GetByID(id uint64) (*domain.Chat, error) {
 	return s.chatRepo.FindByID(id)
 }
 
-// GetByOrganizationID gets chats by organization ID with pagination.
-func (s *ChatService) GetByOrganizationID(orgID uint64, limit, offset int) ([]domain.Chat, error) {
-	return s.chatRepo.FindByOrganizationID(orgID, limit, offset)
+// GetByOrganizationID gets a filtered and sorted page of chats by organization ID.
+func (s *ChatService) GetByOrganizationID(
+	orgID uint64,
+	filter *domain.ChatFilter,
+	sort *domain.ChatSort,
+	page *domain.PageRequest,
+) (*domain.Page[domain.Chat], error) {
+	if filter != nil {
+		if err := filter.Validate(); err != nil {
+			return nil, fmt.Errorf("invalid filter: %w", err)
+		}
+	}
+
+	if sort == nil {
+		sort = &domain.DefaultChatSort
+	} else if err := sort.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid sort: %w", err)
+	}
+
+	page.Normalize()
+
+	result, err := s.chatRepo.FindByOrganizationID(orgID, filter, sort, page)
+	if err != nil {
+		return nil, err
+	}
+
+	if page.IncludeTotal {
+		total, err := s.chatRepo.CountByOrganizationID(orgID, filter)
+		if err != nil {
+			return nil, fmt.Errorf("error counting chats: %w", err)
+		}
+		result.TotalCount = &total
+	}
+
+	return result, nil
+}
+
+// IterateByOrganizationID walks every chat of an organization matching filter in
+// batches using keyset pagination, so callers can process any number of chats in
+// constant memory.
+func (s *ChatService) IterateByOrganizationID(
+	orgID uint64,
+	filter *domain.ChatFilter,
+	batchSize int,
+	fn func(chats []domain.Chat) error,
+) error {
+	var afterID uint64
+
+	for {
+		chats, err := s.chatRepo.FindByOrganizationIDAfter(orgID, afterID, filter, batchSize)
+		if err != nil {
+			return fmt.Errorf("error getting chats: %w", err)
+		}
+
+		if len(chats) == 0 {
+			return nil
+		}
+
+		if err := fn(chats); err != nil {
+			return err
+		}
+
+		if len(chats) < batchSize {
+			return nil
+		}
+
+		afterID = chats[len(chats)-1].ID
+	}
+}
+
+// CountByOrganizationID counts the chats of an organization matching filter.
+func (s *ChatService) CountByOrganizationID(orgID uint64, filter *domain.ChatFilter) (int64, error) {
+	return s.chatRepo.CountByOrganizationID(orgID, filter)
 }
 
 // GetByUserID gets chats by user ID with pagination.
@@ -81,7 +151,7 @@ func (s *ChatService) GetChatStats(
 	}
 
 	// Get tag statistics
-	tagStats, err := s.chatRepo.GetTagStats(orgID)
+	tagStats, err := s.chatRepo.GetTagStats(orgID, start, end)
 	if err != nil {
 		return nil, fmt.Errorf("error getting tag stats: %w", err)
 	}
@@ -98,3 +168,50 @@ func (s *ChatService) GetChatStats(
 
 	return stats, nil
 }
+
+// ListTags lists the distinct tags of an organization's chats, most used first.
+func (s *ChatService) ListTags(orgID uint64) ([]domain.TagCount, error) {
+	return s.chatRepo.ListTags(orgID)
+}
+
+// RenameTag renames a tag on every chat of an organization.
+func (s *ChatService) RenameTag(orgID uint64, from, to string) (int64, error) {
+	if from == to {
+		return 0, fmt.Errorf("%w: tag cannot be renamed to itself", domain.ErrInvalidTagChange)
+	}
+
+	return s.MergeTags(orgID, []string{from}, to)
+}
+
+// MergeTags replaces the source tags with the target tag on every chat of an organization.
+func (s *ChatService) MergeTags(orgID uint64, sources []string, target string) (int64, error) {
+	if err := domain.ValidateTag(target); err != nil {
+		return 0, fmt.Errorf("%w: %w", domain.ErrInvalidTagChange, err)
+	}
+
+	// Chats carrying only the target need no change
+	var merged []string
+	for _, source := range sources {
+		if err := domain.ValidateTag(source); err != nil {
+			return 0, fmt.Errorf("%w: %w", domain.ErrInvalidTagChange, err)
+		}
+		if source != target {
+			merged = append(merged, source)
+		}
+	}
+
+	if len(merged) == 0 {
+		return 0, fmt.Errorf("%w: merge needs a source tag other than the target", domain.ErrInvalidTagChange)
+	}
+
+	return s.chatRepo.MergeTags(orgID, merged, target)
+}
+
+// UpdateTags adds and removes tags on a selection of chats of an organization.
+func (s *ChatService) UpdateTags(orgID uint64, update *domain.TagUpdate) (int64, error) {
+	if err := update.Validate(); err != nil {
+		return 0, fmt.Errorf("%w: %w", domain.ErrInvalidTagChange, err)
+	}
+
+	return s.chatRepo.UpdateTags(orgID, update)
+}
@@ -0,0 +1,69 @@
+package service
+
+import (
+	"time"
+
+	"github.com/kjanat/chatlogger-api-go/internal/domain"
+)
+
+// ExportDestinationService implements domain.ExportDestinationService
+type ExportDestinationService struct {
+	destinationRepo domain.ExportDestinationRepository
+}
+
+// NewExportDestinationService creates a new export destination service
+func NewExportDestinationService(destinationRepo domain.ExportDestinationRepository) *ExportDestinationService {
+	return &ExportDestinationService{destinationRepo: destinationRepo}
+}
+
+// CreateDestination validates and stores a new export destination
+func (s *ExportDestinationService) CreateDestination(destination *domain.ExportDestination) error {
+	if err := destination.Validate(); err != nil {
+		return err
+	}
+
+	destination.CreatedAt = time.Now()
+	destination.UpdatedAt = time.Now()
+
+	return s.destinationRepo.Create(destination)
+}
+
+// GetDestination gets an export destination by ID, ensuring it belongs to the given organization
+func (s *ExportDestinationService) GetDestination(id, orgID uint64) (*domain.ExportDestination, error) {
+	destination, err := s.destinationRepo.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	// Security check to ensure the destination belongs to the organization
+	if destination.OrganizationID != orgID {
+		return nil, domain.ErrExportDestinationNotFound
+	}
+
+	return destination, nil
+}
+
+// ListDestinations lists the export destinations of an organization
+func (s *ExportDestinationService) ListDestinations(orgID uint64) ([]*domain.ExportDestination, error) {
+	return s.destinationRepo.GetByOrganizationID(orgID)
+}
+
+// UpdateDestination validates and saves an export destination
+func (s *ExportDestinationService) UpdateDestination(destination *domain.ExportDestination) error {
+	if err := destination.Validate(); err != nil {
+		return err
+	}
+
+	destination.UpdatedAt = time.Now()
+
+	return s.destinationRepo.Update(destination)
+}
+
+// DeleteDestination deletes an export destination of the given organization
+func (s *ExportDestinationService) DeleteDestination(id, orgID uint64) error {
+	if _, err := s.GetDestination(id, orgID); err != nil {
+		return err
+	}
+
+	return s.destinationRepo.Delete(id)
+}
@@ -0,0 +1,158 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/robfig/cron/v3"
+
+	"github.com/kjanat/chatlogger-api-go/internal/domain"
+)
+
+// ExportScheduleService implements domain.ExportScheduleService
+type ExportScheduleService struct {
+	scheduleRepo  domain.ExportScheduleRepository
+	exportService domain.ExportService
+}
+
+// NewExportScheduleService creates a new export schedule service
+func NewExportScheduleService(
+	scheduleRepo domain.ExportScheduleRepository,
+	exportService domain.ExportService,
+) *ExportScheduleService {
+	return &ExportScheduleService{
+		scheduleRepo:  scheduleRepo,
+		exportService: exportService,
+	}
+}
+
+// validateSchedule checks the schedule fields, its cron expression and the
+// destinations of its exports
+func (s *ExportScheduleService) validateSchedule(schedule *domain.ExportSchedule) error {
+	if err := schedule.Validate(); err != nil {
+		return err
+	}
+
+	if _, err := cron.ParseStandard(schedule.CronExpression()); err != nil {
+		return fmt.Errorf("invalid cron_spec: %w", err)
+	}
+
+	options, err := schedule.GetOptions()
+	if err != nil {
+		return fmt.Errorf("invalid options: %w", err)
+	}
+	if _, err := s.exportService.ResolveOptions(schedule.OrganizationID, options); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// CreateSchedule validates and stores a new export schedule
+func (s *ExportScheduleService) CreateSchedule(schedule *domain.ExportSchedule) error {
+	if err := s.validateSchedule(schedule); err != nil {
+		return err
+	}
+
+	schedule.CreatedAt = time.Now()
+	schedule.UpdatedAt = time.Now()
+
+	return s.scheduleRepo.Create(schedule)
+}
+
+// GetSchedule gets an export schedule by ID, ensuring it belongs to the given organization
+func (s *ExportScheduleService) GetSchedule(id, orgID uint64) (*domain.ExportSchedule, error) {
+	schedule, err := s.scheduleRepo.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	// Security check to ensure the schedule belongs to the organization
+	if schedule.OrganizationID != orgID {
+		return nil, errors.New("export schedule not found")
+	}
+
+	return schedule, nil
+}
+
+// ListSchedules lists the export schedules of an organization
+func (s *ExportScheduleService) ListSchedules(orgID uint64) ([]*domain.ExportSchedule, error) {
+	return s.scheduleRepo.GetByOrganizationID(orgID)
+}
+
+// UpdateSchedule validates and saves an export schedule
+func (s *ExportScheduleService) UpdateSchedule(schedule *domain.ExportSchedule) error {
+	if err := s.validateSchedule(schedule); err != nil {
+		return err
+	}
+
+	schedule.UpdatedAt = time.Now()
+
+	return s.scheduleRepo.Update(schedule)
+}
+
+// DeleteSchedule deletes an export schedule of the given organization
+func (s *ExportScheduleService) DeleteSchedule(id, orgID uint64) error {
+	if _, err := s.GetSchedule(id, orgID); err != nil {
+		return err
+	}
+
+	return s.scheduleRepo.Delete(id)
+}
+
+// ListEnabledSchedules lists the enabled export schedules of all organizations
+func (s *ExportScheduleService) ListEnabledSchedules() ([]*domain.ExportSchedule, error) {
+	return s.scheduleRepo.ListEnabled()
+}
+
+// RunSchedule creates an export from the schedule and enqueues it. When the
+// schedule has a lookback window, only chats created within it are exported.
+func (s *ExportScheduleService) RunSchedule(id uint64) (*domain.Export, error) {
+	schedule, err := s.scheduleRepo.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	if !schedule.Enabled {
+		return nil, domain.ErrExportScheduleDisabled
+	}
+
+	filter, err := schedule.GetFilters()
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse schedule filters: %w", err)
+	}
+
+	now := time.Now()
+	if schedule.LookbackHours > 0 {
+		if filter == nil {
+			filter = &domain.ExportFilter{}
+		}
+		from := now.Add(-time.Duration(schedule.LookbackHours) * time.Hour)
+		filter.CreatedFrom = &from
+		filter.CreatedTo = &now
+	}
+
+	export := &domain.Export{
+		OrganizationID: schedule.OrganizationID,
+		UserID:         schedule.UserID,
+		Format:         schedule.Format,
+		Type:           schedule.Type,
+		Compression:    schedule.Compression,
+		Options:        schedule.Options,
+	}
+
+	if err := export.SetFilters(filter); err != nil {
+		return nil, err
+	}
+
+	if err := s.exportService.CreateExport(export); err != nil {
+		return nil, err
+	}
+
+	if err := s.scheduleRepo.UpdateLastRun(schedule.ID, now, export.ID); err != nil {
+		return export, fmt.Errorf("failed to record schedule run: %w", err)
+	}
+
+	return export, nil
+}
@@ -2,6 +2,7 @@ package service
 
 import (
 	"errors"
+	"fmt"
 	"time"
 
 	"github.com/kjanat/chatlogger-api-go/internal/domain"
@@ -10,34 +11,103 @@ import (
 
 // ExportService implements domain.ExportService
 type ExportService struct {
-	exportRepo domain.ExportRepository
-	queue      *jobs.Queue
+	exportRepo      domain.ExportRepository
+	orgRepo         domain.OrganizationRepository
+	destinationRepo domain.ExportDestinationRepository
+	deliveryRepo    domain.ExportDeliveryRepository
+	queue           *jobs.Queue
 }
 
 // NewExportService creates a new export service
-func NewExportService(exportRepo domain.ExportRepository, queue *jobs.Queue) *ExportService {
+func NewExportService(
+	exportRepo domain.ExportRepository,
+	orgRepo domain.OrganizationRepository,
+	destinationRepo domain.ExportDestinationRepository,
+	deliveryRepo domain.ExportDeliveryRepository,
+	queue *jobs.Queue,
+) *ExportService {
 	return &ExportService{
-		exportRepo: exportRepo,
-		queue:      queue,
+		exportRepo:      exportRepo,
+		orgRepo:         orgRepo,
+		destinationRepo: destinationRepo,
+		deliveryRepo:    deliveryRepo,
+		queue:           queue,
 	}
 }
 
-// CreateExport creates an export job and enqueues it for processing
-func (s *ExportService) CreateExport(orgID, userID uint64, format domain.ExportFormat, exportType domain.ExportType) (*domain.Export, error) {
-	// Create new export record
-	export := &domain.Export{
-		OrganizationID: orgID,
-		UserID:         userID,
-		Format:         format,
-		Type:           exportType,
-		Status:         domain.ExportStatusPending,
-		CreatedAt:      time.Now(),
-		UpdatedAt:      time.Now(),
+// ResolveOptions applies the organization's export defaults to the options of
+// a new export and checks that its destinations are enabled destinations of
+// the organization. Exports are encrypted to the organization's recipients
+// unless the options name their own.
+func (s *ExportService) ResolveOptions(orgID uint64, options *domain.ExportOptions) (*domain.ExportOptions, error) {
+	if options != nil {
+		for _, id := range options.DestinationIDs {
+			destination, err := s.destinationRepo.GetByID(id)
+			if err != nil || destination.OrganizationID != orgID || !destination.Enabled {
+				return nil, fmt.Errorf("%w: %d", domain.ErrExportDestinationNotFound, id)
+			}
+		}
+
+		if options.Encryption != nil {
+			return options, nil
+		}
 	}
 
+	org, err := s.orgRepo.FindByID(orgID)
+	if err != nil {
+		return nil, err
+	}
+	if org == nil {
+		return nil, errors.New("organization not found")
+	}
+
+	settings, err := org.GetSettings()
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse organization settings: %w", err)
+	}
+	if len(settings.ExportRecipients) == 0 {
+		return options, nil
+	}
+
+	resolved := &domain.ExportOptions{}
+	if options != nil {
+		*resolved = *options
+	}
+	resolved.Encryption = &domain.EncryptionOptions{Recipients: settings.ExportRecipients}
+
+	return resolved, nil
+}
+
+// CreateExport creates an export job, records a pending delivery for each of
+// its destinations and enqueues it for processing
+func (s *ExportService) CreateExport(export *domain.Export) error {
+	options, err := export.GetOptions()
+	if err != nil {
+		return fmt.Errorf("failed to parse export options: %w", err)
+	}
+	options, err = s.ResolveOptions(export.OrganizationID, options)
+	if err != nil {
+		return err
+	}
+	if err := export.SetOptions(options); err != nil {
+		return err
+	}
+
+	// Initialize the export record
+	export.Status = domain.ExportStatusPending
+	export.CreatedAt = time.Now()
+	export.UpdatedAt = time.Now()
+
 	// Save to database
 	if err := s.exportRepo.Create(export); err != nil {
-		return nil, err
+		return err
+	}
+
+	if err := s.createDeliveries(export, options); err != nil {
+		if updateErr := s.exportRepo.UpdateStatus(export.ID, domain.ExportStatusFailed, err.Error()); updateErr != nil {
+			return errors.New(err.Error() + " (additionally, failed to update status: " + updateErr.Error() + ")")
+		}
+		return err
 	}
 
 	// Enqueue the export job
@@ -45,12 +115,41 @@ func (s *ExportService) CreateExport(orgID, userID uint64, format domain.ExportF
 		// If enqueueing fails, update the export status
 		if updateErr := s.exportRepo.UpdateStatus(export.ID, domain.ExportStatusFailed, err.Error()); updateErr != nil {
 			// Log the update error but return the original error
-			return nil, errors.New(err.Error() + " (additionally, failed to update status: " + updateErr.Error() + ")")
+			return errors.New(err.Error() + " (additionally, failed to update status: " + updateErr.Error() + ")")
 		}
-		return nil, err
+		return err
 	}
 
-	return export, nil
+	return nil
+}
+
+// createDeliveries records a pending delivery for every destination of an export
+func (s *ExportService) createDeliveries(export *domain.Export, options *domain.ExportOptions) error {
+	if options == nil {
+		return nil
+	}
+
+	seen := make(map[uint64]bool, len(options.DestinationIDs))
+	for _, id := range options.DestinationIDs {
+		if seen[id] {
+			continue
+		}
+		seen[id] = true
+
+		delivery := domain.ExportDelivery{
+			ExportID:      export.ID,
+			DestinationID: id,
+			Status:        domain.ExportDeliveryPending,
+			CreatedAt:     time.Now(),
+			UpdatedAt:     time.Now(),
+		}
+		if err := s.deliveryRepo.Create(&delivery); err != nil {
+			return fmt.Errorf("failed to record delivery to destination %d: %w", id, err)
+		}
+		export.Deliveries = append(export.Deliveries, delivery)
+	}
+
+	return nil
 }
 
 // GetExport gets an export by ID, ensuring it belongs to the given organization
@@ -68,7 +167,55 @@ func (s *ExportService) GetExport(id, orgID uint64) (*domain.Export, error) {
 	return export, nil
 }
 
-// ListExports lists exports for an organization
-func (s *ExportService) ListExports(orgID uint64, limit, offset int) ([]*domain.Export, error) {
-	return s.exportRepo.GetByOrganizationID(orgID, limit, offset)
+// CancelExport cancels a pending or processing export of the given organization.
+// The export is marked cancelled first, so a worker that picks it up or finishes
+// it afterwards leaves it cancelled.
+func (s *ExportService) CancelExport(id, orgID uint64) (*domain.Export, error) {
+	export, err := s.GetExport(id, orgID)
+	if err != nil {
+		return nil, err
+	}
+
+	if !export.Status.IsCancellable() {
+		return export, domain.ErrExportNotCancellable
+	}
+
+	cancelled, err := s.exportRepo.Cancel(export.ID)
+	if err != nil {
+		return nil, err
+	}
+	if !cancelled {
+		// The export finished after it was read
+		if export, err = s.GetExport(id, orgID); err != nil {
+			return nil, err
+		}
+		return export, domain.ErrExportNotCancellable
+	}
+	export.Status = domain.ExportStatusCancelled
+
+	if err := s.queue.CancelExport(export.ID); err != nil {
+		return nil, fmt.Errorf("export marked cancelled, but failed to stop its task: %w", err)
+	}
+
+	return export, nil
+}
+
+// ListExports lists a page of the exports of an organization, newest first
+func (s *ExportService) ListExports(orgID uint64, page *domain.PageRequest) (*domain.Page[*domain.Export], error) {
+	page.Normalize()
+
+	result, err := s.exportRepo.GetByOrganizationID(orgID, page)
+	if err != nil {
+		return nil, err
+	}
+
+	if page.IncludeTotal {
+		total, err := s.exportRepo.CountByOrganizationID(orgID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to count exports: %w", err)
+		}
+		result.TotalCount = &total
+	}
+
+	return result, nil
 }
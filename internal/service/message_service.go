@@ -43,6 +43,57 @@ func (s *MessageService) GetByChatID(chatID uint64) ([]domain.Message, error) {
 	return s.messageRepo.FindByChatID(chatID)
 }
 
+// GetPageByChatID gets a page of the messages of a chat in chronological order.
+func (s *MessageService) GetPageByChatID(
+	chatID uint64,
+	page *domain.PageRequest,
+) (*domain.Page[domain.Message], error) {
+	page.Normalize()
+
+	result, err := s.messageRepo.FindPageByChatID(chatID, page)
+	if err != nil {
+		return nil, err
+	}
+
+	if page.IncludeTotal {
+		total, err := s.messageRepo.CountByChatID(chatID)
+		if err != nil {
+			return nil, fmt.Errorf("error counting messages: %w", err)
+		}
+		result.TotalCount = &total
+	}
+
+	return result, nil
+}
+
+// GetByChatIDs gets the messages of several chats at once, optionally restricted to roles.
+func (s *MessageService) GetByChatIDs(chatIDs []uint64, roles []domain.MessageRole) ([]domain.Message, error) {
+	return s.messageRepo.FindByChatIDs(chatIDs, roles)
+}
+
+// SearchMessages searches the messages of an organization, applying the default
+// page size and number of hits per chat.
+func (s *MessageService) SearchMessages(
+	orgID uint64,
+	query *domain.MessageSearchQuery,
+) (*domain.MessageSearchResult, error) {
+	if err := query.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid search: %w", err)
+	}
+
+	if query.HitsPerChat == 0 {
+		query.HitsPerChat = domain.DefaultSearchHitsPerChat
+	}
+	if query.Limit <= 0 || query.Limit > 100 {
+		query.Limit = 20
+	}
+	if query.Offset < 0 {
+		query.Offset = 0
+	}
+
+	return s.messageRepo.Search(orgID, query)
+}
+
 // GetMessageStats gets message statistics for an organization.
 func (s *MessageService) GetMessageStats(
 	orgID uint64,
@@ -98,6 +98,65 @@ func (s *OrganizationService) List(limit, offset int) ([]domain.Organization, er
 	return s.orgRepo.List(limit, offset)
 }
 
+// GetSettings gets the settings of an organization.
+func (s *OrganizationService) GetSettings(id uint64) (*domain.OrganizationSettings, error) {
+	org, err := s.orgRepo.FindByID(id)
+	if err != nil {
+		return nil, fmt.Errorf("error finding organization: %w", err)
+	}
+
+	if org == nil {
+		return nil, errors.New("organization not found")
+	}
+
+	return org.GetSettings()
+}
+
+// UpdateSettings replaces the settings of an organization.
+func (s *OrganizationService) UpdateSettings(id uint64, settings *domain.OrganizationSettings) error {
+	if err := settings.Validate(); err != nil {
+		return err
+	}
+
+	org, err := s.orgRepo.FindByID(id)
+	if err != nil {
+		return fmt.Errorf("error finding organization: %w", err)
+	}
+
+	if org == nil {
+		return errors.New("organization not found")
+	}
+
+	if err := org.SetSettings(settings); err != nil {
+		return err
+	}
+
+	// Update timestamp
+	org.UpdatedAt = time.Now()
+
+	return s.orgRepo.Update(org)
+}
+
+// UpdateQuotas replaces the usage quotas of an organization, keeping its other
+// settings. Zero-valued quotas remove every limit.
+func (s *OrganizationService) UpdateQuotas(id uint64, quotas *domain.UsageQuotas) (*domain.OrganizationSettings, error) {
+	settings, err := s.GetSettings(id)
+	if err != nil {
+		return nil, err
+	}
+
+	if quotas.IsZero() {
+		quotas = nil
+	}
+	settings.Quotas = quotas
+
+	if err := s.UpdateSettings(id, settings); err != nil {
+		return nil, err
+	}
+
+	return settings, nil
+}
+
 // Helper functions
 
 // generateSlug generates a URL-friendly slug from a name.
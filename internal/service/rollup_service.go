@@ -0,0 +1,83 @@
+package service
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/kjanat/chatlogger-api-go/internal/domain"
+)
+
+// RollupService implements the domain.RollupService interface.
+type RollupService struct {
+	rollupRepo domain.RollupRepository
+}
+
+// NewRollupService creates a new rollup service.
+func NewRollupService(rollupRepo domain.RollupRepository) domain.RollupService {
+	return &RollupService{
+		rollupRepo: rollupRepo,
+	}
+}
+
+// Refresh recomputes the rollups from shortly before the previous refresh up
+// to now. After a long outage, the whole gap is caught up in chunks.
+func (s *RollupService) Refresh() error {
+	now := time.Now()
+
+	watermark, err := s.rollupRepo.GetWatermark()
+	if err != nil {
+		return fmt.Errorf("error getting rollup watermark: %w", err)
+	}
+
+	from := now
+	if watermark != nil && watermark.Before(from) {
+		from = *watermark
+	}
+
+	if err := s.rebuild(from.Add(-domain.RollupLookback), now, nil); err != nil {
+		return err
+	}
+
+	if err := s.rollupRepo.SetWatermark(now); err != nil {
+		return fmt.Errorf("error setting rollup watermark: %w", err)
+	}
+
+	return nil
+}
+
+// Backfill recomputes the rollups of the hours overlapping [from, to).
+func (s *RollupService) Backfill(from, to time.Time, progress func(done time.Time)) error {
+	if !from.Before(to) {
+		return domain.ErrInvalidBackfillRange
+	}
+
+	return s.rebuild(from, to, progress)
+}
+
+// rebuild recomputes the rollups of the hours overlapping [from, to), one
+// chunk per transaction.
+func (s *RollupService) rebuild(from, to time.Time, progress func(done time.Time)) error {
+	start := from.UTC().Truncate(time.Hour)
+	end := to.UTC().Truncate(time.Hour)
+	if end.Before(to) {
+		end = end.Add(time.Hour)
+	}
+
+	for start.Before(end) {
+		chunkEnd := start.Add(domain.RollupChunk)
+		if chunkEnd.After(end) {
+			chunkEnd = end
+		}
+
+		if err := s.rollupRepo.Rebuild(start, chunkEnd); err != nil {
+			return fmt.Errorf("error rebuilding rollups from %s: %w", start.Format(time.RFC3339), err)
+		}
+
+		if progress != nil {
+			progress(chunkEnd)
+		}
+		start = chunkEnd
+	}
+
+	return nil
+}
@@ -0,0 +1,179 @@
+package service
+
+import (
+	"cmp"
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/kjanat/chatlogger-api-go/internal/domain"
+)
+
+// UsageService implements the domain.UsageService interface.
+type UsageService struct {
+	usageRepo  domain.UsageRepository
+	orgRepo    domain.OrganizationRepository
+	apiKeyRepo domain.APIKeyRepository
+}
+
+// NewUsageService creates a new usage service.
+func NewUsageService(
+	usageRepo domain.UsageRepository,
+	orgRepo domain.OrganizationRepository,
+	apiKeyRepo domain.APIKeyRepository,
+) domain.UsageService {
+	return &UsageService{
+		usageRepo:  usageRepo,
+		orgRepo:    orgRepo,
+		apiKeyRepo: apiKeyRepo,
+	}
+}
+
+// CheckQuota returns a domain.QuotaError when the organization has reached a
+// quota limiting the ingestion of resource. Concurrent requests are checked
+// against the same usage, so a quota may be overshot by a few requests.
+func (s *UsageService) CheckQuota(orgID uint64, resource domain.UsageResource) error {
+	quotas, err := s.quotas(orgID)
+	if err != nil {
+		return err
+	}
+	if quotas.IsZero() {
+		return nil
+	}
+
+	now := time.Now()
+	day := domain.UsageDay(now)
+	nextDay := day.AddDate(0, 0, 1)
+
+	today, err := s.usageRepo.Sum(orgID, day, nextDay)
+	if err != nil {
+		return fmt.Errorf("error getting today's usage: %w", err)
+	}
+
+	month, err := s.usageRepo.Sum(orgID, domain.UsageMonth(now), nextDay)
+	if err != nil {
+		return fmt.Errorf("error getting this month's usage: %w", err)
+	}
+
+	return quotas.Check(resource, *today, *month, now)
+}
+
+// Record counts ingested resources towards an API key, 0 for the dashboard,
+// on the current UTC day.
+func (s *UsageService) Record(orgID, apiKeyID uint64, counts domain.UsageCounts) error {
+	if err := s.usageRepo.Increment(orgID, apiKeyID, domain.UsageDay(time.Now()), counts); err != nil {
+		return fmt.Errorf("error recording usage: %w", err)
+	}
+
+	return nil
+}
+
+// GetUsage lists the ingestion of an organization per day and API key over
+// the UTC days overlapping a date range, applying the default range.
+func (s *UsageService) GetUsage(orgID uint64, query *domain.UsageViewQuery) (*domain.OrganizationUsage, error) {
+	query.Normalize()
+	if err := query.Validate(); err != nil {
+		return nil, fmt.Errorf("%w: %w", domain.ErrInvalidAnalyticsQuery, err)
+	}
+
+	quotas, err := s.quotas(orgID)
+	if err != nil {
+		return nil, err
+	}
+
+	start := domain.UsageDay(query.Start)
+	end := domain.UsageDay(query.End).AddDate(0, 0, 1)
+
+	counters, err := s.usageRepo.FindByOrganizationID(orgID, start, end)
+	if err != nil {
+		return nil, fmt.Errorf("error listing usage: %w", err)
+	}
+
+	keys, err := s.apiKeyRepo.ListByOrganizationID(orgID)
+	if err != nil {
+		return nil, fmt.Errorf("error listing API keys: %w", err)
+	}
+	labels := make(map[uint64]string, len(keys))
+	for _, key := range keys {
+		labels[key.ID] = key.Label
+	}
+
+	usage := &domain.OrganizationUsage{
+		Start:   start,
+		End:     end,
+		Days:    []domain.DailyUsage{},
+		APIKeys: []domain.APIKeyUsage{},
+	}
+	if !quotas.IsZero() {
+		usage.Quotas = quotas
+	}
+
+	days := make(map[string]int)
+	apiKeys := make(map[uint64]int)
+	for _, counter := range counters {
+		usage.Totals.Add(counter.UsageCounts)
+
+		// Counters are ordered by day
+		day := counter.Day.Format(time.DateOnly)
+		i, ok := days[day]
+		if !ok {
+			i = len(usage.Days)
+			days[day] = i
+			usage.Days = append(usage.Days, domain.DailyUsage{Day: day})
+		}
+		usage.Days[i].Add(counter.UsageCounts)
+
+		i, ok = apiKeys[counter.APIKeyID]
+		if !ok {
+			i = len(usage.APIKeys)
+			apiKeys[counter.APIKeyID] = i
+			usage.APIKeys = append(usage.APIKeys, domain.APIKeyUsage{
+				APIKeyID: counter.APIKeyID,
+				Label:    labels[counter.APIKeyID],
+			})
+		}
+		usage.APIKeys[i].Add(counter.UsageCounts)
+	}
+
+	slices.SortFunc(usage.APIKeys, func(a, b domain.APIKeyUsage) int {
+		return cmp.Or(cmp.Compare(b.Messages, a.Messages), cmp.Compare(a.APIKeyID, b.APIKeyID))
+	})
+
+	now := time.Now()
+	day := domain.UsageDay(now)
+	nextDay := day.AddDate(0, 0, 1)
+
+	today, err := s.usageRepo.Sum(orgID, day, nextDay)
+	if err != nil {
+		return nil, fmt.Errorf("error getting today's usage: %w", err)
+	}
+	usage.Today = *today
+
+	month, err := s.usageRepo.Sum(orgID, domain.UsageMonth(now), nextDay)
+	if err != nil {
+		return nil, fmt.Errorf("error getting this month's usage: %w", err)
+	}
+	usage.ThisMonth = *month
+
+	return usage, nil
+}
+
+// quotas loads the usage quotas of an organization, nil when none are set.
+func (s *UsageService) quotas(orgID uint64) (*domain.UsageQuotas, error) {
+	org, err := s.orgRepo.FindByID(orgID)
+	if err != nil {
+		return nil, fmt.Errorf("error finding organization: %w", err)
+	}
+
+	if org == nil {
+		return nil, errors.New("organization not found")
+	}
+
+	settings, err := org.GetSettings()
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse organization settings: %w", err)
+	}
+
+	return settings.Quotas, nil
+}
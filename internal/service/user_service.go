@@ -96,9 +96,24 @@ func (s *UserService) GetByEmail(email string) (*domain.User, error) {
 	return s.userRepo.FindByEmail(email)
 }
 
-// GetByOrganizationID gets users by organization ID with pagination.
-func (s *UserService) GetByOrganizationID(orgID uint64, limit, offset int) ([]domain.User, error) {
-	return s.userRepo.FindByOrganizationID(orgID, limit, offset)
+// GetByOrganizationID gets a page of users by organization ID.
+func (s *UserService) GetByOrganizationID(orgID uint64, page *domain.PageRequest) (*domain.Page[domain.User], error) {
+	page.Normalize()
+
+	result, err := s.userRepo.FindByOrganizationID(orgID, page)
+	if err != nil {
+		return nil, err
+	}
+
+	if page.IncludeTotal {
+		total, err := s.userRepo.CountByOrganizationID(orgID)
+		if err != nil {
+			return nil, fmt.Errorf("error counting users: %w", err)
+		}
+		result.TotalCount = &total
+	}
+
+	return result, nil
 }
 
 // UpdateUser updates a user.
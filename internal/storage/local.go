@@ -0,0 +1,127 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// fileMode is the mode of stored files, readable by other processes serving or
+// syncing the export directory.
+const fileMode = 0644
+
+// Local stores objects as files below a root directory.
+type Local struct {
+	root string
+}
+
+// NewLocal creates a local filesystem backend rooted at dir, creating the
+// directory if it does not exist.
+func NewLocal(dir string) (*Local, error) {
+	if dir == "" {
+		return nil, errors.New("storage: local directory is required")
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create export directory: %w", err)
+	}
+	return &Local{root: dir}, nil
+}
+
+// path resolves key to a file path below the root directory.
+// Exports created before storage backends existed recorded the full file path,
+// so keys that already point into the root directory are used as they are.
+func (l *Local) path(key string) (string, error) {
+	cleaned := filepath.Clean(filepath.FromSlash(key))
+	root := filepath.Clean(l.root)
+
+	if cleaned == root || strings.HasPrefix(cleaned, root+string(filepath.Separator)) {
+		return cleaned, nil
+	}
+	if filepath.IsAbs(cleaned) || cleaned == ".." || strings.HasPrefix(cleaned, ".."+string(filepath.Separator)) {
+		return "", fmt.Errorf("storage: invalid key %q", key)
+	}
+
+	return filepath.Join(root, cleaned), nil
+}
+
+// Put writes the object to a temporary file and moves it into place once
+// write has succeeded, so readers never see a partial export.
+func (l *Local) Put(ctx context.Context, key string, write func(w io.Writer) error) error {
+	path, err := l.path(key)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+
+	file, err := os.CreateTemp(filepath.Dir(path), ".upload-*")
+	if err != nil {
+		return err
+	}
+
+	// Temporary files are only readable by their owner
+	err = write(file)
+	if err == nil {
+		err = file.Chmod(fileMode)
+	}
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(file.Name(), path)
+	}
+
+	if err != nil {
+		_ = os.Remove(file.Name())
+		return err
+	}
+	return nil
+}
+
+// Open opens the file of key.
+func (l *Local) Open(ctx context.Context, key string) (*Object, error) {
+	path, err := l.path(key)
+	if err != nil {
+		return nil, err
+	}
+
+	file, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	info, err := file.Stat()
+	if err != nil {
+		_ = file.Close()
+		return nil, err
+	}
+
+	return &Object{ReadCloser: file, Size: info.Size()}, nil
+}
+
+// Delete removes the file of key.
+func (l *Local) Delete(ctx context.Context, key string) error {
+	path, err := l.path(key)
+	if err != nil {
+		return err
+	}
+
+	if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
+
+// PresignGet is not supported by the local backend.
+func (l *Local) PresignGet(ctx context.Context, key, filename string, expiry time.Duration) (string, error) {
+	return "", ErrPresignNotSupported
+}
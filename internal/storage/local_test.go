@@ -0,0 +1,154 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// putString stores content under key.
+func putString(t *testing.T, store Storage, key, content string) {
+	t.Helper()
+
+	err := store.Put(context.Background(), key, func(w io.Writer) error {
+		_, err := io.WriteString(w, content)
+		return err
+	})
+	if err != nil {
+		t.Fatalf("Put(%q) error = %v", key, err)
+	}
+}
+
+// readString reads the object stored under key.
+func readString(t *testing.T, store Storage, key string) string {
+	t.Helper()
+
+	object, err := store.Open(context.Background(), key)
+	if err != nil {
+		t.Fatalf("Open(%q) error = %v", key, err)
+	}
+	defer object.Close()
+
+	data, err := io.ReadAll(object)
+	if err != nil {
+		t.Fatalf("failed to read %q: %v", key, err)
+	}
+	if object.Size != int64(len(data)) {
+		t.Errorf("Size = %d, want %d", object.Size, len(data))
+	}
+
+	return string(data)
+}
+
+func TestLocalPutOpenDelete(t *testing.T) {
+	store, err := NewLocal(t.TempDir())
+	if err != nil {
+		t.Fatalf("NewLocal() error = %v", err)
+	}
+
+	putString(t, store, "7/export_1.json", `{"chats":[]}`)
+	if got := readString(t, store, "7/export_1.json"); got != `{"chats":[]}` {
+		t.Errorf("stored content = %q", got)
+	}
+
+	if err := store.Delete(context.Background(), "7/export_1.json"); err != nil {
+		t.Fatalf("Delete() error = %v", err)
+	}
+	if _, err := store.Open(context.Background(), "7/export_1.json"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Open() after Delete error = %v, want %v", err, ErrNotFound)
+	}
+
+	// Deleting a missing object is not an error
+	if err := store.Delete(context.Background(), "7/export_1.json"); err != nil {
+		t.Errorf("Delete() of a missing object error = %v", err)
+	}
+}
+
+func TestLocalPutFileMode(t *testing.T) {
+	dir := t.TempDir()
+	store, err := NewLocal(dir)
+	if err != nil {
+		t.Fatalf("NewLocal() error = %v", err)
+	}
+
+	putString(t, store, "export.csv", "id,title\n")
+
+	info, err := os.Stat(filepath.Join(dir, "export.csv"))
+	if err != nil {
+		t.Fatalf("Stat() error = %v", err)
+	}
+	if mode := info.Mode().Perm(); mode != fileMode {
+		t.Errorf("file mode = %o, want %o", mode, fileMode)
+	}
+}
+
+func TestLocalPutFailedWriteStoresNothing(t *testing.T) {
+	dir := t.TempDir()
+	store, err := NewLocal(dir)
+	if err != nil {
+		t.Fatalf("NewLocal() error = %v", err)
+	}
+
+	writeErr := errors.New("export failed")
+	err = store.Put(context.Background(), "export.json", func(w io.Writer) error {
+		_, _ = io.WriteString(w, "partial")
+		return writeErr
+	})
+	if !errors.Is(err, writeErr) {
+		t.Fatalf("Put() error = %v, want %v", err, writeErr)
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatalf("ReadDir() error = %v", err)
+	}
+	if len(entries) != 0 {
+		t.Errorf("directory holds %d files after a failed write, want none", len(entries))
+	}
+}
+
+func TestLocalPathRejectsEscapingKeys(t *testing.T) {
+	store, err := NewLocal(t.TempDir())
+	if err != nil {
+		t.Fatalf("NewLocal() error = %v", err)
+	}
+
+	for _, key := range []string{"../export.json", "/etc/passwd", ".."} {
+		if _, err := store.path(key); err == nil {
+			t.Errorf("path(%q) succeeded, want an invalid key error", key)
+		}
+	}
+}
+
+func TestLocalPathAcceptsLegacyFullPaths(t *testing.T) {
+	dir := t.TempDir()
+	store, err := NewLocal(dir)
+	if err != nil {
+		t.Fatalf("NewLocal() error = %v", err)
+	}
+
+	legacy := filepath.Join(dir, "export_1.json")
+	path, err := store.path(legacy)
+	if err != nil {
+		t.Fatalf("path(%q) error = %v", legacy, err)
+	}
+	if path != legacy {
+		t.Errorf("path(%q) = %q, want it unchanged", legacy, path)
+	}
+}
+
+func TestLocalPresignGetNotSupported(t *testing.T) {
+	store, err := NewLocal(t.TempDir())
+	if err != nil {
+		t.Fatalf("NewLocal() error = %v", err)
+	}
+
+	_, err = store.PresignGet(context.Background(), "export.json", "export.json", time.Hour)
+	if !errors.Is(err, ErrPresignNotSupported) {
+		t.Errorf("PresignGet() error = %v, want %v", err, ErrPresignNotSupported)
+	}
+}
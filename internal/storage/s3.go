@@ -0,0 +1,137 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/url"
+	"path"
+	"time"
+
+	"github.com/minio/minio-go/v7"
+	"github.com/minio/minio-go/v7/pkg/credentials"
+)
+
+// s3PartSize is the multipart upload part size. Exports are streamed with an
+// unknown length, so one part is buffered in memory at a time.
+const s3PartSize = 16 << 20
+
+// S3Config holds the settings of the S3-compatible backend.
+type S3Config struct {
+	Endpoint        string // Host and optional port, e.g. "s3.amazonaws.com" or "minio:9000"
+	Region          string
+	Bucket          string
+	Prefix          string // Optional key prefix, e.g. "exports/"
+	AccessKeyID     string
+	SecretAccessKey string
+	UseSSL          bool
+}
+
+// S3 stores objects in a bucket of an S3-compatible object store.
+type S3 struct {
+	client *minio.Client
+	bucket string
+	prefix string
+}
+
+// NewS3 creates an S3-compatible backend. The bucket is created when it does
+// not exist yet, which keeps local MinIO setups free of manual steps.
+func NewS3(cfg S3Config) (*S3, error) {
+	if cfg.Endpoint == "" || cfg.Bucket == "" {
+		return nil, errors.New("storage: S3 endpoint and bucket are required")
+	}
+
+	client, err := minio.New(cfg.Endpoint, &minio.Options{
+		Creds:  credentials.NewStaticV4(cfg.AccessKeyID, cfg.SecretAccessKey, ""),
+		Secure: cfg.UseSSL,
+		Region: cfg.Region,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create S3 client: %w", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	exists, err := client.BucketExists(ctx, cfg.Bucket)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check S3 bucket: %w", err)
+	}
+	if !exists {
+		if err := client.MakeBucket(ctx, cfg.Bucket, minio.MakeBucketOptions{Region: cfg.Region}); err != nil {
+			return nil, fmt.Errorf("failed to create S3 bucket: %w", err)
+		}
+	}
+
+	return &S3{client: client, bucket: cfg.Bucket, prefix: cfg.Prefix}, nil
+}
+
+// objectName returns the name of the object of key inside the bucket.
+func (s *S3) objectName(key string) string {
+	return path.Join(s.prefix, key)
+}
+
+// Put streams the data written by write into a multipart upload. The upload is
+// aborted when write fails.
+func (s *S3) Put(ctx context.Context, key string, write func(w io.Writer) error) error {
+	pr, pw := io.Pipe()
+
+	uploaded := make(chan error, 1)
+	go func() {
+		_, err := s.client.PutObject(ctx, s.bucket, s.objectName(key), pr, -1, minio.PutObjectOptions{
+			ContentType: mime.TypeByExtension(path.Ext(key)),
+			PartSize:    s3PartSize,
+		})
+		// Unblock the writer if the upload stopped reading early
+		pr.CloseWithError(err)
+		uploaded <- err
+	}()
+
+	if err := write(pw); err != nil {
+		pw.CloseWithError(err)
+		<-uploaded
+		return err
+	}
+	pw.Close()
+
+	return <-uploaded
+}
+
+// Open opens the object of key.
+func (s *S3) Open(ctx context.Context, key string) (*Object, error) {
+	object, err := s.client.GetObject(ctx, s.bucket, s.objectName(key), minio.GetObjectOptions{})
+	if err != nil {
+		return nil, err
+	}
+
+	info, err := object.Stat()
+	if err != nil {
+		_ = object.Close()
+		if minio.ToErrorResponse(err).Code == "NoSuchKey" {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+
+	return &Object{ReadCloser: object, Size: info.Size}, nil
+}
+
+// Delete removes the object of key.
+func (s *S3) Delete(ctx context.Context, key string) error {
+	return s.client.RemoveObject(ctx, s.bucket, s.objectName(key), minio.RemoveObjectOptions{})
+}
+
+// PresignGet returns a presigned URL that downloads the object of key as an
+// attachment named filename.
+func (s *S3) PresignGet(ctx context.Context, key, filename string, expiry time.Duration) (string, error) {
+	params := url.Values{}
+	params.Set("response-content-disposition", fmt.Sprintf("attachment; filename=%q", filename))
+
+	u, err := s.client.PresignedGetObject(ctx, s.bucket, s.objectName(key), expiry, params)
+	if err != nil {
+		return "", err
+	}
+	return u.String(), nil
+}
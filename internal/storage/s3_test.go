@@ -0,0 +1,127 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+// newTestS3 connects to the S3-compatible store at S3_TEST_ENDPOINT, such as
+// the MinIO of `docker compose --profile minio up`, in a fresh key prefix.
+// The test is skipped when no endpoint is set.
+func newTestS3(t *testing.T) *S3 {
+	t.Helper()
+
+	endpoint := os.Getenv("S3_TEST_ENDPOINT")
+	if endpoint == "" {
+		t.Skip("S3_TEST_ENDPOINT not set")
+	}
+
+	cfg := S3Config{
+		Endpoint:        endpoint,
+		Bucket:          envOr("S3_TEST_BUCKET", "chatlogger-test"),
+		Prefix:          fmt.Sprintf("test-%d/", time.Now().UnixNano()),
+		AccessKeyID:     envOr("S3_TEST_ACCESS_KEY_ID", "minioadmin"),
+		SecretAccessKey: envOr("S3_TEST_SECRET_ACCESS_KEY", "minioadmin"),
+		UseSSL:          os.Getenv("S3_TEST_USE_SSL") == "true",
+	}
+
+	store, err := NewS3(cfg)
+	if err != nil {
+		t.Fatalf("NewS3() error = %v", err)
+	}
+
+	return store
+}
+
+// envOr returns the environment variable key, or fallback when it is not set.
+func envOr(key, fallback string) string {
+	if value := os.Getenv(key); value != "" {
+		return value
+	}
+	return fallback
+}
+
+func TestS3PutOpenDelete(t *testing.T) {
+	store := newTestS3(t)
+
+	putString(t, store, "7/export_1.json", `{"chats":[]}`)
+	if got := readString(t, store, "7/export_1.json"); got != `{"chats":[]}` {
+		t.Errorf("stored content = %q", got)
+	}
+
+	if err := store.Delete(context.Background(), "7/export_1.json"); err != nil {
+		t.Fatalf("Delete() error = %v", err)
+	}
+	if _, err := store.Open(context.Background(), "7/export_1.json"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Open() after Delete error = %v, want %v", err, ErrNotFound)
+	}
+}
+
+func TestS3PutStreamsMultipleParts(t *testing.T) {
+	store := newTestS3(t)
+
+	// Larger than one part, so the upload is split
+	content := strings.Repeat("0123456789abcdef", s3PartSize/16+1024)
+	putString(t, store, "large.ndjson", content)
+	t.Cleanup(func() { _ = store.Delete(context.Background(), "large.ndjson") })
+
+	if got := readString(t, store, "large.ndjson"); got != content {
+		t.Errorf("stored %d bytes, want %d", len(got), len(content))
+	}
+}
+
+func TestS3PutFailedWriteStoresNothing(t *testing.T) {
+	store := newTestS3(t)
+
+	writeErr := errors.New("export failed")
+	err := store.Put(context.Background(), "failed.json", func(w io.Writer) error {
+		_, _ = io.WriteString(w, "partial")
+		return writeErr
+	})
+	if !errors.Is(err, writeErr) {
+		t.Fatalf("Put() error = %v, want %v", err, writeErr)
+	}
+
+	if _, err := store.Open(context.Background(), "failed.json"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Open() after a failed write error = %v, want %v", err, ErrNotFound)
+	}
+}
+
+func TestS3PresignGet(t *testing.T) {
+	store := newTestS3(t)
+
+	putString(t, store, "export.csv", "id,title\n")
+	t.Cleanup(func() { _ = store.Delete(context.Background(), "export.csv") })
+
+	url, err := store.PresignGet(context.Background(), "export.csv", "chats.csv", time.Minute)
+	if err != nil {
+		t.Fatalf("PresignGet() error = %v", err)
+	}
+
+	resp, err := http.Get(url)
+	if err != nil {
+		t.Fatalf("GET presigned URL error = %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("GET presigned URL status = %d", resp.StatusCode)
+	}
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatalf("failed to read download: %v", err)
+	}
+	if string(body) != "id,title\n" {
+		t.Errorf("downloaded content = %q", body)
+	}
+	if got := resp.Header.Get("Content-Disposition"); !strings.Contains(got, `filename="chats.csv"`) {
+		t.Errorf("Content-Disposition = %q, want the requested file name", got)
+	}
+}
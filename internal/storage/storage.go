@@ -0,0 +1,68 @@
+// Package storage provides pluggable backends for export files.
+// Exports are written by the worker and read back by the API server, which may run
+// on different hosts. The local filesystem backend suits single-host deployments
+// with a shared volume; the S3 backend works with any S3-compatible object store,
+// such as AWS S3 or MinIO.
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+)
+
+// Driver names accepted by New.
+const (
+	DriverLocal = "local"
+	DriverS3    = "s3"
+)
+
+var (
+	// ErrNotFound is returned when an object does not exist.
+	ErrNotFound = errors.New("storage: object not found")
+	// ErrPresignNotSupported is returned by backends that cannot hand out
+	// presigned URLs. Callers should stream the object through Open instead.
+	ErrPresignNotSupported = errors.New("storage: presigned URLs not supported")
+)
+
+// Object is an object opened for reading.
+type Object struct {
+	io.ReadCloser
+	Size int64 // Size in bytes, or -1 when unknown
+}
+
+// Storage defines the operations available on an export storage backend.
+// Keys are slash-separated paths relative to the root of the backend.
+type Storage interface {
+	// Put stores the data written by write under key. When write returns an
+	// error, nothing is stored.
+	Put(ctx context.Context, key string, write func(w io.Writer) error) error
+	// Open opens the object stored under key for reading.
+	Open(ctx context.Context, key string) (*Object, error)
+	// Delete removes the object stored under key. Deleting a missing object is not an error.
+	Delete(ctx context.Context, key string) error
+	// PresignGet returns a URL that downloads the object under key as filename
+	// until expiry has passed.
+	PresignGet(ctx context.Context, key, filename string, expiry time.Duration) (string, error)
+}
+
+// Config holds the settings of the storage backends.
+type Config struct {
+	Driver   string // "local" (default) or "s3"
+	LocalDir string // Root directory of the local backend
+	S3       S3Config
+}
+
+// New returns the storage backend selected by cfg.Driver.
+func New(cfg Config) (Storage, error) {
+	switch cfg.Driver {
+	case "", DriverLocal:
+		return NewLocal(cfg.LocalDir)
+	case DriverS3:
+		return NewS3(cfg.S3)
+	default:
+		return nil, fmt.Errorf("unsupported storage driver: %s", cfg.Driver)
+	}
+}
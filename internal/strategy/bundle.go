@@ -0,0 +1,159 @@
+package strategy
+
+import (
+	"archive/zip"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"hash"
+	"io"
+	"time"
+
+	"github.com/kjanat/chatlogger-api-go/internal/domain"
+)
+
+// ManifestName is the name of the manifest entry written into every bundle.
+const ManifestName = "manifest.json"
+
+// BundleExporter is implemented by exporters whose output is a set of files
+// rather than a single document. Each file is created in bundle.
+type BundleExporter interface {
+	ExportBundle(bundle *Bundle, info ExportInfo, source ChatSource) error
+}
+
+// Manifest describes the contents of a bundle.
+type Manifest struct {
+	OrganizationID uint64            `json:"organization_id"`
+	ExportType     domain.ExportType `json:"export_type"`
+	ExportDate     time.Time         `json:"export_date"`
+	ChatCount      int               `json:"chat_count"`
+	MessageCount   int               `json:"message_count"`
+	Files          []ManifestFile    `json:"files"`
+}
+
+// ManifestFile is the manifest entry of a single file in a bundle.
+type ManifestFile struct {
+	Name   string `json:"name"`
+	Size   int64  `json:"size"`
+	SHA256 string `json:"sha256"`
+}
+
+// Bundle is a zip archive being written by a BundleExporter. It records the
+// size and SHA-256 checksum of every file for the manifest.
+type Bundle struct {
+	zw      *zip.Writer
+	files   []ManifestFile
+	current *bundleFile
+}
+
+// bundleFile is the file of a bundle that is currently being written.
+type bundleFile struct {
+	name string
+	size int64
+	hash hash.Hash
+	w    io.Writer
+}
+
+// Write writes p to the file and its checksum.
+func (f *bundleFile) Write(p []byte) (int, error) {
+	n, err := f.w.Write(p)
+	f.hash.Write(p[:n])
+	f.size += int64(n)
+	return n, err
+}
+
+// Create adds a file to the bundle and returns a writer for its contents. The
+// previous file is finished and must not be written to anymore.
+func (b *Bundle) Create(name string) (io.Writer, error) {
+	b.finishFile()
+
+	w, err := b.zw.Create(name)
+	if err != nil {
+		return nil, err
+	}
+
+	b.current = &bundleFile{name: name, hash: sha256.New(), w: w}
+	return b.current, nil
+}
+
+// finishFile records the file currently being written in the manifest.
+func (b *Bundle) finishFile() {
+	if b.current == nil {
+		return
+	}
+
+	b.files = append(b.files, ManifestFile{
+		Name:   b.current.name,
+		Size:   b.current.size,
+		SHA256: hex.EncodeToString(b.current.hash.Sum(nil)),
+	})
+	b.current = nil
+}
+
+// writeBundle writes a zip archive with the files of exporter and a manifest to w.
+func writeBundle(w io.Writer, exporter BundleExporter, info ExportInfo, source ChatSource) error {
+	bundle := &Bundle{zw: zip.NewWriter(w)}
+	counts := &sourceCounts{}
+
+	if err := exporter.ExportBundle(bundle, info, countingSource(source, counts)); err != nil {
+		return err
+	}
+	bundle.finishFile()
+
+	manifest := Manifest{
+		OrganizationID: info.OrganizationID,
+		ExportType:     info.ExportType,
+		ExportDate:     info.ExportDate.UTC(),
+		ChatCount:      counts.chats,
+		MessageCount:   counts.messages,
+		Files:          bundle.files,
+	}
+
+	entry, err := bundle.zw.Create(ManifestName)
+	if err != nil {
+		return err
+	}
+
+	encoder := json.NewEncoder(entry)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(manifest); err != nil {
+		return err
+	}
+
+	return bundle.zw.Close()
+}
+
+// sourceCounts holds the number of chats and messages read in a pass over a source.
+type sourceCounts struct {
+	chats    int
+	messages int
+}
+
+// countingSource wraps source so that counts reflects the last pass over it.
+// Bundles may read their source several times, once per file.
+func countingSource(source ChatSource, counts *sourceCounts) ChatSource {
+	return func(fn func(chat *domain.Chat) error) error {
+		*counts = sourceCounts{}
+
+		return source(func(chat *domain.Chat) error {
+			counts.chats++
+			counts.messages += len(chat.Messages)
+			return fn(chat)
+		})
+	}
+}
+
+// singleFileBundle adapts an exporter that writes a single document to the
+// BundleExporter interface.
+type singleFileBundle struct {
+	exporter Exporter
+}
+
+// ExportBundle writes the document of the wrapped exporter as the only file of the bundle.
+func (s singleFileBundle) ExportBundle(bundle *Bundle, info ExportInfo, source ChatSource) error {
+	w, err := bundle.Create("export" + s.exporter.Extension())
+	if err != nil {
+		return err
+	}
+	return s.exporter.Export(w, info, source)
+}
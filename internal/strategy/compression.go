@@ -0,0 +1,89 @@
+package strategy
+
+import (
+	"compress/gzip"
+	"fmt"
+	"io"
+
+	"github.com/kjanat/chatlogger-api-go/internal/domain"
+)
+
+// Compress returns an exporter that writes the output of exporter with the given
+// compression. Exporters are returned unchanged when no compression is requested.
+func Compress(exporter Exporter, compression domain.ExportCompression) (Exporter, error) {
+	switch compression {
+	case "", domain.ExportCompressionNone:
+		return exporter, nil
+	case domain.ExportCompressionGzip:
+		return &GzipExporter{exporter: exporter}, nil
+	case domain.ExportCompressionZip:
+		return &ZipExporter{exporter: exporter}, nil
+	default:
+		return nil, fmt.Errorf("unsupported export compression: %s", compression)
+	}
+}
+
+// wrappedExporter is implemented by exporters that decorate another exporter.
+type wrappedExporter interface {
+	unwrap() Exporter
+}
+
+// GzipExporter compresses the output of another exporter with gzip.
+type GzipExporter struct {
+	exporter Exporter
+}
+
+// Export exports data with the wrapped exporter through a gzip stream.
+func (g *GzipExporter) Export(w io.Writer, info ExportInfo, source ChatSource) error {
+	gw := gzip.NewWriter(w)
+	if err := g.exporter.Export(gw, info, source); err != nil {
+		return err
+	}
+	return gw.Close()
+}
+
+// ContentType returns the MIME type of gzip files.
+func (g *GzipExporter) ContentType() string {
+	return "application/gzip"
+}
+
+// Extension returns the extension of the wrapped exporter followed by ".gz".
+func (g *GzipExporter) Extension() string {
+	return g.exporter.Extension() + ".gz"
+}
+
+// unwrap returns the exporter whose output is compressed.
+func (g *GzipExporter) unwrap() Exporter {
+	return g.exporter
+}
+
+// ZipExporter archives the output of another exporter as a zip bundle with a
+// manifest.json listing chat and message counts and the checksum of every file.
+// Exporters implementing BundleExporter, such as CSV, split their output into
+// several files; any other output becomes a single file of the bundle.
+type ZipExporter struct {
+	exporter Exporter
+}
+
+// Export exports data as a zip bundle.
+func (z *ZipExporter) Export(w io.Writer, info ExportInfo, source ChatSource) error {
+	if bundler, ok := z.exporter.(BundleExporter); ok {
+		return writeBundle(w, bundler, info, source)
+	}
+	return writeBundle(w, singleFileBundle{exporter: z.exporter}, info, source)
+}
+
+// ContentType returns the MIME type of zip bundles.
+func (z *ZipExporter) ContentType() string {
+	return "application/zip"
+}
+
+// Extension returns the file extension of zip bundles.
+func (z *ZipExporter) Extension() string {
+	return ".zip"
+}
+
+// unwrap returns the exporter whose output is archived.
+func (z *ZipExporter) unwrap() Exporter {
+	return z.exporter
+}
@@ -0,0 +1,317 @@
+package strategy
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/kjanat/chatlogger-api-go/internal/domain"
+)
+
+// csvRow is the chat, and for message-level rows the message, a CSV row is written for.
+type csvRow struct {
+	chat            *domain.Chat
+	tags            []string
+	metadata        *domain.ChatMetadata
+	message         *domain.Message
+	messageMetadata *domain.MessageMetadata
+}
+
+// csvColumn is a column of CSV exports. Message columns are empty in rows of
+// chats without messages.
+type csvColumn struct {
+	name    string
+	message bool
+	value   func(row *csvRow) string
+}
+
+// csvColumns lists every CSV column in default output order: the chat fields,
+// the flattened chat metadata, then the message fields and flattened message
+// metadata. Metadata columns are named after their JSON field, message
+// metadata columns are prefixed with "message_".
+var csvColumns = buildCSVColumns()
+
+// csvColumnsByName indexes csvColumns by column name.
+var csvColumnsByName = func() map[string]csvColumn {
+	byName := make(map[string]csvColumn, len(csvColumns))
+	for _, column := range csvColumns {
+		byName[column.name] = column
+	}
+	return byName
+}()
+
+// buildCSVColumns creates the column list of CSV exports.
+func buildCSVColumns() []csvColumn {
+	columns := []csvColumn{
+		{name: "chat_id", value: func(r *csvRow) string { return strconv.FormatUint(r.chat.ID, 10) }},
+		{name: "organization_id", value: func(r *csvRow) string { return strconv.FormatUint(r.chat.OrganizationID, 10) }},
+		{name: "user_id", value: func(r *csvRow) string {
+			if r.chat.UserID == nil {
+				return ""
+			}
+			return strconv.FormatUint(*r.chat.UserID, 10)
+		}},
+		{name: "title", value: func(r *csvRow) string { return r.chat.Title }},
+		{name: "tags", value: func(r *csvRow) string { return strings.Join(r.tags, ";") }},
+		{name: "created_at", value: func(r *csvRow) string { return r.chat.CreatedAt.Format(time.RFC3339) }},
+		{name: "updated_at", value: func(r *csvRow) string { return r.chat.UpdatedAt.Format(time.RFC3339) }},
+	}
+
+	columns = append(columns, metadataColumns("", false, reflect.TypeOf(domain.ChatMetadata{}),
+		func(r *csvRow) any { return r.metadata })...)
+
+	columns = append(columns,
+		csvColumn{name: "message_id", message: true, value: func(r *csvRow) string { return strconv.FormatUint(r.message.ID, 10) }},
+		csvColumn{name: "message_role", message: true, value: func(r *csvRow) string { return string(r.message.Role) }},
+		csvColumn{name: "message_content", message: true, value: func(r *csvRow) string { return r.message.Content }},
+		csvColumn{name: "message_created_at", message: true, value: func(r *csvRow) string { return r.message.CreatedAt.Format(time.RFC3339) }},
+	)
+
+	return append(columns, metadataColumns("message_", true, reflect.TypeOf(domain.MessageMetadata{}),
+		func(r *csvRow) any { return r.messageMetadata })...)
+}
+
+// metadataColumns creates a column for every field of metadataType, read from
+// the struct pointer returned by metadata, so new metadata fields are exported
+// without changes here.
+func metadataColumns(
+	prefix string,
+	message bool,
+	metadataType reflect.Type,
+	metadata func(r *csvRow) any,
+) []csvColumn {
+	columns := make([]csvColumn, 0, metadataType.NumField())
+	for i := 0; i < metadataType.NumField(); i++ {
+		name, _, _ := strings.Cut(metadataType.Field(i).Tag.Get("json"), ",")
+		if name == "" || name == "-" {
+			continue
+		}
+
+		index := i
+		columns = append(columns, csvColumn{
+			name:    prefix + name,
+			message: message,
+			value: func(r *csvRow) string {
+				return formatCSVValue(reflect.ValueOf(metadata(r)).Elem().Field(index))
+			},
+		})
+	}
+	return columns
+}
+
+// formatCSVValue formats a metadata field for CSV. Nil pointers are empty.
+func formatCSVValue(v reflect.Value) string {
+	if v.Kind() == reflect.Pointer {
+		if v.IsNil() {
+			return ""
+		}
+		v = v.Elem()
+	}
+
+	switch v.Kind() {
+	case reflect.String:
+		return v.String()
+	case reflect.Bool:
+		return strconv.FormatBool(v.Bool())
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(v.Int(), 10)
+	case reflect.Float32, reflect.Float64:
+		return strconv.FormatFloat(v.Float(), 'f', -1, 64)
+	default:
+		return fmt.Sprint(v.Interface())
+	}
+}
+
+// ValidateCSVColumns checks that every name is a known CSV column that applies
+// to the export type.
+func ValidateCSVColumns(names []string, exportType domain.ExportType) error {
+	_, err := (&CSVExporter{Columns: names}).columns(exportType)
+	return err
+}
+
+// CSVExporter implements the Exporter interface for CSV format.
+// Chat exports are written with one row per chat. Message exports are written
+// with one row per message; "all" exports additionally write a row for each
+// chat without messages.
+type CSVExporter struct {
+	// Columns selects the columns and their order; empty selects every column
+	// that applies to the export type.
+	Columns []string
+}
+
+// columns resolves the columns of an export. Without a selection, chat exports
+// get every chat column, message exports the chat ID and every message column,
+// and "all" exports every column.
+func (c *CSVExporter) columns(exportType domain.ExportType) ([]csvColumn, error) {
+	if len(c.Columns) == 0 {
+		var columns []csvColumn
+		for _, column := range csvColumns {
+			switch {
+			case exportType == domain.ExportTypeChats && column.message:
+			case exportType == domain.ExportTypeMessages && !column.message && column.name != "chat_id":
+			default:
+				columns = append(columns, column)
+			}
+		}
+		return columns, nil
+	}
+
+	columns := make([]csvColumn, 0, len(c.Columns))
+	for _, name := range c.Columns {
+		column, ok := csvColumnsByName[name]
+		if !ok {
+			return nil, fmt.Errorf("unknown csv column %q", name)
+		}
+		if column.message && !exportType.IncludesMessages() {
+			return nil, fmt.Errorf("csv column %q requires a messages or all export", name)
+		}
+		columns = append(columns, column)
+	}
+	return columns, nil
+}
+
+// Export exports data to CSV format.
+func (c *CSVExporter) Export(w io.Writer, info ExportInfo, source ChatSource) error {
+	columns, err := c.columns(info.ExportType)
+	if err != nil {
+		return err
+	}
+
+	switch info.ExportType {
+	case domain.ExportTypeChats:
+		return writeCSV(w, columns, source, false, false)
+	case domain.ExportTypeMessages:
+		return writeCSV(w, columns, source, true, false)
+	default:
+		return writeCSV(w, columns, source, true, true)
+	}
+}
+
+// ExportBundle exports data as two CSV files: chats.csv with one row per chat
+// and, when the export type includes messages, messages.csv with one row per
+// message referencing its chat by ID. Selected columns are split between the
+// files; a file without selected columns gets its default columns.
+func (c *CSVExporter) ExportBundle(bundle *Bundle, info ExportInfo, source ChatSource) error {
+	var chatNames, messageNames []string
+	for _, name := range c.Columns {
+		if column, ok := csvColumnsByName[name]; ok && column.message {
+			messageNames = append(messageNames, name)
+		} else {
+			chatNames = append(chatNames, name)
+		}
+	}
+
+	chatColumns, err := (&CSVExporter{Columns: chatNames}).columns(domain.ExportTypeChats)
+	if err != nil {
+		return err
+	}
+
+	chatsFile, err := bundle.Create("chats.csv")
+	if err != nil {
+		return err
+	}
+	if err := writeCSV(chatsFile, chatColumns, source, false, false); err != nil {
+		return err
+	}
+
+	if !info.ExportType.IncludesMessages() {
+		return nil
+	}
+
+	// Keep the reference to the chat
+	if len(messageNames) > 0 {
+		messageNames = append([]string{"chat_id"}, messageNames...)
+	}
+	messageColumns, err := (&CSVExporter{Columns: messageNames}).columns(domain.ExportTypeMessages)
+	if err != nil {
+		return err
+	}
+
+	messagesFile, err := bundle.Create("messages.csv")
+	if err != nil {
+		return err
+	}
+	return writeCSV(messagesFile, messageColumns, source, true, false)
+}
+
+// writeCSV writes a header and one row per chat, or one row per message when
+// perMessage is set. With emptyChats, chats without messages still get a row
+// when writing per message.
+func writeCSV(w io.Writer, columns []csvColumn, source ChatSource, perMessage, emptyChats bool) error {
+	writer := csv.NewWriter(w)
+
+	header := make([]string, len(columns))
+	for i, column := range columns {
+		header[i] = column.name
+	}
+	if err := writer.Write(header); err != nil {
+		return err
+	}
+
+	record := make([]string, len(columns))
+	writeRow := func(row *csvRow) error {
+		for i, column := range columns {
+			if column.message && row.message == nil {
+				record[i] = ""
+				continue
+			}
+			record[i] = column.value(row)
+		}
+		return writer.Write(record)
+	}
+
+	err := source(func(chat *domain.Chat) error {
+		row := &csvRow{chat: chat}
+
+		// Malformed tags or metadata are exported as empty columns
+		row.tags, _ = chat.GetTags()
+		if metadata, err := chat.GetMetadata(); err == nil {
+			row.metadata = metadata
+		} else {
+			row.metadata = &domain.ChatMetadata{}
+		}
+
+		if !perMessage || (emptyChats && len(chat.Messages) == 0) {
+			if err := writeRow(row); err != nil {
+				return err
+			}
+		} else {
+			for i := range chat.Messages {
+				row.message = &chat.Messages[i]
+				if metadata, err := row.message.GetMetadata(); err == nil {
+					row.messageMetadata = metadata
+				} else {
+					row.messageMetadata = &domain.MessageMetadata{}
+				}
+
+				if err := writeRow(row); err != nil {
+					return err
+				}
+			}
+		}
+
+		// Flush per chat so the writer never buffers more than one conversation
+		writer.Flush()
+		return writer.Error()
+	})
+	if err != nil {
+		return err
+	}
+
+	writer.Flush()
+	return writer.Error()
+}
+
+// ContentType returns the MIME type of CSV exports.
+func (c *CSVExporter) ContentType() string {
+	return "text/csv"
+}
+
+// Extension returns the file extension of CSV exports.
+func (c *CSVExporter) Extension() string {
+	return ".csv"
+}
@@ -0,0 +1,76 @@
+package strategy
+
+import (
+	"fmt"
+	"io"
+
+	"filippo.io/age"
+
+	"github.com/kjanat/chatlogger-api-go/internal/domain"
+)
+
+// ParseRecipients parses age X25519 public keys.
+func ParseRecipients(keys []string) ([]age.Recipient, error) {
+	recipients := make([]age.Recipient, 0, len(keys))
+	for _, key := range keys {
+		recipient, err := age.ParseX25519Recipient(key)
+		if err != nil {
+			return nil, fmt.Errorf("invalid recipient %q: %w", key, err)
+		}
+		recipients = append(recipients, recipient)
+	}
+	return recipients, nil
+}
+
+// Encrypt returns an exporter that encrypts the output of exporter to the
+// recipients in options. Exporters are returned unchanged when options is nil.
+// Compression must be applied first, as encrypted data does not compress.
+func Encrypt(exporter Exporter, options *domain.EncryptionOptions) (Exporter, error) {
+	if options == nil {
+		return exporter, nil
+	}
+
+	recipients, err := ParseRecipients(options.Recipients)
+	if err != nil {
+		return nil, err
+	}
+	if len(recipients) == 0 {
+		return nil, fmt.Errorf("encryption requires at least one recipient")
+	}
+
+	return &EncryptedExporter{exporter: exporter, recipients: recipients}, nil
+}
+
+// EncryptedExporter encrypts the output of another exporter with age while it
+// is written, so no plaintext copy of the export is ever stored.
+type EncryptedExporter struct {
+	exporter   Exporter
+	recipients []age.Recipient
+}
+
+// Export exports data with the wrapped exporter through an age encryption stream.
+func (e *EncryptedExporter) Export(w io.Writer, info ExportInfo, source ChatSource) error {
+	ew, err := age.Encrypt(w, e.recipients...)
+	if err != nil {
+		return err
+	}
+	if err := e.exporter.Export(ew, info, source); err != nil {
+		return err
+	}
+	return ew.Close()
+}
+
+// ContentType returns the MIME type of age-encrypted files.
+func (e *EncryptedExporter) ContentType() string {
+	return "application/octet-stream"
+}
+
+// Extension returns the extension of the wrapped exporter followed by ".age".
+func (e *EncryptedExporter) Extension() string {
+	return e.exporter.Extension() + ".age"
+}
+
+// unwrap returns the exporter whose output is encrypted.
+func (e *EncryptedExporter) unwrap() Exporter {
+	return e.exporter
+}
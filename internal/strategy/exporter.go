@@ -1,121 +1,196 @@
 // Package strategy implements the Strategy Pattern for the ChatLogger API.
 // This file defines exporters that implement different strategies for data export
-// formats (JSON, CSV). It follows the Strategy Pattern to allow for runtime selection
-// of different export formats while maintaining a consistent interface.
+// formats (JSON, CSV, NDJSON, fine-tuning JSONL, Markdown, HTML, XLSX, Parquet), optionally
+// compressed with gzip or bundled in a zip archive. It follows the Strategy Pattern
+// to allow for runtime selection of different export formats while maintaining a
+// consistent interface.
 package strategy
 
 import (
-	"encoding/csv"
+	"bufio"
 	"encoding/json"
 	"fmt"
-	"strings"
+	"io"
 	"time"
 
 	"github.com/kjanat/chatlogger-api-go/internal/domain"
 )
 
+// ExportInfo describes the export being written. Formats with a document header
+// render it there; other formats may ignore it.
+type ExportInfo struct {
+	OrganizationID uint64
+	ExportType     domain.ExportType
+	ExportDate     time.Time
+	Filter         *domain.ExportFilter // Nil when the export is not filtered
+}
+
 // Exporter defines the interface for export strategies.
-// Implementations can export data in different formats (JSON, CSV, etc.).
+// Implementations stream data in different formats (JSON, CSV, etc.) to a writer,
+// one chat at a time, so that exports of any size run in constant memory.
 type Exporter interface {
-	// Export takes arbitrary data and exports it to a specific format.
-	// It returns the exported data as bytes and any error encountered.
-	Export(data interface{}) ([]byte, error)
+	// Export reads every chat from source and writes it to w in a specific format.
+	Export(w io.Writer, info ExportInfo, source ChatSource) error
+	// ContentType returns the MIME type of the exported data.
+	ContentType() string
+	// Extension returns the file extension of the exported data, including the dot.
+	Extension() string
 }
 
-// JSONExporter implements the Exporter interface for JSON format.
-type JSONExporter struct{}
+// NewExporter returns the export strategy for the given format, configured with
+// the format-specific settings in options. Options may be nil.
+func NewExporter(format domain.ExportFormat, options *domain.ExportOptions) (Exporter, error) {
+	if options == nil {
+		options = &domain.ExportOptions{}
+	}
 
-// Export exports data to JSON format.
-func (j *JSONExporter) Export(data interface{}) ([]byte, error) {
-	return json.MarshalIndent(data, "", "  ")
+	switch format {
+	case domain.ExportFormatJSON:
+		return &JSONExporter{}, nil
+	case domain.ExportFormatCSV:
+		exporter := &CSVExporter{}
+		if options.CSV != nil {
+			exporter.Columns = options.CSV.Columns
+		}
+		return exporter, nil
+	case domain.ExportFormatNDJSON:
+		return &NDJSONExporter{}, nil
+	case domain.ExportFormatNDJSONMessages:
+		return &NDJSONMessagesExporter{}, nil
+	case domain.ExportFormatFineTune:
+		exporter := &FineTuneExporter{}
+		if options.FineTune != nil {
+			exporter.Options = *options.FineTune
+		}
+		return exporter, nil
+	case domain.ExportFormatMarkdown:
+		return &MarkdownExporter{}, nil
+	case domain.ExportFormatHTML:
+		return &HTMLExporter{}, nil
+	case domain.ExportFormatXLSX:
+		return &XLSXExporter{}, nil
+	case domain.ExportFormatParquet:
+		return &ParquetExporter{}, nil
+	default:
+		return nil, fmt.Errorf("unsupported export format: %s", format)
+	}
 }
 
-// CSVExporter implements the Exporter interface for CSV format.
-type CSVExporter struct{}
+// messageExporter is implemented by exporters that cannot do without chat
+// messages, whatever the export type.
+type messageExporter interface {
+	needsMessages()
+}
+
+// IncludesMessages reports whether chat messages must be loaded to export
+// exportType with exporter.
+func IncludesMessages(exporter Exporter, exportType domain.ExportType) bool {
+	if wrapped, ok := exporter.(wrappedExporter); ok {
+		return IncludesMessages(wrapped.unwrap(), exportType)
+	}
+	if _, ok := exporter.(messageExporter); ok {
+		return true
+	}
+	return exportType.IncludesMessages()
+}
 
-// Export exports data to CSV format.
-// It expects 'data' to be a map with at least a 'chats' key containing an array of chats.
-func (c *CSVExporter) Export(data interface{}) ([]byte, error) {
-	// Convert data to map
-	dataMap, ok := data.(map[string]interface{})
-	if !ok {
-		return nil, fmt.Errorf("data must be a map[string]interface{}")
+// ContentType returns the MIME type of the file of an export, taking its
+// compression and encryption into account.
+func ContentType(export *domain.Export) string {
+	options, err := export.GetOptions()
+	if err != nil || options.Encryption != nil {
+		return "application/octet-stream"
 	}
 
-	// Get chats from data
-	chatsInterface, ok := dataMap["chats"]
-	if !ok {
-		return nil, fmt.Errorf("data must contain a 'chats' key")
+	exporter, err := NewExporter(export.Format, options)
+	if err != nil {
+		return "application/octet-stream"
+	}
+	if exporter, err = Compress(exporter, export.Compression); err != nil {
+		return "application/octet-stream"
 	}
+	return exporter.ContentType()
+}
+
+// JSONExporter implements the Exporter interface for JSON format.
+type JSONExporter struct{}
 
-	chats, ok := chatsInterface.([]domain.Chat)
-	if !ok {
-		return nil, fmt.Errorf("'chats' must be an array of domain.Chat")
+// Export exports data to JSON format.
+// The document is a single indented object with the export details and a "chats"
+// array, written element by element as chats arrive from the source.
+func (j *JSONExporter) Export(w io.Writer, info ExportInfo, source ChatSource) error {
+	bw := bufio.NewWriter(w)
+
+	exportType, err := json.Marshal(info.ExportType)
+	if err != nil {
+		return err
 	}
 
-	// Create CSV writer
-	var sb strings.Builder
-	writer := csv.NewWriter(&sb)
+	if _, err := fmt.Fprintf(bw,
+		"{\n  \"organization_id\": %d,\n  \"export_date\": %q,\n  \"export_type\": %s,",
+		info.OrganizationID,
+		info.ExportDate.Format(time.RFC3339),
+		exportType,
+	); err != nil {
+		return err
+	}
 
-	// Write header
-	header := []string{
-		"Chat ID", "Organization ID", "User ID", "Title", "Created At",
-		"Message ID", "Role", "Content", "Timestamp", "Token Count", "Latency",
+	if info.Filter != nil {
+		filterJSON, err := json.MarshalIndent(info.Filter, "  ", "  ")
+		if err != nil {
+			return err
+		}
+		if _, err := fmt.Fprintf(bw, "\n  \"filters\": %s,", filterJSON); err != nil {
+			return err
+		}
 	}
-	if err := writer.Write(header); err != nil {
-		return nil, err
+
+	if _, err := bw.WriteString("\n  \"chats\": ["); err != nil {
+		return err
 	}
 
-	// Write data
-	for _, chat := range chats {
-		chatID := fmt.Sprintf("%d", chat.ID)
-		orgID := fmt.Sprintf("%d", chat.OrganizationID)
-		var userID string
-		if chat.UserID != nil {
-			userID = fmt.Sprintf("%d", *chat.UserID)
-		} else {
-			userID = "N/A"
+	first := true
+	err = source(func(chat *domain.Chat) error {
+		chatJSON, err := json.MarshalIndent(chat, "    ", "  ")
+		if err != nil {
+			return err
 		}
-		title := chat.Title
-		createdAt := chat.CreatedAt.Format(time.RFC3339)
-
-		// If no messages, write a row for just the chat
-		if len(chat.Messages) == 0 {
-			row := []string{chatID, orgID, userID, title, createdAt, "", "", "", "", "", ""}
-			if err := writer.Write(row); err != nil {
-				return nil, err
-			}
-			continue
+
+		separator := ",\n    "
+		if first {
+			separator = "\n    "
+			first = false
 		}
 
-		// Write a row for each message in the chat
-		for _, message := range chat.Messages {
-			messageID := fmt.Sprintf("%d", message.ID)
-			role := string(message.Role)
-			content := message.Content
-			timestamp := message.CreatedAt.Format(time.RFC3339)
-
-			// Extract token count and latency from metadata
-			meta, err := message.GetMetadata()
-			var tokenCount, latency string
-			if err == nil && meta != nil {
-				tokenCount = fmt.Sprintf("%d", meta.TokenCount)
-				latency = fmt.Sprintf("%.2f", meta.ResponseTime)
-			} else {
-				tokenCount = ""
-				latency = ""
-			}
-
-			row := []string{
-				chatID, orgID, userID, title, createdAt,
-				messageID, role, content, timestamp, tokenCount, latency,
-			}
-			if err := writer.Write(row); err != nil {
-				return nil, err
-			}
+		if _, err := bw.WriteString(separator); err != nil {
+			return err
 		}
+
+		_, err = bw.Write(chatJSON)
+		return err
+	})
+	if err != nil {
+		return err
 	}
 
-	writer.Flush()
-	return []byte(sb.String()), nil
+	closing := "\n  ]\n}\n"
+	if first {
+		closing = "]\n}\n"
+	}
+
+	if _, err := bw.WriteString(closing); err != nil {
+		return err
+	}
+
+	return bw.Flush()
+}
+
+// ContentType returns the MIME type of JSON exports.
+func (j *JSONExporter) ContentType() string {
+	return "application/json"
+}
+
+// Extension returns the file extension of JSON exports.
+func (j *JSONExporter) Extension() string {
+	return ".json"
 }
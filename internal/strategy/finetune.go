@@ -0,0 +1,105 @@
+package strategy
+
+import (
+	"bufio"
+	"encoding/json"
+	"io"
+
+	"github.com/kjanat/chatlogger-api-go/internal/domain"
+)
+
+// fineTuneMessage is a single turn in a fine-tuning example.
+type fineTuneMessage struct {
+	Role    domain.MessageRole `json:"role"`
+	Content string             `json:"content"`
+}
+
+// fineTuneExample is one line of a chat-completion fine-tuning dataset.
+type fineTuneExample struct {
+	Messages []fineTuneMessage `json:"messages"`
+}
+
+// FineTuneExporter implements the Exporter interface for chat-completion
+// fine-tuning datasets: one {"messages":[{"role":...,"content":...}]} object per
+// chat and line, as expected by common model fine-tuning tools.
+type FineTuneExporter struct {
+	Options domain.FineTuneOptions
+}
+
+// Export exports each eligible chat as a fine-tuning example.
+// Chats are skipped when they fail the rating or escalation options, or when no
+// assistant message is left to learn from.
+func (f *FineTuneExporter) Export(w io.Writer, info ExportInfo, source ChatSource) error {
+	bw := bufio.NewWriter(w)
+	encoder := json.NewEncoder(bw)
+
+	err := source(func(chat *domain.Chat) error {
+		if !f.includesChat(chat) {
+			return nil
+		}
+
+		example := fineTuneExample{Messages: make([]fineTuneMessage, 0, len(chat.Messages))}
+		hasAssistant := false
+
+		for _, message := range chat.Messages {
+			if message.Role == domain.MessageRoleSystem && f.Options.DropSystemMessages {
+				continue
+			}
+			if message.Role == domain.MessageRoleAssistant {
+				hasAssistant = true
+			}
+			example.Messages = append(example.Messages, fineTuneMessage{
+				Role:    message.Role,
+				Content: message.Content,
+			})
+		}
+
+		if !hasAssistant {
+			return nil
+		}
+
+		return encoder.Encode(example)
+	})
+	if err != nil {
+		return err
+	}
+
+	return bw.Flush()
+}
+
+// includesChat reports whether a chat passes the rating and escalation options.
+func (f *FineTuneExporter) includesChat(chat *domain.Chat) bool {
+	if f.Options.MinUserRating == 0 && !f.Options.ExcludeEscalated {
+		return true
+	}
+
+	metadata, err := chat.GetMetadata()
+	if err != nil {
+		// Without readable metadata we cannot prove the chat qualifies
+		return false
+	}
+
+	if f.Options.ExcludeEscalated && metadata.IsEscalated {
+		return false
+	}
+
+	if f.Options.MinUserRating > 0 &&
+		(metadata.UserRating == nil || *metadata.UserRating < f.Options.MinUserRating) {
+		return false
+	}
+
+	return true
+}
+
+// ContentType returns the MIME type of fine-tuning datasets.
+func (f *FineTuneExporter) ContentType() string {
+	return "application/jsonl"
+}
+
+// Extension returns the file extension of fine-tuning datasets.
+func (f *FineTuneExporter) Extension() string {
+	return ".jsonl"
+}
+
+// needsMessages marks the exporter as needing messages for every export type.
+func (f *FineTuneExporter) needsMessages() {}
@@ -0,0 +1,167 @@
+package strategy
+
+import (
+	"bufio"
+	"encoding/json"
+	"io"
+	"time"
+
+	"github.com/kjanat/chatlogger-api-go/internal/domain"
+)
+
+// ndjsonContentType is the MIME type of newline-delimited JSON.
+const ndjsonContentType = "application/x-ndjson"
+
+// chatRecord is the line representation of a chat in NDJSON exports.
+// Tags and metadata are embedded as JSON values instead of JSON-encoded strings
+// so that warehouses can load them without a second parsing step.
+type chatRecord struct {
+	ID             uint64          `json:"id"`
+	OrganizationID uint64          `json:"organization_id"`
+	UserID         *uint64         `json:"user_id"`
+	Title          string          `json:"title"`
+	Tags           json.RawMessage `json:"tags"`
+	Metadata       json.RawMessage `json:"metadata"`
+	CreatedAt      time.Time       `json:"created_at"`
+	UpdatedAt      time.Time       `json:"updated_at"`
+	Messages       []messageRecord `json:"messages,omitempty"`
+}
+
+// messageRecord is the representation of a message in NDJSON exports.
+type messageRecord struct {
+	ID        uint64             `json:"id"`
+	ChatID    uint64             `json:"chat_id"`
+	Role      domain.MessageRole `json:"role"`
+	Content   string             `json:"content"`
+	Metadata  json.RawMessage    `json:"metadata"`
+	CreatedAt time.Time          `json:"created_at"`
+}
+
+// denormalizedMessageRecord is a message line carrying the fields of its chat.
+type denormalizedMessageRecord struct {
+	messageRecord
+	OrganizationID uint64          `json:"organization_id"`
+	UserID         *uint64         `json:"user_id"`
+	ChatTitle      string          `json:"chat_title"`
+	ChatTags       json.RawMessage `json:"chat_tags"`
+	ChatMetadata   json.RawMessage `json:"chat_metadata"`
+	ChatCreatedAt  time.Time       `json:"chat_created_at"`
+}
+
+// NDJSONExporter implements the Exporter interface for newline-delimited JSON
+// with one chat per line and its messages embedded.
+type NDJSONExporter struct{}
+
+// Export exports data as one JSON object per chat, separated by newlines.
+func (n *NDJSONExporter) Export(w io.Writer, info ExportInfo, source ChatSource) error {
+	bw := bufio.NewWriter(w)
+	encoder := json.NewEncoder(bw)
+
+	err := source(func(chat *domain.Chat) error {
+		record := newChatRecord(chat)
+		for i := range chat.Messages {
+			record.Messages = append(record.Messages, newMessageRecord(&chat.Messages[i]))
+		}
+
+		return encoder.Encode(record)
+	})
+	if err != nil {
+		return err
+	}
+
+	return bw.Flush()
+}
+
+// ContentType returns the MIME type of NDJSON exports.
+func (n *NDJSONExporter) ContentType() string {
+	return ndjsonContentType
+}
+
+// Extension returns the file extension of NDJSON exports.
+func (n *NDJSONExporter) Extension() string {
+	return ".ndjson"
+}
+
+// NDJSONMessagesExporter implements the Exporter interface for newline-delimited
+// JSON with one message per line and the fields of its chat denormalized into it.
+type NDJSONMessagesExporter struct{}
+
+// Export exports data as one JSON object per message, separated by newlines.
+func (n *NDJSONMessagesExporter) Export(w io.Writer, info ExportInfo, source ChatSource) error {
+	bw := bufio.NewWriter(w)
+	encoder := json.NewEncoder(bw)
+
+	err := source(func(chat *domain.Chat) error {
+		chatFields := newChatRecord(chat)
+
+		for i := range chat.Messages {
+			record := denormalizedMessageRecord{
+				messageRecord:  newMessageRecord(&chat.Messages[i]),
+				OrganizationID: chatFields.OrganizationID,
+				UserID:         chatFields.UserID,
+				ChatTitle:      chatFields.Title,
+				ChatTags:       chatFields.Tags,
+				ChatMetadata:   chatFields.Metadata,
+				ChatCreatedAt:  chatFields.CreatedAt,
+			}
+			if err := encoder.Encode(record); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	return bw.Flush()
+}
+
+// ContentType returns the MIME type of NDJSON exports.
+func (n *NDJSONMessagesExporter) ContentType() string {
+	return ndjsonContentType
+}
+
+// Extension returns the file extension of NDJSON exports.
+func (n *NDJSONMessagesExporter) Extension() string {
+	return ".ndjson"
+}
+
+// needsMessages marks the exporter as writing one record per message.
+func (n *NDJSONMessagesExporter) needsMessages() {}
+
+// newChatRecord converts a chat to its NDJSON representation, without messages.
+func newChatRecord(chat *domain.Chat) chatRecord {
+	return chatRecord{
+		ID:             chat.ID,
+		OrganizationID: chat.OrganizationID,
+		UserID:         chat.UserID,
+		Title:          chat.Title,
+		Tags:           rawJSON(chat.Tags, "[]"),
+		Metadata:       rawJSON(chat.Metadata, "{}"),
+		CreatedAt:      chat.CreatedAt,
+		UpdatedAt:      chat.UpdatedAt,
+	}
+}
+
+// newMessageRecord converts a message to its NDJSON representation.
+func newMessageRecord(message *domain.Message) messageRecord {
+	return messageRecord{
+		ID:        message.ID,
+		ChatID:    message.ChatID,
+		Role:      message.Role,
+		Content:   message.Content,
+		Metadata:  rawJSON(message.Metadata, "{}"),
+		CreatedAt: message.CreatedAt,
+	}
+}
+
+// rawJSON returns a stored JSON string as a raw JSON value, or fallback when the
+// string is empty or not valid JSON.
+func rawJSON(value, fallback string) json.RawMessage {
+	if value == "" || value == "null" || !json.Valid([]byte(value)) {
+		return json.RawMessage(fallback)
+	}
+	return json.RawMessage(value)
+}
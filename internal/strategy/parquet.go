@@ -0,0 +1,189 @@
+package strategy
+
+import (
+	"io"
+	"time"
+
+	"github.com/parquet-go/parquet-go"
+
+	"github.com/kjanat/chatlogger-api-go/internal/domain"
+)
+
+// parquetRowGroupSize is the number of rows buffered per Parquet row group.
+const parquetRowGroupSize = 10000
+
+// parquetChat is the row of a chat in chats.parquet. Chat metadata is
+// flattened into typed columns.
+type parquetChat struct {
+	ID               uint64    `parquet:"id"`
+	OrganizationID   uint64    `parquet:"organization_id"`
+	UserID           *uint64   `parquet:"user_id,optional"`
+	Title            string    `parquet:"title"`
+	Tags             []string  `parquet:"tags,list"`
+	CreatedAt        time.Time `parquet:"created_at,timestamp(millisecond)"`
+	UpdatedAt        time.Time `parquet:"updated_at,timestamp(millisecond)"`
+	IPAddress        string    `parquet:"ip_address"`
+	CountryCode      string    `parquet:"country_code,dict"`
+	LanguageCode     string    `parquet:"language_code,dict"`
+	SessionID        string    `parquet:"session_id"`
+	Sentiment        string    `parquet:"sentiment,dict"`
+	IsEscalated      bool      `parquet:"is_escalated"`
+	IsForwardedToHR  bool      `parquet:"is_forwarded_to_hr"`
+	TranscriptLink   string    `parquet:"transcript_link"`
+	TokenCount       int64     `parquet:"token_count"`
+	AvgResponseTime  float64   `parquet:"avg_response_time"` // In seconds
+	QuestionCategory string    `parquet:"question_category,dict"`
+	UserRating       *int64    `parquet:"user_rating,optional"`
+}
+
+// parquetMessage is the row of a message in messages.parquet. Message
+// metadata is flattened into typed columns.
+type parquetMessage struct {
+	ID               uint64    `parquet:"id"`
+	ChatID           uint64    `parquet:"chat_id"`
+	Role             string    `parquet:"role,dict"`
+	Content          string    `parquet:"content"`
+	CreatedAt        time.Time `parquet:"created_at,timestamp(millisecond)"`
+	TokenCount       int64     `parquet:"token_count"`
+	ResponseTime     float64   `parquet:"response_time"` // In milliseconds
+	Model            string    `parquet:"model,dict"`
+	Provider         string    `parquet:"provider,dict"`
+	PromptTokens     int64     `parquet:"prompt_tokens"`
+	CompletionTokens int64     `parquet:"completion_tokens"`
+	CachedTokens     int64     `parquet:"cached_tokens"`
+}
+
+// newParquetChat converts a chat into its Parquet row.
+// Malformed tags or metadata are exported as empty columns.
+func newParquetChat(chat *domain.Chat) parquetChat {
+	row := parquetChat{
+		ID:             chat.ID,
+		OrganizationID: chat.OrganizationID,
+		UserID:         chat.UserID,
+		Title:          chat.Title,
+		CreatedAt:      chat.CreatedAt,
+		UpdatedAt:      chat.UpdatedAt,
+	}
+
+	if tags, err := chat.GetTags(); err == nil {
+		row.Tags = tags
+	}
+
+	if metadata, err := chat.GetMetadata(); err == nil {
+		row.IPAddress = metadata.IPAddress
+		row.CountryCode = metadata.CountryCode
+		row.LanguageCode = metadata.LanguageCode
+		row.SessionID = metadata.SessionID
+		row.Sentiment = metadata.Sentiment
+		row.IsEscalated = metadata.IsEscalated
+		row.IsForwardedToHR = metadata.IsForwardedToHR
+		row.TranscriptLink = metadata.TranscriptLink
+		row.TokenCount = int64(metadata.TokenCount)
+		row.AvgResponseTime = metadata.AvgResponseTime
+		row.QuestionCategory = metadata.QuestionCategory
+		if metadata.UserRating != nil {
+			rating := int64(*metadata.UserRating)
+			row.UserRating = &rating
+		}
+	}
+
+	return row
+}
+
+// newParquetMessage converts a message into its Parquet row.
+func newParquetMessage(message *domain.Message) parquetMessage {
+	row := parquetMessage{
+		ID:        message.ID,
+		ChatID:    message.ChatID,
+		Role:      string(message.Role),
+		Content:   message.Content,
+		CreatedAt: message.CreatedAt,
+	}
+
+	if metadata, err := message.GetMetadata(); err == nil {
+		row.TokenCount = int64(metadata.TokenCount)
+		row.ResponseTime = metadata.ResponseTime
+		row.Model = metadata.Model
+		row.Provider = metadata.Provider
+		row.PromptTokens = int64(metadata.PromptTokens)
+		row.CompletionTokens = int64(metadata.CompletionTokens)
+		row.CachedTokens = int64(metadata.CachedTokens)
+	}
+
+	return row
+}
+
+// ParquetExporter implements the Exporter interface for Apache Parquet.
+// The export is a zip bundle of chats.parquet and, when the export type
+// includes messages, messages.parquet, so warehouses such as DuckDB and Spark
+// can load both tables with their types.
+type ParquetExporter struct{}
+
+// Export exports data as a zip bundle of Parquet files.
+func (p *ParquetExporter) Export(w io.Writer, info ExportInfo, source ChatSource) error {
+	return writeBundle(w, p, info, source)
+}
+
+// ExportBundle writes chats.parquet and, when the export type includes
+// messages, messages.parquet into bundle. The source is read once per file.
+func (p *ParquetExporter) ExportBundle(bundle *Bundle, info ExportInfo, source ChatSource) error {
+	chatsFile, err := bundle.Create("chats.parquet")
+	if err != nil {
+		return err
+	}
+
+	chats := parquet.NewGenericWriter[parquetChat](chatsFile, parquetWriterOptions()...)
+	err = source(func(chat *domain.Chat) error {
+		_, err := chats.Write([]parquetChat{newParquetChat(chat)})
+		return err
+	})
+	if err != nil {
+		return err
+	}
+	if err := chats.Close(); err != nil {
+		return err
+	}
+
+	if !info.ExportType.IncludesMessages() {
+		return nil
+	}
+
+	messagesFile, err := bundle.Create("messages.parquet")
+	if err != nil {
+		return err
+	}
+
+	messages := parquet.NewGenericWriter[parquetMessage](messagesFile, parquetWriterOptions()...)
+	rows := make([]parquetMessage, 0, 64)
+	err = source(func(chat *domain.Chat) error {
+		rows = rows[:0]
+		for i := range chat.Messages {
+			rows = append(rows, newParquetMessage(&chat.Messages[i]))
+		}
+		_, err := messages.Write(rows)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+	return messages.Close()
+}
+
+// parquetWriterOptions returns the options of the Parquet file writers.
+func parquetWriterOptions() []parquet.WriterOption {
+	return []parquet.WriterOption{
+		parquet.Compression(&parquet.Snappy),
+		parquet.MaxRowsPerRowGroup(parquetRowGroupSize),
+		parquet.CreatedBy("chatlogger-api", "", ""),
+	}
+}
+
+// ContentType returns the MIME type of Parquet bundles.
+func (p *ParquetExporter) ContentType() string {
+	return "application/zip"
+}
+
+// Extension returns the file extension of Parquet bundles.
+func (p *ParquetExporter) Extension() string {
+	return ".zip"
+}
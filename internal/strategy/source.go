@@ -0,0 +1,78 @@
+// Package strategy implements the Strategy Pattern for the ChatLogger API.
+// This file defines the chat source exporters read from, which streams the chats
+// of an organization in keyset-paginated batches along with their messages.
+package strategy
+
+import (
+	"fmt"
+
+	"github.com/kjanat/chatlogger-api-go/internal/domain"
+)
+
+// ExportBatchSize is the number of chats loaded per database round trip while
+// streaming an export.
+const ExportBatchSize = 500
+
+// ChatSource streams chats to fn one at a time, in a stable order. A source may be
+// invoked more than once; every invocation starts again from the first chat.
+type ChatSource func(fn func(chat *domain.Chat) error) error
+
+// NewChatSource returns a ChatSource over the chats of an organization that match
+// filter, or over every chat when filter is nil. Chats are read in keyset-paginated
+// batches and, when includeMessages is set, the messages of each batch are loaded
+// with a single query.
+func NewChatSource(
+	chatService domain.ChatService,
+	messageService domain.MessageService,
+	orgID uint64,
+	filter *domain.ExportFilter,
+	includeMessages bool,
+) ChatSource {
+	var chatFilter *domain.ChatFilter
+	var roles []domain.MessageRole
+	if filter != nil {
+		chatFilter = &filter.ChatFilter
+		roles = filter.Roles
+	}
+
+	return func(fn func(chat *domain.Chat) error) error {
+		return chatService.IterateByOrganizationID(orgID, chatFilter, ExportBatchSize, func(chats []domain.Chat) error {
+			if includeMessages {
+				if err := attachMessages(messageService, chats, roles); err != nil {
+					return err
+				}
+			}
+
+			for i := range chats {
+				if err := fn(&chats[i]); err != nil {
+					return err
+				}
+			}
+
+			return nil
+		})
+	}
+}
+
+// attachMessages loads the messages of a batch of chats and assigns them to their chat.
+func attachMessages(messageService domain.MessageService, chats []domain.Chat, roles []domain.MessageRole) error {
+	chatIDs := make([]uint64, len(chats))
+	index := make(map[uint64]int, len(chats))
+	for i := range chats {
+		chatIDs[i] = chats[i].ID
+		index[chats[i].ID] = i
+	}
+
+	messages, err := messageService.GetByChatIDs(chatIDs, roles)
+	if err != nil {
+		return fmt.Errorf("failed to get messages: %w", err)
+	}
+
+	for _, message := range messages {
+		if i, ok := index[message.ChatID]; ok {
+			chats[i].Messages = append(chats[i].Messages, message)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,345 @@
+package strategy
+
+import (
+	"bufio"
+	"fmt"
+	"html/template"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/kjanat/chatlogger-api-go/internal/domain"
+)
+
+// transcriptTimeFormat is the timestamp layout used in human-readable transcripts.
+const transcriptTimeFormat = "2006-01-02 15:04:05 MST"
+
+// transcriptField is a labelled value in the metadata summary of a transcript.
+type transcriptField struct {
+	Label string
+	Value string
+}
+
+// transcriptMessage is a role-labelled, timestamped message of a transcript.
+type transcriptMessage struct {
+	Role      string
+	RoleClass string
+	Timestamp string
+	Content   string
+}
+
+// transcript is the human-readable view of a chat shared by the Markdown and
+// HTML exporters.
+type transcript struct {
+	ID        uint64
+	Title     string
+	CreatedAt string
+	Tags      []string
+	Summary   []transcriptField
+	Messages  []transcriptMessage
+}
+
+// newTranscript builds the transcript view of a chat. Unparseable tags or
+// metadata are left out rather than failing the whole export.
+func newTranscript(chat *domain.Chat) transcript {
+	t := transcript{
+		ID:        chat.ID,
+		Title:     chat.Title,
+		CreatedAt: chat.CreatedAt.UTC().Format(transcriptTimeFormat),
+	}
+	if t.Title == "" {
+		t.Title = fmt.Sprintf("Chat #%d", chat.ID)
+	}
+
+	if tags, err := chat.GetTags(); err == nil {
+		t.Tags = tags
+	}
+
+	if metadata, err := chat.GetMetadata(); err == nil {
+		t.Summary = metadataSummary(metadata)
+	}
+
+	for _, message := range chat.Messages {
+		t.Messages = append(t.Messages, transcriptMessage{
+			Role:      roleLabel(message.Role),
+			RoleClass: string(message.Role),
+			Timestamp: message.CreatedAt.UTC().Format(transcriptTimeFormat),
+			Content:   message.Content,
+		})
+	}
+
+	return t
+}
+
+// metadataSummary lists the chat metadata worth showing to a human reader.
+// The IP address is deliberately omitted from transcripts.
+func metadataSummary(metadata *domain.ChatMetadata) []transcriptField {
+	var fields []transcriptField
+	add := func(label, value string) {
+		if value != "" {
+			fields = append(fields, transcriptField{Label: label, Value: value})
+		}
+	}
+
+	add("Country", metadata.CountryCode)
+	add("Language", metadata.LanguageCode)
+	add("Session", metadata.SessionID)
+	add("Sentiment", metadata.Sentiment)
+	add("Category", metadata.QuestionCategory)
+	if metadata.UserRating != nil {
+		add("User rating", fmt.Sprintf("%d", *metadata.UserRating))
+	}
+	if metadata.IsEscalated {
+		add("Escalated", "yes")
+	}
+	if metadata.IsForwardedToHR {
+		add("Forwarded to HR", "yes")
+	}
+	if metadata.TokenCount > 0 {
+		add("Tokens", fmt.Sprintf("%d", metadata.TokenCount))
+	}
+	if metadata.AvgResponseTime > 0 {
+		add("Average response time", fmt.Sprintf("%.2fs", metadata.AvgResponseTime))
+	}
+	add("Transcript link", metadata.TranscriptLink)
+
+	return fields
+}
+
+// roleLabel returns the display label of a message role.
+func roleLabel(role domain.MessageRole) string {
+	switch role {
+	case domain.MessageRoleUser:
+		return "User"
+	case domain.MessageRoleAssistant:
+		return "Assistant"
+	case domain.MessageRoleSystem:
+		return "System"
+	default:
+		return string(role)
+	}
+}
+
+// MarkdownExporter implements the Exporter interface for Markdown transcripts.
+// Every chat becomes a section with its title, tags, metadata summary and
+// role-labelled, timestamped messages.
+type MarkdownExporter struct{}
+
+// Export exports data as a single Markdown document.
+func (m *MarkdownExporter) Export(w io.Writer, info ExportInfo, source ChatSource) error {
+	bw := bufio.NewWriter(w)
+	first := true
+
+	err := source(func(chat *domain.Chat) error {
+		if !first {
+			if _, err := bw.WriteString("\n---\n\n"); err != nil {
+				return err
+			}
+		}
+		first = false
+
+		return writeMarkdownTranscript(bw, newTranscript(chat))
+	})
+	if err != nil {
+		return err
+	}
+
+	return bw.Flush()
+}
+
+// writeMarkdownTranscript renders one transcript as Markdown.
+func writeMarkdownTranscript(w io.Writer, t transcript) error {
+	var sb strings.Builder
+
+	fmt.Fprintf(&sb, "# %s\n\n", t.Title)
+	fmt.Fprintf(&sb, "- **Chat ID:** %d\n", t.ID)
+	fmt.Fprintf(&sb, "- **Created:** %s\n", t.CreatedAt)
+	if len(t.Tags) > 0 {
+		fmt.Fprintf(&sb, "- **Tags:** %s\n", strings.Join(t.Tags, ", "))
+	}
+	for _, field := range t.Summary {
+		fmt.Fprintf(&sb, "- **%s:** %s\n", field.Label, field.Value)
+	}
+
+	if len(t.Messages) > 0 {
+		sb.WriteString("\n## Transcript\n")
+	}
+	for _, message := range t.Messages {
+		fmt.Fprintf(&sb, "\n**%s** · %s\n\n%s\n", message.Role, message.Timestamp, message.Content)
+	}
+
+	_, err := io.WriteString(w, sb.String())
+	return err
+}
+
+// ContentType returns the MIME type of Markdown exports.
+func (m *MarkdownExporter) ContentType() string {
+	return "text/markdown; charset=utf-8"
+}
+
+// Extension returns the file extension of Markdown exports.
+func (m *MarkdownExporter) Extension() string {
+	return ".md"
+}
+
+// needsMessages marks the exporter as needing messages for every export type.
+func (m *MarkdownExporter) needsMessages() {}
+
+// transcriptStyle is the stylesheet inlined into every HTML page so that each
+// file is readable on its own.
+const transcriptStyle = `
+body { font-family: -apple-system, "Segoe UI", Roboto, sans-serif; max-width: 860px; margin: 2rem auto; padding: 0 1rem; color: #1f2328; }
+h1 { font-size: 1.5rem; }
+dl { display: grid; grid-template-columns: max-content 1fr; gap: .25rem 1rem; }
+dt { font-weight: 600; }
+.tag { display: inline-block; background: #eef1f4; border-radius: 1rem; padding: 0 .6rem; margin-right: .25rem; }
+.message { border-radius: .5rem; padding: .75rem 1rem; margin: .75rem 0; background: #f6f8fa; }
+.message.user { background: #ddf4ff; }
+.message.system { background: #fff8c5; }
+.meta { font-size: .85rem; color: #59636e; margin-bottom: .25rem; }
+.content { white-space: pre-wrap; }
+table { border-collapse: collapse; width: 100%; }
+th, td { text-align: left; padding: .4rem .6rem; border-bottom: 1px solid #d1d9e0; }
+`
+
+// chatPageTemplate renders a single chat transcript as a self-contained HTML page.
+var chatPageTemplate = template.Must(template.New("chat").Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>{{.Title}}</title>
+<style>{{.Style}}</style>
+</head>
+<body>
+{{with .Transcript}}<h1>{{.Title}}</h1>
+<dl>
+<dt>Chat ID</dt><dd>{{.ID}}</dd>
+<dt>Created</dt><dd>{{.CreatedAt}}</dd>
+{{if .Tags}}<dt>Tags</dt><dd>{{range .Tags}}<span class="tag">{{.}}</span>{{end}}</dd>
+{{end}}{{range .Summary}}<dt>{{.Label}}</dt><dd>{{.Value}}</dd>
+{{end}}</dl>
+{{range .Messages}}<div class="message {{.RoleClass}}">
+<div class="meta"><strong>{{.Role}}</strong> · {{.Timestamp}}</div>
+<div class="content">{{.Content}}</div>
+</div>
+{{end}}{{end}}{{if .IndexLink}}<p><a href="index.html">&larr; All chats</a></p>
+{{end}}</body>
+</html>
+`))
+
+// chatPage is the data of chatPageTemplate.
+type chatPage struct {
+	Title      string
+	Style      template.CSS
+	Transcript transcript
+	IndexLink  bool
+}
+
+// indexHeaderTemplate and indexRowTemplate render the index page of an HTML bundle
+// row by row, so the index does not have to be held in memory.
+var (
+	indexHeaderTemplate = template.Must(template.New("index-header").Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>Chat transcripts</title>
+<style>{{.Style}}</style>
+</head>
+<body>
+<h1>Chat transcripts</h1>
+<p>Organization {{.OrganizationID}}, exported {{.ExportDate}}</p>
+<table>
+<thead><tr><th>Chat</th><th>Created</th><th>Tags</th><th>Messages</th></tr></thead>
+<tbody>
+`))
+	indexRowTemplate = template.Must(template.New("index-row").Parse(
+		`<tr><td><a href="{{.Href}}">{{.Transcript.Title}}</a></td><td>{{.Transcript.CreatedAt}}</td>` +
+			`<td>{{range .Transcript.Tags}}<span class="tag">{{.}}</span>{{end}}</td><td>{{len .Transcript.Messages}}</td></tr>
+`))
+)
+
+// indexFooter closes the index page of an HTML bundle.
+const indexFooter = "</tbody>\n</table>\n</body>\n</html>\n"
+
+// HTMLExporter implements the Exporter and BundleExporter interfaces for HTML
+// transcripts. The output is a zip archive with one self-contained page per chat
+// and an index.html linking to all of them.
+type HTMLExporter struct{}
+
+// Export exports data as a zip archive of HTML pages.
+func (h *HTMLExporter) Export(w io.Writer, info ExportInfo, source ChatSource) error {
+	return writeBundle(w, h, info, source)
+}
+
+// ExportBundle writes one HTML page per chat followed by the index page into bundle.
+// The source is read twice, once for the pages and once for the index, so that
+// memory use does not grow with the number of chats.
+func (h *HTMLExporter) ExportBundle(bundle *Bundle, info ExportInfo, source ChatSource) error {
+	err := source(func(chat *domain.Chat) error {
+		t := newTranscript(chat)
+
+		entry, err := bundle.Create(chatPageName(chat.ID))
+		if err != nil {
+			return err
+		}
+
+		return chatPageTemplate.Execute(entry, chatPage{
+			Title:      t.Title,
+			Style:      template.CSS(transcriptStyle),
+			Transcript: t,
+			IndexLink:  true,
+		})
+	})
+	if err != nil {
+		return err
+	}
+
+	index, err := bundle.Create("index.html")
+	if err != nil {
+		return err
+	}
+
+	bw := bufio.NewWriter(index)
+	err = indexHeaderTemplate.Execute(bw, map[string]any{
+		"Style":          template.CSS(transcriptStyle),
+		"OrganizationID": info.OrganizationID,
+		"ExportDate":     info.ExportDate.UTC().Format(time.RFC3339),
+	})
+	if err != nil {
+		return err
+	}
+
+	err = source(func(chat *domain.Chat) error {
+		return indexRowTemplate.Execute(bw, map[string]any{
+			"Href":       chatPageName(chat.ID),
+			"Transcript": newTranscript(chat),
+		})
+	})
+	if err != nil {
+		return err
+	}
+
+	if _, err := bw.WriteString(indexFooter); err != nil {
+		return err
+	}
+
+	return bw.Flush()
+}
+
+// chatPageName returns the bundle entry name of a chat's page.
+func chatPageName(chatID uint64) string {
+	return fmt.Sprintf("chat-%d.html", chatID)
+}
+
+// ContentType returns the MIME type of HTML transcript bundles.
+func (h *HTMLExporter) ContentType() string {
+	return "application/zip"
+}
+
+// Extension returns the file extension of HTML transcript bundles.
+func (h *HTMLExporter) Extension() string {
+	return ".zip"
+}
+
+// needsMessages marks the exporter as needing messages for every export type.
+func (h *HTMLExporter) needsMessages() {}
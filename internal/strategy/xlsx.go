@@ -0,0 +1,403 @@
+package strategy
+
+import (
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/xuri/excelize/v2"
+
+	"github.com/kjanat/chatlogger-api-go/internal/domain"
+)
+
+// Sheet names of XLSX exports.
+const (
+	xlsxSummarySheet  = "Summary"
+	xlsxChatsSheet    = "Chats"
+	xlsxMessagesSheet = "Messages"
+)
+
+// xlsxMaxCellChars is the most characters Excel allows in a cell.
+const xlsxMaxCellChars = 32767
+
+// xlsxMaxRows is the most rows Excel allows in a sheet, including the header.
+const xlsxMaxRows = excelize.TotalRows
+
+// XLSXExporter implements the Exporter interface for Excel workbooks.
+// The workbook has a Summary sheet with counts, a Chats sheet with one row per
+// chat and, when the export type includes messages, a Messages sheet with one
+// row per message. Rows are streamed sheet by sheet, so only the rows of the
+// current chat are held in memory; the source is read once per sheet. Rows
+// beyond the row limit of Excel continue on "Chats (2)", "Messages (2)" and so on.
+type XLSXExporter struct{}
+
+// Export exports data to an XLSX workbook.
+func (x *XLSXExporter) Export(w io.Writer, info ExportInfo, source ChatSource) error {
+	f := excelize.NewFile()
+	defer f.Close()
+
+	// The default sheet becomes the summary, which is filled in last
+	if err := f.SetSheetName(f.GetSheetName(0), xlsxSummarySheet); err != nil {
+		return err
+	}
+
+	headerStyle, err := f.NewStyle(&excelize.Style{Font: &excelize.Font{Bold: true}})
+	if err != nil {
+		return err
+	}
+	dateStyle, err := f.NewStyle(&excelize.Style{CustomNumFmt: stringPtr("yyyy-mm-dd hh:mm:ss")})
+	if err != nil {
+		return err
+	}
+	styles := xlsxStyles{header: headerStyle, date: dateStyle}
+
+	chatCount, err := writeChatsSheet(f, styles, source)
+	if err != nil {
+		return err
+	}
+
+	messageCount := 0
+	if info.ExportType.IncludesMessages() {
+		if messageCount, err = writeMessagesSheet(f, styles, source); err != nil {
+			return err
+		}
+	}
+
+	if err := writeSummarySheet(f, styles, info, chatCount, messageCount); err != nil {
+		return err
+	}
+
+	_, err = f.WriteTo(w)
+	return err
+}
+
+// xlsxStyles holds the cell styles of a workbook.
+type xlsxStyles struct {
+	header int
+	date   int
+}
+
+// chatMetadataHeaders lists the headers of the ChatMetadata columns, derived
+// from their JSON field names, e.g. "Country Code" for country_code.
+var chatMetadataHeaders = func() []string {
+	metadataType := reflect.TypeOf(domain.ChatMetadata{})
+	headers := make([]string, 0, metadataType.NumField())
+	for i := 0; i < metadataType.NumField(); i++ {
+		name, _, _ := strings.Cut(metadataType.Field(i).Tag.Get("json"), ",")
+		words := strings.Split(name, "_")
+		for j, word := range words {
+			switch word {
+			case "ip", "hr", "id":
+				words[j] = strings.ToUpper(word)
+			default:
+				words[j] = strings.ToUpper(word[:1]) + word[1:]
+			}
+		}
+		headers = append(headers, strings.Join(words, " "))
+	}
+	return headers
+}()
+
+// writeChatsSheet streams one row per chat into the Chats sheet and returns the number of chats.
+func writeChatsSheet(f *excelize.File, styles xlsxStyles, source ChatSource) (int, error) {
+	header := append([]string{"Chat ID", "Organization ID", "User ID", "Title", "Tags", "Created At", "Updated At"},
+		chatMetadataHeaders...)
+	sheet, err := newXLSXSheet(f, xlsxChatsSheet, styles, header, 4, 40)
+	if err != nil {
+		return 0, err
+	}
+
+	count := 0
+	err = source(func(chat *domain.Chat) error {
+		var userID any
+		if chat.UserID != nil {
+			userID = *chat.UserID
+		}
+
+		// Malformed tags or metadata are exported as empty cells
+		tags, _ := chat.GetTags()
+		metadata, err := chat.GetMetadata()
+		if err != nil {
+			metadata = &domain.ChatMetadata{}
+		}
+
+		row := []any{
+			chat.ID,
+			chat.OrganizationID,
+			userID,
+			xlsxText(chat.Title),
+			xlsxText(strings.Join(tags, ", ")),
+			xlsxDate(styles, chat.CreatedAt),
+			xlsxDate(styles, chat.UpdatedAt),
+		}
+
+		fields := reflect.ValueOf(metadata).Elem()
+		for i := 0; i < fields.NumField(); i++ {
+			field := fields.Field(i)
+			if field.Kind() == reflect.Pointer {
+				if field.IsNil() {
+					row = append(row, nil)
+					continue
+				}
+				field = field.Elem()
+			}
+			if field.Kind() == reflect.String {
+				row = append(row, xlsxText(field.String()))
+			} else {
+				row = append(row, field.Interface())
+			}
+		}
+
+		count++
+		return sheet.AddRow(row)
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	return count, sheet.Flush()
+}
+
+// writeMessagesSheet streams one row per message into the Messages sheet and
+// returns the number of messages.
+func writeMessagesSheet(f *excelize.File, styles xlsxStyles, source ChatSource) (int, error) {
+	header := []string{
+		"Message ID", "Chat ID", "Role", "Content", "Created At", "Token Count", "Response Time (ms)",
+		"Model", "Provider", "Prompt Tokens", "Completion Tokens", "Cached Tokens",
+	}
+	sheet, err := newXLSXSheet(f, xlsxMessagesSheet, styles, header, 4, 80)
+	if err != nil {
+		return 0, err
+	}
+
+	count := 0
+	err = source(func(chat *domain.Chat) error {
+		for _, message := range chat.Messages {
+			count++
+			row := []any{
+				message.ID,
+				message.ChatID,
+				string(message.Role),
+				xlsxText(message.Content),
+				xlsxDate(styles, message.CreatedAt),
+			}
+
+			// Malformed metadata is exported as empty cells
+			if metadata, err := message.GetMetadata(); err == nil {
+				row = append(row,
+					metadata.TokenCount,
+					metadata.ResponseTime,
+					xlsxText(metadata.Model),
+					xlsxText(metadata.Provider),
+					metadata.PromptTokens,
+					metadata.CompletionTokens,
+					metadata.CachedTokens,
+				)
+			}
+			if err := sheet.AddRow(row); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	return count, sheet.Flush()
+}
+
+// writeSummarySheet writes the export details and counts into the Summary sheet.
+func writeSummarySheet(f *excelize.File, styles xlsxStyles, info ExportInfo, chatCount, messageCount int) error {
+	rows := [][]any{
+		{"Organization ID", info.OrganizationID},
+		{"Export Type", string(info.ExportType)},
+		{"Export Date", info.ExportDate},
+		{"Chats", chatCount},
+	}
+	if info.ExportType.IncludesMessages() {
+		rows = append(rows, []any{"Messages", messageCount})
+	}
+
+	for i, row := range rows {
+		if err := f.SetSheetRow(xlsxSummarySheet, xlsxCell(1, i+1), &row); err != nil {
+			return err
+		}
+	}
+
+	if err := f.SetCellStyle(xlsxSummarySheet, "A1", xlsxCell(1, len(rows)), styles.header); err != nil {
+		return err
+	}
+	if err := f.SetCellStyle(xlsxSummarySheet, "B3", "B3", styles.date); err != nil {
+		return err
+	}
+	return f.SetColWidth(xlsxSummarySheet, "A", "B", 20)
+}
+
+// xlsxSheet streams rows into a sheet. Once the sheet is full, it continues on
+// a new sheet with the same header, named after the first with a number.
+type xlsxSheet struct {
+	f          *excelize.File
+	name       string
+	styles     xlsxStyles
+	header     []string
+	textColumn int
+	textWidth  float64
+
+	sheets int                    // Sheets created so far
+	rows   int                    // Rows of the current sheet, including the header
+	sw     *excelize.StreamWriter // Writer of the current sheet
+}
+
+// newXLSXSheet creates a sheet with a frozen header row. The 1-based
+// textColumn is made textWidth wide.
+func newXLSXSheet(
+	f *excelize.File,
+	name string,
+	styles xlsxStyles,
+	header []string,
+	textColumn int,
+	textWidth float64,
+) (*xlsxSheet, error) {
+	sheet := &xlsxSheet{
+		f:          f,
+		name:       name,
+		styles:     styles,
+		header:     header,
+		textColumn: textColumn,
+		textWidth:  textWidth,
+	}
+	return sheet, sheet.next()
+}
+
+// AddRow appends a row, moving on to a new sheet when the current one is full.
+func (s *xlsxSheet) AddRow(row []any) error {
+	if s.rows >= xlsxMaxRows {
+		if err := s.next(); err != nil {
+			return err
+		}
+	}
+
+	s.rows++
+	return s.sw.SetRow(xlsxCell(1, s.rows), row)
+}
+
+// Flush finishes the current sheet.
+func (s *xlsxSheet) Flush() error {
+	return s.sw.Flush()
+}
+
+// next finishes the current sheet, if any, and starts a new one.
+func (s *xlsxSheet) next() error {
+	if s.sw != nil {
+		if err := s.sw.Flush(); err != nil {
+			return err
+		}
+	}
+
+	s.sheets++
+	name := s.name
+	if s.sheets > 1 {
+		name = fmt.Sprintf("%s (%d)", s.name, s.sheets)
+	}
+
+	sw, err := newXLSXStreamWriter(s.f, name, s.styles, s.header, s.textColumn, s.textWidth)
+	if err != nil {
+		return err
+	}
+
+	s.sw = sw
+	s.rows = 1
+	return nil
+}
+
+// newXLSXStreamWriter creates a sheet and returns a stream writer positioned
+// after its frozen header row. The 1-based textColumn is made textWidth wide.
+func newXLSXStreamWriter(
+	f *excelize.File,
+	name string,
+	styles xlsxStyles,
+	header []string,
+	textColumn int,
+	textWidth float64,
+) (*excelize.StreamWriter, error) {
+	if _, err := f.NewSheet(name); err != nil {
+		return nil, err
+	}
+
+	sw, err := f.NewStreamWriter(name)
+	if err != nil {
+		return nil, err
+	}
+
+	// Column widths and panes must be set before the first row. Width ranges
+	// must not overlap, so the text column splits the default range.
+	if textColumn > 1 {
+		if err := sw.SetColWidth(1, textColumn-1, 18); err != nil {
+			return nil, err
+		}
+	}
+	if err := sw.SetColWidth(textColumn, textColumn, textWidth); err != nil {
+		return nil, err
+	}
+	if textColumn < len(header) {
+		if err := sw.SetColWidth(textColumn+1, len(header), 18); err != nil {
+			return nil, err
+		}
+	}
+	if err := sw.SetPanes(&excelize.Panes{
+		Freeze:      true,
+		YSplit:      1,
+		TopLeftCell: "A2",
+		ActivePane:  "bottomLeft",
+	}); err != nil {
+		return nil, err
+	}
+
+	cells := make([]any, len(header))
+	for i, title := range header {
+		cells[i] = excelize.Cell{StyleID: styles.header, Value: title}
+	}
+	return sw, sw.SetRow("A1", cells)
+}
+
+// xlsxCell returns the name of the cell at the 1-based column and row.
+func xlsxCell(col, row int) string {
+	cell, _ := excelize.CoordinatesToCellName(col, row)
+	return cell
+}
+
+// xlsxDate returns a date cell, or an empty cell for the zero time, which
+// Excel cannot represent.
+func xlsxDate(styles xlsxStyles, t time.Time) any {
+	if t.IsZero() {
+		return nil
+	}
+	return excelize.Cell{StyleID: styles.date, Value: t}
+}
+
+// xlsxText truncates s to the length limit of Excel cells.
+func xlsxText(s string) string {
+	if utf8.RuneCountInString(s) <= xlsxMaxCellChars {
+		return s
+	}
+	return string([]rune(s)[:xlsxMaxCellChars])
+}
+
+// stringPtr returns a pointer to s.
+func stringPtr(s string) *string {
+	return &s
+}
+
+// ContentType returns the MIME type of XLSX workbooks.
+func (x *XLSXExporter) ContentType() string {
+	return "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+}
+
+// Extension returns the file extension of XLSX workbooks.
+func (x *XLSXExporter) Extension() string {
+	return ".xlsx"
+}
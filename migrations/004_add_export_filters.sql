@@ -0,0 +1,5 @@
+-- Migration to store the filters an export was requested with
+
+ALTER TABLE exports ADD COLUMN IF NOT EXISTS filters JSONB;
+
+COMMENT ON COLUMN exports.filters IS 'Export filters: created_from, created_to, tags, exclude_tags, user_id, is_escalated, country_code, sentiment, question_category, roles';
@@ -0,0 +1,5 @@
+-- Migration to make room for longer export format names such as ndjson_messages
+
+ALTER TABLE exports ALTER COLUMN format TYPE VARCHAR(32);
+
+COMMENT ON COLUMN exports.format IS 'Export format: json, csv, ndjson, ndjson_messages';
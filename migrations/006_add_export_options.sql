@@ -0,0 +1,6 @@
+-- Migration to store format-specific export settings, such as fine-tuning dataset options
+
+ALTER TABLE exports ADD COLUMN IF NOT EXISTS options JSONB;
+
+COMMENT ON COLUMN exports.format IS 'Export format: json, csv, ndjson, ndjson_messages, finetune';
+COMMENT ON COLUMN exports.options IS 'Format-specific export options, e.g. fine_tune: drop_system_messages, min_user_rating, exclude_escalated';
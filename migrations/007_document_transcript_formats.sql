@@ -0,0 +1,3 @@
+-- Migration to document the Markdown and HTML transcript export formats
+
+COMMENT ON COLUMN exports.format IS 'Export format: json, csv, ndjson, ndjson_messages, finetune, markdown, html';
@@ -0,0 +1,5 @@
+-- Migration to store how an export file is compressed or archived
+
+ALTER TABLE exports ADD COLUMN IF NOT EXISTS compression VARCHAR(10) NOT NULL DEFAULT 'none';
+
+COMMENT ON COLUMN exports.compression IS 'Export compression: none, gzip (single gzip file) or zip (bundle with manifest.json)';
@@ -0,0 +1,8 @@
+-- Migration to expire exports after the retention period of their organization
+
+ALTER TABLE exports ADD COLUMN IF NOT EXISTS expires_at TIMESTAMP;
+
+CREATE INDEX IF NOT EXISTS idx_exports_expires_at ON exports(expires_at);
+
+COMMENT ON COLUMN exports.status IS 'Current status: pending, processing, completed, failed, expired';
+COMMENT ON COLUMN exports.expires_at IS 'Time after which the export file is deleted, from the export_ttl_hours organization setting (default 168)';
@@ -0,0 +1,33 @@
+-- Migration to add recurring export schedules
+
+CREATE TABLE IF NOT EXISTS export_schedules (
+    id BIGSERIAL PRIMARY KEY,
+    organization_id BIGINT NOT NULL,
+    user_id BIGINT NOT NULL,
+    name VARCHAR(100) NOT NULL,
+    cron_spec VARCHAR(100) NOT NULL,
+    timezone VARCHAR(64),
+    format VARCHAR(32) NOT NULL,
+    type VARCHAR(20) NOT NULL,
+    compression VARCHAR(10) NOT NULL DEFAULT 'none',
+    filters JSONB,
+    options JSONB,
+    lookback_hours INTEGER NOT NULL DEFAULT 0,
+    enabled BOOLEAN NOT NULL DEFAULT TRUE,
+    last_run_at TIMESTAMP,
+    last_export_id BIGINT,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP NOT NULL DEFAULT NOW(),
+
+    CONSTRAINT fk_export_schedules_organization FOREIGN KEY (organization_id)
+        REFERENCES organizations(id) ON DELETE CASCADE,
+    CONSTRAINT fk_export_schedules_user FOREIGN KEY (user_id)
+        REFERENCES users(id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_export_schedules_organization_id ON export_schedules(organization_id);
+
+COMMENT ON TABLE export_schedules IS 'Recurring exports; each run creates a regular export job';
+COMMENT ON COLUMN export_schedules.cron_spec IS 'Standard 5-field cron expression or descriptor such as @weekly';
+COMMENT ON COLUMN export_schedules.timezone IS 'IANA time zone of the cron expression, UTC when empty';
+COMMENT ON COLUMN export_schedules.lookback_hours IS 'When set, each run only exports chats created in the last N hours';
@@ -0,0 +1,8 @@
+-- Migration to report export progress and support cancellation
+
+ALTER TABLE exports ADD COLUMN IF NOT EXISTS processed_chats INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE exports ADD COLUMN IF NOT EXISTS total_chats INTEGER NOT NULL DEFAULT 0;
+
+COMMENT ON COLUMN exports.status IS 'Current status: pending, processing, completed, failed, expired, cancelled';
+COMMENT ON COLUMN exports.processed_chats IS 'Chats written so far while the export is processing';
+COMMENT ON COLUMN exports.total_chats IS 'Chats matching the export, counted when processing starts';
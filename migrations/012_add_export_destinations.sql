@@ -0,0 +1,43 @@
+-- Migration to deliver completed exports to webhook and SFTP destinations
+
+CREATE TABLE IF NOT EXISTS export_destinations (
+    id BIGSERIAL PRIMARY KEY,
+    organization_id BIGINT NOT NULL,
+    name VARCHAR(100) NOT NULL,
+    type VARCHAR(20) NOT NULL,
+    config JSONB,
+    enabled BOOLEAN NOT NULL DEFAULT TRUE,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP NOT NULL DEFAULT NOW(),
+
+    CONSTRAINT fk_export_destinations_organization FOREIGN KEY (organization_id)
+        REFERENCES organizations(id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_export_destinations_organization_id ON export_destinations(organization_id);
+
+-- Deliveries keep their history when the destination is deleted, so there is
+-- no foreign key on destination_id
+CREATE TABLE IF NOT EXISTS export_deliveries (
+    id BIGSERIAL PRIMARY KEY,
+    export_id BIGINT NOT NULL,
+    destination_id BIGINT NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'pending',
+    attempts INTEGER NOT NULL DEFAULT 0,
+    last_error TEXT,
+    delivered_at TIMESTAMP,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP NOT NULL DEFAULT NOW(),
+
+    CONSTRAINT fk_export_deliveries_export FOREIGN KEY (export_id)
+        REFERENCES exports(id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_export_deliveries_export_id ON export_deliveries(export_id);
+CREATE INDEX IF NOT EXISTS idx_export_deliveries_destination_id ON export_deliveries(destination_id);
+
+COMMENT ON TABLE export_destinations IS 'Webhook and SFTP targets that completed exports are delivered to';
+COMMENT ON COLUMN export_destinations.type IS 'Destination type: webhook, sftp';
+COMMENT ON COLUMN export_destinations.config IS 'Type-specific settings, including secrets that are never returned by the API';
+COMMENT ON TABLE export_deliveries IS 'Delivery of an export to one destination, with its attempts and last error';
+COMMENT ON COLUMN export_deliveries.status IS 'Current status: pending, delivered, failed';
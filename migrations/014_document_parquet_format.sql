@@ -0,0 +1,3 @@
+-- Migration to document the Parquet export format
+
+COMMENT ON COLUMN exports.format IS 'Export format: json, csv, ndjson, ndjson_messages, finetune, markdown, html, xlsx, parquet';
@@ -0,0 +1,92 @@
+-- Migration to add full-text search across message content
+
+-- Maps an ISO-639 language code, optionally with a region (e.g., "en-US"), to
+-- the text search configuration used to stem messages in that language.
+-- Unknown or missing languages use the language-agnostic 'simple' configuration.
+CREATE OR REPLACE FUNCTION chatlogger_search_config(language_code TEXT)
+RETURNS regconfig AS $$
+    SELECT (CASE lower(split_part(coalesce(language_code, ''), '-', 1))
+        WHEN 'ar' THEN 'arabic'
+        WHEN 'da' THEN 'danish'
+        WHEN 'de' THEN 'german'
+        WHEN 'el' THEN 'greek'
+        WHEN 'en' THEN 'english'
+        WHEN 'es' THEN 'spanish'
+        WHEN 'fi' THEN 'finnish'
+        WHEN 'fr' THEN 'french'
+        WHEN 'ga' THEN 'irish'
+        WHEN 'hu' THEN 'hungarian'
+        WHEN 'id' THEN 'indonesian'
+        WHEN 'it' THEN 'italian'
+        WHEN 'lt' THEN 'lithuanian'
+        WHEN 'ne' THEN 'nepali'
+        WHEN 'nl' THEN 'dutch'
+        WHEN 'no' THEN 'norwegian'
+        WHEN 'nb' THEN 'norwegian'
+        WHEN 'nn' THEN 'norwegian'
+        WHEN 'pt' THEN 'portuguese'
+        WHEN 'ro' THEN 'romanian'
+        WHEN 'ru' THEN 'russian'
+        WHEN 'sv' THEN 'swedish'
+        WHEN 'ta' THEN 'tamil'
+        WHEN 'tr' THEN 'turkish'
+        ELSE 'simple'
+    END)::regconfig
+$$ LANGUAGE SQL IMMUTABLE;
+
+-- Builds the search vector of a message. The content is indexed both stemmed
+-- for its chat's language and unstemmed, so searches without a language still
+-- match the exact words of every conversation.
+CREATE OR REPLACE FUNCTION chatlogger_message_search_vector(language_code TEXT, content TEXT)
+RETURNS tsvector AS $$
+    SELECT to_tsvector(chatlogger_search_config(language_code), coalesce(content, ''))
+        || to_tsvector('simple'::regconfig, coalesce(content, ''))
+$$ LANGUAGE SQL IMMUTABLE;
+
+ALTER TABLE messages ADD COLUMN IF NOT EXISTS search_vector TSVECTOR;
+
+-- Keep the search vector of a message up to date with its content
+CREATE OR REPLACE FUNCTION messages_search_vector_update()
+RETURNS TRIGGER AS $$
+BEGIN
+    NEW.search_vector := chatlogger_message_search_vector(
+        (SELECT chats.metadata->>'language_code' FROM chats WHERE chats.id = NEW.chat_id),
+        NEW.content
+    );
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS messages_search_vector_trigger ON messages;
+CREATE TRIGGER messages_search_vector_trigger
+    BEFORE INSERT OR UPDATE OF content, chat_id ON messages
+    FOR EACH ROW EXECUTE FUNCTION messages_search_vector_update();
+
+-- Re-index the messages of a chat when its language changes
+CREATE OR REPLACE FUNCTION chats_search_language_update()
+RETURNS TRIGGER AS $$
+BEGIN
+    IF NEW.metadata->>'language_code' IS DISTINCT FROM OLD.metadata->>'language_code' THEN
+        UPDATE messages
+        SET search_vector = chatlogger_message_search_vector(NEW.metadata->>'language_code', content)
+        WHERE chat_id = NEW.id;
+    END IF;
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS chats_search_language_trigger ON chats;
+CREATE TRIGGER chats_search_language_trigger
+    AFTER UPDATE OF metadata ON chats
+    FOR EACH ROW EXECUTE FUNCTION chats_search_language_update();
+
+-- Index existing messages
+UPDATE messages
+SET search_vector = chatlogger_message_search_vector(chats.metadata->>'language_code', messages.content)
+FROM chats
+WHERE chats.id = messages.chat_id AND messages.search_vector IS NULL;
+
+CREATE INDEX IF NOT EXISTS idx_messages_search_vector ON messages USING GIN (search_vector);
+
+COMMENT ON COLUMN messages.search_vector IS 'Full-text search vector of the content, stemmed for the chat language and unstemmed';
+COMMENT ON FUNCTION chatlogger_search_config(TEXT) IS 'Text search configuration of an ISO-639 language code, simple when unknown';
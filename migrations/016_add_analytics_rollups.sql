@@ -0,0 +1,55 @@
+-- Migration to add pre-aggregated analytics rollups
+--
+-- The worker recomputes the hourly rollups of recent hours from chats and
+-- messages, and the daily rollups of the days they fall in from the hourly
+-- rollups. Buckets are aligned to UTC. Older buckets are (re)computed with
+-- `chatlogger-worker backfill-rollups`.
+
+CREATE TABLE IF NOT EXISTS analytics_hourly_rollups (
+    organization_id BIGINT NOT NULL,
+    bucket_start TIMESTAMPTZ NOT NULL,
+    chats BIGINT NOT NULL DEFAULT 0,
+    user_messages BIGINT NOT NULL DEFAULT 0,
+    assistant_messages BIGINT NOT NULL DEFAULT 0,
+    system_messages BIGINT NOT NULL DEFAULT 0,
+    tokens BIGINT NOT NULL DEFAULT 0,
+    response_time_count BIGINT NOT NULL DEFAULT 0,
+    response_time_sum DOUBLE PRECISION NOT NULL DEFAULT 0,
+    response_time_histogram JSONB NOT NULL DEFAULT '[]',
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+
+    PRIMARY KEY (organization_id, bucket_start),
+    CONSTRAINT fk_analytics_hourly_rollups_organization FOREIGN KEY (organization_id)
+        REFERENCES organizations(id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_analytics_hourly_rollups_bucket_start ON analytics_hourly_rollups(bucket_start);
+
+CREATE TABLE IF NOT EXISTS analytics_daily_rollups (
+    LIKE analytics_hourly_rollups INCLUDING DEFAULTS,
+
+    PRIMARY KEY (organization_id, bucket_start),
+    CONSTRAINT fk_analytics_daily_rollups_organization FOREIGN KEY (organization_id)
+        REFERENCES organizations(id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_analytics_daily_rollups_bucket_start ON analytics_daily_rollups(bucket_start);
+
+-- Position of the incremental rollup: everything created before rolled_up_to
+-- has been rolled up
+CREATE TABLE IF NOT EXISTS analytics_rollup_state (
+    name VARCHAR(50) PRIMARY KEY,
+    rolled_up_to TIMESTAMPTZ NOT NULL,
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+-- The rollups scan the chats and messages created in a time range
+CREATE INDEX IF NOT EXISTS idx_chats_created_at ON chats(created_at);
+CREATE INDEX IF NOT EXISTS idx_messages_created_at ON messages(created_at);
+
+COMMENT ON TABLE analytics_hourly_rollups IS 'Activity per organization and UTC hour, maintained by the worker';
+COMMENT ON TABLE analytics_daily_rollups IS 'Activity per organization and UTC day, summed from the hourly rollups';
+COMMENT ON COLUMN analytics_hourly_rollups.bucket_start IS 'Start of the UTC hour (daily rollups: UTC day)';
+COMMENT ON COLUMN analytics_hourly_rollups.chats IS 'Chats created in the bucket';
+COMMENT ON COLUMN analytics_hourly_rollups.tokens IS 'Sum of the token_count metadata of the messages';
+COMMENT ON COLUMN analytics_hourly_rollups.response_time_histogram IS 'Messages per response time range in milliseconds, see domain.ResponseTimeHistogramBounds; empty without response times';
@@ -0,0 +1,7 @@
+-- Migration to record the API key chats are created with, for per-key latency reports
+
+ALTER TABLE chats ADD COLUMN IF NOT EXISTS api_key_id BIGINT;
+
+CREATE INDEX IF NOT EXISTS idx_chats_api_key_id ON chats(api_key_id);
+
+COMMENT ON COLUMN chats.api_key_id IS 'API key the chat was created with through the public API, NULL for dashboard chats. Not a foreign key, so deleting a key keeps its chats';
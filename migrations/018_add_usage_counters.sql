@@ -0,0 +1,17 @@
+-- Migration to count ingestion per API key and day, for usage quotas
+
+CREATE TABLE IF NOT EXISTS usage_counters (
+    organization_id BIGINT NOT NULL,
+    api_key_id BIGINT NOT NULL,
+    day DATE NOT NULL,
+    chats BIGINT NOT NULL DEFAULT 0,
+    messages BIGINT NOT NULL DEFAULT 0,
+    bytes BIGINT NOT NULL DEFAULT 0,
+    updated_at TIMESTAMPTZ,
+    PRIMARY KEY (organization_id, api_key_id, day)
+);
+
+COMMENT ON COLUMN usage_counters.api_key_id IS 'API key the resources were created with, 0 for the dashboard. Not a foreign key, so deleting a key keeps its usage';
+COMMENT ON COLUMN usage_counters.bytes IS 'Size of the request bodies';
+
+ALTER TABLE api_keys ADD COLUMN IF NOT EXISTS last_used_at TIMESTAMPTZ;
@@ -0,0 +1,16 @@
+// Package migrations embeds the SQL migrations that cannot be expressed as
+// GORM models, so the server can apply them after auto-migrating the models.
+package migrations
+
+import _ "embed" // Required for go:embed
+
+// MessageSearch creates the full-text search column, triggers and index of messages.
+//
+//go:embed 015_add_message_search.sql
+var MessageSearch string
+
+// AnalyticsRollups creates the hourly and daily analytics rollup tables, which
+// need composite primary keys GORM cannot express.
+//
+//go:embed 016_add_analytics_rollups.sql
+var AnalyticsRollups string